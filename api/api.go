@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	v1 "github.com/nurmuhammaddeveloper/blog_db/api/v1"
 	"github.com/nurmuhammaddeveloper/blog_db/config"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/filter"
 	"github.com/nurmuhammaddeveloper/blog_db/storage"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -14,6 +15,8 @@ type RoutetOptions struct {
 	Cfg      *config.Config
 	Storage  storage.StorageI
 	InMemory storage.InMemoryStorageI
+	// Scorers plug extra spam and abuse scoring into the content filter.
+	Scorers []*filter.ScoreChecker
 }
 
 // New @title           Swagger for blog api
@@ -37,6 +40,7 @@ func New(opt *RoutetOptions) *gin.Engine {
 		Cfg:      opt.Cfg,
 		Storage:  &opt.Storage,
 		InMemory: &opt.InMemory,
+		Scorers:  opt.Scorers,
 	})
 
 	apiV1 := router.Group("/v1")
@@ -44,8 +48,9 @@ func New(opt *RoutetOptions) *gin.Engine {
 	{
 
 		apiV1.POST("/users", handlerV1.CreateUser)
-		apiV1.GET("/users/:id", handlerV1.GetUser)
+		apiV1.GET("/users/:id", handlerV1.OptionalAuthMiddleWare, handlerV1.GetUser)
 		apiV1.GET("/users/me", handlerV1.AuthMiddleWare, handlerV1.GetUserProfile)
+		apiV1.GET("/users/me/stats", handlerV1.AuthMiddleWare, handlerV1.GetUserStats)
 		apiV1.PUT("/users/:id", handlerV1.AuthMiddleWare, handlerV1.UpdateUser)
 		apiV1.DELETE("/users/:id", handlerV1.AuthMiddleWare, handlerV1.DeleteUser)
 		apiV1.GET("/users", handlerV1.GetAllUsers)
@@ -54,18 +59,63 @@ func New(opt *RoutetOptions) *gin.Engine {
 		apiV1.GET("/categories/:id", handlerV1.GetCategory)
 		apiV1.PUT("/categories/:id", handlerV1.AuthMiddleWare, handlerV1.UpdateCategory)
 		apiV1.DELETE("/categories/:id", handlerV1.AuthMiddleWare, handlerV1.DeleteCategory)
+		apiV1.GET("/categories/trash", handlerV1.AuthMiddleWare, handlerV1.GetCategoriesTrash)
+		apiV1.POST("/categories/:id/restore", handlerV1.AuthMiddleWare, handlerV1.RestoreCategory)
 		apiV1.GET("/categories", handlerV1.GetAllCategories)
 
 		apiV1.POST("/posts", handlerV1.AuthMiddleWare, handlerV1.CreatePost)
-		apiV1.GET("/posts/:id", handlerV1.GetPost)
+		apiV1.GET("/posts/:id", handlerV1.OptionalAuthMiddleWare, handlerV1.GetPost)
+		apiV1.GET("/posts/slug/:lang/:slug", handlerV1.OptionalAuthMiddleWare, handlerV1.GetPostBySlug)
+		apiV1.POST("/posts/:id/unlock", handlerV1.UnlockPost)
+		apiV1.GET("/posts/:id/related", handlerV1.OptionalAuthMiddleWare, handlerV1.GetRelatedPosts)
+		apiV1.GET("/posts/:id/seo", handlerV1.OptionalAuthMiddleWare, handlerV1.GetPostSEO)
+		apiV1.PUT("/posts/:id/seo", handlerV1.AuthMiddleWare, handlerV1.SetPostSEO)
+		apiV1.GET("/posts/:id/head", handlerV1.OptionalAuthMiddleWare, handlerV1.GetPostHead)
+		apiV1.GET("/posts/:id/translations", handlerV1.AuthMiddleWare, handlerV1.GetPostTranslations)
+		apiV1.PUT("/posts/:id/translations/:lang", handlerV1.AuthMiddleWare, handlerV1.SetPostTranslation)
+		apiV1.DELETE("/posts/:id/translations/:lang", handlerV1.AuthMiddleWare, handlerV1.DeletePostTranslation)
+		apiV1.GET("/posts/:id/stats", handlerV1.AuthMiddleWare, handlerV1.GetPostStats)
 		apiV1.PUT("/posts/:id", handlerV1.AuthMiddleWare, handlerV1.UpdatePost)
 		apiV1.DELETE("/posts/:id", handlerV1.AuthMiddleWare, handlerV1.DeletePost)
-		apiV1.GET("/posts", handlerV1.GetAllPosts)
+		apiV1.PUT("/posts/:id/contributors/:user_id", handlerV1.AuthMiddleWare, handlerV1.SetPostContributor)
+		apiV1.DELETE("/posts/:id/contributors/:user_id", handlerV1.AuthMiddleWare, handlerV1.RemovePostContributor)
+		apiV1.PUT("/posts/:id/pin", handlerV1.AuthMiddleWare, handlerV1.PinPost)
+		apiV1.DELETE("/posts/:id/pin", handlerV1.AuthMiddleWare, handlerV1.UnpinPost)
+		apiV1.GET("/posts/pins", handlerV1.AuthMiddleWare, handlerV1.GetPins)
+		apiV1.GET("/posts/featured", handlerV1.OptionalAuthMiddleWare, handlerV1.GetFeaturedPosts)
+		apiV1.PUT("/posts/featured", handlerV1.AuthMiddleWare, handlerV1.SetFeaturedPosts)
+		apiV1.POST("/posts/import", handlerV1.AuthMiddleWare, handlerV1.ImportPosts)
+		apiV1.GET("/posts/export", handlerV1.AuthMiddleWare, handlerV1.ExportPosts)
+		apiV1.GET("/posts/trash", handlerV1.AuthMiddleWare, handlerV1.GetPostsTrash)
+		apiV1.POST("/posts/:id/restore", handlerV1.AuthMiddleWare, handlerV1.RestorePost)
+		apiV1.GET("/posts", handlerV1.OptionalAuthMiddleWare, handlerV1.GetAllPosts)
+
+		apiV1.POST("/imports/wordpress", handlerV1.AuthMiddleWare, handlerV1.CreateWordPressImport)
+		apiV1.GET("/imports/wordpress/:id", handlerV1.AuthMiddleWare, handlerV1.GetWordPressImport)
+
+		apiV1.POST("/series", handlerV1.AuthMiddleWare, handlerV1.CreateSeries)
+		apiV1.GET("/series/:id", handlerV1.OptionalAuthMiddleWare, handlerV1.GetSeries)
+		apiV1.PUT("/series/:id", handlerV1.AuthMiddleWare, handlerV1.UpdateSeries)
+		apiV1.DELETE("/series/:id", handlerV1.AuthMiddleWare, handlerV1.DeleteSeries)
+		apiV1.PUT("/series/:id/posts", handlerV1.AuthMiddleWare, handlerV1.SetSeriesPosts)
 
 		apiV1.POST("/comments", handlerV1.AuthMiddleWare, handlerV1.CreateComment)
 		apiV1.PUT("/comments/:id", handlerV1.AuthMiddleWare, handlerV1.UpdateComment)
 		apiV1.DELETE("/comments/:id", handlerV1.AuthMiddleWare, handlerV1.DeleteComment)
-		apiV1.GET("/comments", handlerV1.GetAllComments)
+		apiV1.GET("/comments/trash", handlerV1.AuthMiddleWare, handlerV1.GetCommentsTrash)
+		apiV1.GET("/comments/moderation", handlerV1.AuthMiddleWare, handlerV1.GetModerationQueue)
+		apiV1.POST("/comments/moderation", handlerV1.AuthMiddleWare, handlerV1.ModerateComments)
+		apiV1.POST("/comments/:id/restore", handlerV1.AuthMiddleWare, handlerV1.RestoreComment)
+		apiV1.GET("/comments/:id/history", handlerV1.AuthMiddleWare, handlerV1.GetCommentHistory)
+		apiV1.GET("/comments", handlerV1.OptionalAuthMiddleWare, handlerV1.GetAllComments)
+
+		apiV1.GET("/filter/outcomes", handlerV1.AuthMiddleWare, handlerV1.GetFilterOutcomes)
+
+		apiV1.POST("/reports", handlerV1.AuthMiddleWare, handlerV1.CreateReport)
+		apiV1.GET("/reports", handlerV1.AuthMiddleWare, handlerV1.GetReports)
+		apiV1.GET("/reports/:id", handlerV1.AuthMiddleWare, handlerV1.GetReport)
+		apiV1.POST("/reports/:id/resolve", handlerV1.AuthMiddleWare, handlerV1.ResolveReport)
+		apiV1.POST("/reports/:id/dismiss", handlerV1.AuthMiddleWare, handlerV1.DismissReport)
 
 		apiV1.POST("/likes", handlerV1.AuthMiddleWare, handlerV1.CreateOrUpdateLike)
 		apiV1.GET("/likes/user-post", handlerV1.AuthMiddleWare, handlerV1.GetLike)
@@ -79,8 +129,20 @@ func New(opt *RoutetOptions) *gin.Engine {
 		apiV1.POST("/auth/verify-forgot-password", handlerV1.VerifyForgotPassword)
 
 		apiV1.POST("/file_upload", handlerV1.AuthMiddleWare, handlerV1.UploadFile)
+
+		apiV1.GET("/search", handlerV1.Search)
+
+		apiV1.GET("/oembed", handlerV1.GetOEmbed)
+
+		apiV1.GET("/feeds/:file", handlerV1.GetPostsFeed)
+		apiV1.GET("/feeds/categories/:id/:file", handlerV1.GetCategoryFeed)
+		apiV1.GET("/feeds/users/:id/:file", handlerV1.GetUserFeed)
 	}
 
+	router.GET("/robots.txt", handlerV1.GetRobots)
+	router.GET("/sitemap.xml", handlerV1.GetSitemapIndex)
+	router.GET("/sitemaps/:file", handlerV1.GetSitemap)
+
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	return router
@@ -0,0 +1,13 @@
+package models
+
+type ImportFileResult struct {
+	Name   string  `json:"name"`
+	PostID *int64  `json:"post_id"`
+	Error  *string `json:"error"`
+}
+
+type ImportPostsResponse struct {
+	Imported int                 `json:"imported"`
+	Failed   int                 `json:"failed"`
+	Files    []*ImportFileResult `json:"files"`
+}
@@ -3,9 +3,10 @@ package models
 import "time"
 
 type Category struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int64      `json:"id"`
+	Title     string     `json:"title"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type CreateCategoryRequest struct {
@@ -15,4 +16,6 @@ type CreateCategoryRequest struct {
 type GetCategoriesResponse struct {
 	Categories []*Category `json:"categories"`
 	Count      int64       `json:"count"`
+	NextCursor *string     `json:"next_cursor"`
+	PrevCursor *string     `json:"prev_cursor"`
 }
@@ -9,16 +9,54 @@ type Comment struct {
 	PostID      int64        `json:"post_id"`
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   *time.Time   `json:"updated_at"`
+	DeletedAt   *time.Time   `json:"deleted_at,omitempty"`
 	User        *CommentUser `json:"user"`
+	ParentID    *int64       `json:"parent_id"`
+	// Depth is 0 for top level comments. Path holds the ids from the root
+	// of the thread down to the comment itself.
+	Depth      int32   `json:"depth"`
+	Path       []int64 `json:"path"`
+	ReplyCount int64   `json:"reply_count"`
+	// Deleted marks the tombstone of a deleted comment kept for its
+	// replies. Its description and author are left out.
+	Deleted bool `json:"deleted,omitempty"`
+	// Status is pending, approved, rejected or spam.
+	Status      string     `json:"status"`
+	ModeratedAt *time.Time `json:"moderated_at,omitempty"`
+	Mentions    []*Mention `json:"mentions"`
+	// Edited is set once the description was changed, EditCount counts
+	// the changes.
+	Edited    bool  `json:"edited"`
+	EditCount int32 `json:"edit_count"`
 }
 
 type UpdateComment struct {
-	ID          int64        `json:"id"`
-	Description string       `json:"description"`
-	UserID      int64        `json:"user_id"`
-	PostID      int64        `json:"post_id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   *time.Time   `json:"updated_at"`
+	ID          int64      `json:"id"`
+	Description string     `json:"description"`
+	UserID      int64      `json:"user_id"`
+	PostID      int64      `json:"post_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	Status      string     `json:"status"`
+	Mentions    []*Mention `json:"mentions"`
+	Edited      bool       `json:"edited"`
+	EditCount   int32      `json:"edit_count"`
+}
+
+// CommentRevision is what a comment said before an edit. WrittenAt is when
+// that description was written, EditedAt when it was replaced.
+type CommentRevision struct {
+	Description string    `json:"description"`
+	WrittenAt   time.Time `json:"written_at"`
+	EditedBy    *int64    `json:"edited_by"`
+	EditedAt    time.Time `json:"edited_at"`
+}
+
+type GetCommentHistoryResponse struct {
+	// Revisions are the earlier descriptions, oldest first. The current
+	// one is the comment's.
+	Revisions []*CommentRevision `json:"revisions"`
+	Comment   *Comment           `json:"comment"`
 }
 
 type CommentUser struct {
@@ -31,6 +69,8 @@ type CommentUser struct {
 type CreateCommentRequest struct {
 	Description string `json:"description"`
 	PostID      int64  `json:"post_id"`
+	// ParentID makes the comment a reply.
+	ParentID *int64 `json:"parent_id"`
 }
 
 type UpdateCommentRequest struct {
@@ -38,13 +78,35 @@ type UpdateCommentRequest struct {
 }
 
 type GetAllCommentsParams struct {
-	Limit  int64 `json:"limit" binding:"required" default:"10"`
-	Page   int64 `json:"page" binding:"required" default:"1"`
-	UserID int64 `json:"user_id"`
-	PostID int64 `json:"category_id"`
+	Limit  int64  `json:"limit" binding:"required" default:"10" minimum:"1" maximum:"100"`
+	Page   int64  `json:"page" binding:"required" default:"1" minimum:"1"`
+	UserID int64  `json:"user_id"`
+	PostID int64  `json:"post_id"`
+	Sort   string `json:"sort" example:"created_at:desc"`
+	After  string `json:"after"`
+	Before string `json:"before"`
+	// Threaded lists comments depth first, sorted by path unless sort is
+	// given, with tombstones of deleted comments that still have replies.
+	Threaded bool `json:"threaded"`
+	// ParentID lists the replies of a comment, or its whole thread when
+	// threaded.
+	ParentID int64  `json:"parent_id"`
+	MaxDepth *int32 `json:"max_depth"`
+}
+
+type ModerateCommentsRequest struct {
+	IDs    []int64 `json:"ids" binding:"required,min=1,max=100"`
+	Status string  `json:"status" binding:"required,oneof=pending approved rejected spam" example:"approved"`
+}
+
+type ModerateCommentsResponse struct {
+	// Moderated counts the comments that could be moderated by the user.
+	Moderated int64 `json:"moderated"`
 }
 
 type GetAllCommentsResponse struct {
-	Comments []*Comment `json:"comments"`
-	Count    int64      `json:"count"`
+	Comments   []*Comment `json:"comments"`
+	Count      int64      `json:"count"`
+	NextCursor *string    `json:"next_cursor"`
+	PrevCursor *string    `json:"prev_cursor"`
 }
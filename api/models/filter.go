@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type FilterOutcome struct {
+	ID         int64  `json:"id"`
+	TargetType string `json:"target_type"`
+	// TargetID is null for rejected content, which is never saved.
+	TargetID    *int64    `json:"target_id"`
+	UserID      int64     `json:"user_id"`
+	Action      string    `json:"action"`
+	Reasons     []string  `json:"reasons"`
+	ContentHash string    `json:"content_hash"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type GetFilterOutcomesParams struct {
+	Limit      int64  `json:"limit" binding:"required" default:"10" minimum:"1" maximum:"100"`
+	Page       int64  `json:"page" binding:"required" default:"1" minimum:"1"`
+	TargetType string `json:"target_type" enums:"comment,post"`
+	TargetID   int64  `json:"target_id"`
+	UserID     int64  `json:"user_id"`
+	Action     string `json:"action" enums:"allow,hold,reject"`
+}
+
+type GetFilterOutcomesResponse struct {
+	Outcomes []*FilterOutcome `json:"outcomes"`
+	Count    int64            `json:"count"`
+}
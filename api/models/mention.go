@@ -0,0 +1,7 @@
+package models
+
+// Mention is a user mentioned as @username in a post or a comment.
+type Mention struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
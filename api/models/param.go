@@ -1,7 +1,10 @@
 package models
 
 type GetAllParams struct {
-	Limit  int64  `json:"limit" binding:"required" default:"10"`
-	Page   int64  `json:"page" binding:"required" default:"1"`
+	Limit  int64  `json:"limit" binding:"required" default:"10" minimum:"1" maximum:"100"`
+	Page   int64  `json:"page" binding:"required" default:"1" minimum:"1"`
 	Search string `json:"search"`
+	Sort   string `json:"sort" example:"created_at:desc"`
+	After  string `json:"after"`
+	Before string `json:"before"`
 }
@@ -3,16 +3,55 @@ package models
 import "time"
 
 type Post struct {
-	ID           int64         `json:"id"`
-	Title        string        `json:"title"`
-	Description  string        `json:"description"`
-	ImageUrl     *string       `json:"image_url"`
-	UserID       int64         `json:"user_id"`
-	CategoryID   int64         `json:"category_id"`
-	UpdatedAt    *time.Time    `json:"updated_at"`
-	ViewsCount   int32         `json:"views_count"`
-	CreatedAt    time.Time     `json:"created_at"`
-	PostLikeInfo *PostLikeInfo `json:"like_info"`
+	ID              int64          `json:"id"`
+	Title           string         `json:"title"`
+	Description     string         `json:"description"`
+	DescriptionHtml string         `json:"description_html"`
+	TableOfContents []*PostHeading `json:"table_of_contents"`
+	ReadingTime     int32          `json:"reading_time"`
+	ImageUrl        *string        `json:"image_url"`
+	Visibility      string         `json:"visibility"`
+	Tags            []string       `json:"tags"`
+	UserID          int64          `json:"user_id"`
+	// Contributors lists the author first.
+	Contributors []*PostContributor `json:"contributors"`
+	CategoryID   int64              `json:"category_id"`
+	UpdatedAt    *time.Time         `json:"updated_at"`
+	ViewsCount   int32              `json:"views_count"`
+	CreatedAt    time.Time          `json:"created_at"`
+	DeletedAt    *time.Time         `json:"deleted_at,omitempty"`
+	PostLikeInfo *PostLikeInfo      `json:"like_info"`
+	Series       *PostSeries        `json:"series,omitempty"`
+	SearchRank   *float64           `json:"search_rank,omitempty"`
+	Highlight    *string            `json:"highlight,omitempty"`
+	// Language is the language the post is served in, Languages all it
+	// is available in. Slug is set when a translation is served.
+	Language  string   `json:"language"`
+	Languages []string `json:"languages"`
+	Slug      *string  `json:"slug,omitempty"`
+	// CommentStatus is open, closed, moderated or first_time_moderated.
+	CommentStatus string `json:"comment_status"`
+	// Mentions are the users mentioned in the description.
+	Mentions []*Mention `json:"mentions"`
+}
+
+type PostHeading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
+type PostContributor struct {
+	UserID          int64   `json:"user_id"`
+	Role            string  `json:"role"`
+	FirstName       string  `json:"first_name"`
+	LastName        string  `json:"last_name"`
+	Username        *string `json:"username"`
+	ProfileImageUrl *string `json:"profile_image_url"`
+}
+
+type SetContributorRequest struct {
+	Role string `json:"role" binding:"required,oneof=co-author editor"`
 }
 
 type PostLikeInfo struct {
@@ -22,31 +61,99 @@ type PostLikeInfo struct {
 
 type CreatePostRequest struct {
 	Title       string  `json:"title"`
-	Description string  `json:"description"`
+	Description string  `json:"description" example:"Markdown text"`
 	ImageUrl    *string `json:"image_url"`
-	UserID      int64   `json:"user_id"`
-	CategoryID  int64   `json:"category_id"`
+	Visibility  string  `json:"visibility" binding:"omitempty,oneof=public unlisted private password" default:"public"`
+	Password    *string `json:"password"`
+	// Tags are trimmed and lowercased.
+	Tags       []string `json:"tags" binding:"max=10,dive,max=50"`
+	CategoryID int64    `json:"category_id"`
+	// Language defaults to the default language of the site.
+	Language      string `json:"language"`
+	CommentStatus string `json:"comment_status" binding:"omitempty,oneof=open closed moderated first_time_moderated" default:"open"`
 }
 
 type UpdatePostRequest struct {
 	Title       string  `json:"title"`
-	Description string  `json:"description"`
+	Description string  `json:"description" example:"Markdown text"`
 	ImageUrl    *string `json:"image_url"`
-	UserID      int64   `json:"user_id"`
-	CategoryID  int64   `json:"category_id"`
-	ViewsCount  int32   `json:"views_count"`
+	Visibility  string  `json:"visibility" binding:"omitempty,oneof=public unlisted private password"`
+	Password    *string `json:"password"`
+	// Tags replace the current tags when given.
+	Tags       []string `json:"tags" binding:"max=10,dive,max=50"`
+	CategoryID int64    `json:"category_id"`
+	// Visibility, Language and CommentStatus keep the current ones when
+	// empty.
+	Language      string `json:"language"`
+	CommentStatus string `json:"comment_status" binding:"omitempty,oneof=open closed moderated first_time_moderated"`
+}
+
+type RelatedPost struct {
+	ID         int64     `json:"id"`
+	Title      string    `json:"title"`
+	ImageUrl   *string   `json:"image_url"`
+	UserID     int64     `json:"user_id"`
+	CategoryID int64     `json:"category_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	Tags       []string  `json:"tags"`
+	Score      float64   `json:"score"`
+}
+
+type RelatedPostsResponse struct {
+	Posts []*RelatedPost `json:"posts"`
+}
+
+type UnlockPostRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type UnlockPostResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type GetAllPostsParams struct {
-	Limit      int64  `json:"limit" binding:"required" default:"10"`
-	Page       int64  `json:"page" binding:"required" default:"1"`
+	Limit      int64  `json:"limit" binding:"required" default:"10" minimum:"1" maximum:"100"`
+	Page       int64  `json:"page" binding:"required" default:"1" minimum:"1"`
 	Search     string `json:"search"`
 	UserID     int64  `json:"user_id"`
 	CategoryID int64  `json:"category_id"`
-	SortByDate string `json:"sort" enums:"desc,asc" default:"desc"`
+	Sort       string `json:"sort" example:"views_count:desc,created_at:desc"`
+	Period     string `json:"period" enums:"week,month,all" default:"week"`
+	After      string `json:"after"`
+	Before     string `json:"before"`
+	// PinnedFirst puts the posts pinned in the category, or the globally
+	// pinned posts without one, first.
+	PinnedFirst bool `json:"pinned_first"`
 }
 
 type GetAllPostsResponse struct {
-	Posts []*Post `json:"posts"`
-	Count int64   `json:"count"`
+	Posts      []*Post `json:"posts"`
+	Count      int64   `json:"count"`
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+}
+
+// Pin is global when CategoryID is null.
+type Pin struct {
+	PostID     int64      `json:"post_id"`
+	CategoryID *int64     `json:"category_id"`
+	Position   int32      `json:"position"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type PinPostRequest struct {
+	// CategoryID pins the post within its category instead of globally.
+	CategoryID *int64     `json:"category_id"`
+	Position   int32      `json:"position" binding:"min=0"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+type GetPinsResponse struct {
+	Pins []*Pin `json:"pins"`
+}
+
+type SetFeaturedPostsRequest struct {
+	PostIDs []int64 `json:"post_ids" binding:"max=50"`
 }
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+type Report struct {
+	ID         int64  `json:"id"`
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	Status     string `json:"status"`
+	FlagsCount int64  `json:"flags_count"`
+	// Hidden is set while the report keeps the content hidden.
+	Hidden     bool          `json:"hidden"`
+	Resolution *string       `json:"resolution"`
+	ResolvedBy *int64        `json:"resolved_by"`
+	ResolvedAt *time.Time    `json:"resolved_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  *time.Time    `json:"updated_at"`
+	Flags      []*ReportFlag `json:"flags,omitempty"`
+}
+
+type ReportFlag struct {
+	UserID    int64     `json:"user_id"`
+	Reason    string    `json:"reason"`
+	Details   *string   `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateReportRequest struct {
+	TargetType string  `json:"target_type" binding:"required,oneof=post comment user"`
+	TargetID   int64   `json:"target_id" binding:"required"`
+	Reason     string  `json:"reason" binding:"required,oneof=spam harassment hate violence sexual misinformation other"`
+	Details    *string `json:"details" binding:"omitempty,max=1000"`
+}
+
+type CloseReportRequest struct {
+	// Resolution is sent to the users who reported the content.
+	Resolution *string `json:"resolution" binding:"omitempty,max=1000"`
+}
+
+type GetReportsParams struct {
+	Limit      int64  `json:"limit" binding:"required" default:"10" minimum:"1" maximum:"100"`
+	Page       int64  `json:"page" binding:"required" default:"1" minimum:"1"`
+	Status     string `json:"status" enums:"open,resolved,dismissed" default:"open"`
+	TargetType string `json:"target_type" enums:"post,comment,user"`
+	TargetID   int64  `json:"target_id"`
+}
+
+type GetReportsResponse struct {
+	Reports []*Report `json:"reports"`
+	Count   int64     `json:"count"`
+}
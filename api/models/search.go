@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+type SearchParams struct {
+	Query string `json:"q" binding:"required"`
+	Type  string `json:"type" enums:"posts,users,categories" example:"posts,users,categories"`
+	Limit int64  `json:"limit" default:"10" minimum:"1" maximum:"100"`
+}
+
+type SearchResponse struct {
+	Posts           []*SearchPost     `json:"posts"`
+	PostsCount      int64             `json:"posts_count"`
+	Users           []*SearchUser     `json:"users"`
+	UsersCount      int64             `json:"users_count"`
+	Categories      []*SearchCategory `json:"categories"`
+	CategoriesCount int64             `json:"categories_count"`
+}
+
+type SearchPost struct {
+	ID                   int64     `json:"id"`
+	Title                string    `json:"title"`
+	TitleHighlight       string    `json:"title_highlight"`
+	DescriptionHighlight string    `json:"description_highlight"`
+	ImageUrl             *string   `json:"image_url"`
+	UserID               int64     `json:"user_id"`
+	CategoryID           int64     `json:"category_id"`
+	CreatedAt            time.Time `json:"created_at"`
+	Rank                 float64   `json:"rank"`
+}
+
+type SearchUser struct {
+	ID              int64   `json:"id"`
+	FirstName       string  `json:"first_name"`
+	LastName        string  `json:"last_name"`
+	UserName        *string `json:"username"`
+	ProfileImageUrl *string `json:"profile_image_url"`
+	Highlight       string  `json:"highlight"`
+	Rank            float64 `json:"rank"`
+}
+
+type SearchCategory struct {
+	ID        int64   `json:"id"`
+	Title     string  `json:"title"`
+	Highlight string  `json:"highlight"`
+	Rank      float64 `json:"rank"`
+}
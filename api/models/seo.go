@@ -0,0 +1,42 @@
+package models
+
+// PostSEO is the metadata used when a post is shared, derived from the
+// post where Custom doesn't set it.
+type PostSEO struct {
+	MetaTitle       string            `json:"meta_title"`
+	MetaDescription string            `json:"meta_description"`
+	CanonicalUrl    string            `json:"canonical_url"`
+	ImageUrl        *string           `json:"image_url"`
+	Custom          SetPostSEORequest `json:"custom"`
+}
+
+// SetPostSEORequest replaces the custom metadata of a post. Null or empty
+// fields are derived from the post.
+type SetPostSEORequest struct {
+	MetaTitle       *string `json:"meta_title" binding:"omitempty,max=100"`
+	MetaDescription *string `json:"meta_description" binding:"omitempty,max=300"`
+	CanonicalUrl    *string `json:"canonical_url" binding:"omitempty,url"`
+	ImageUrl        *string `json:"image_url"`
+}
+
+type OEmbedParams struct {
+	URL       string `json:"url" binding:"required"`
+	Format    string `json:"format"`
+	MaxWidth  int    `json:"maxwidth"`
+	MaxHeight int    `json:"maxheight"`
+}
+
+// OEmbed is a rich oEmbed response, see https://oembed.com.
+type OEmbed struct {
+	Type         string `json:"type"`
+	Version      string `json:"version"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name,omitempty"`
+	AuthorUrl    string `json:"author_url,omitempty"`
+	ProviderName string `json:"provider_name"`
+	ProviderUrl  string `json:"provider_url"`
+	CacheAge     int    `json:"cache_age"`
+	Html         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+type Series struct {
+	ID          int64         `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	UserID      int64         `json:"user_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   *time.Time    `json:"updated_at"`
+	Posts       []*SeriesPost `json:"posts"`
+}
+
+type SeriesPost struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	Position int32  `json:"position"`
+}
+
+// PostSeries is the series of a post along with where the post is in it.
+type PostSeries struct {
+	ID       int64         `json:"id"`
+	Title    string        `json:"title"`
+	Position int32         `json:"position"`
+	Total    int           `json:"total"`
+	Prev     *SeriesPost   `json:"prev"`
+	Next     *SeriesPost   `json:"next"`
+	Posts    []*SeriesPost `json:"posts"`
+}
+
+type CreateSeriesRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+}
+
+type SetSeriesPostsRequest struct {
+	PostIDs []int64 `json:"post_ids" binding:"max=100"`
+}
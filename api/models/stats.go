@@ -0,0 +1,49 @@
+package models
+
+type StatsParams struct {
+	From  string `json:"from" example:"2022-11-01"`
+	To    string `json:"to" example:"2022-11-30"`
+	Limit int64  `json:"limit" default:"5" minimum:"1" maximum:"100"`
+}
+
+type DailyStats struct {
+	Day           string `json:"day" example:"2022-11-01"`
+	Views         int64  `json:"views"`
+	UniqueViewers int64  `json:"unique_viewers"`
+	Likes         int64  `json:"likes"`
+	Dislikes      int64  `json:"dislikes"`
+	Comments      int64  `json:"comments"`
+}
+
+type StatsTotals struct {
+	Views int64 `json:"views"`
+	// UniqueViewers is the sum of the daily unique viewers.
+	UniqueViewers int64 `json:"unique_viewers"`
+	Likes         int64 `json:"likes"`
+	Dislikes      int64 `json:"dislikes"`
+	Comments      int64 `json:"comments"`
+}
+
+type TopPost struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	Views    int64  `json:"views"`
+	Likes    int64  `json:"likes"`
+	Comments int64  `json:"comments"`
+}
+
+type PostStatsResponse struct {
+	PostID int64         `json:"post_id"`
+	From   string        `json:"from"`
+	To     string        `json:"to"`
+	Totals StatsTotals   `json:"totals"`
+	Days   []*DailyStats `json:"days"`
+}
+
+type UserStatsResponse struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Totals   StatsTotals   `json:"totals"`
+	Days     []*DailyStats `json:"days"`
+	TopPosts []*TopPost    `json:"top_posts"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type PostTranslation struct {
+	PostID          int64          `json:"post_id"`
+	Language        string         `json:"language"`
+	Title           string         `json:"title"`
+	Slug            string         `json:"slug"`
+	Description     string         `json:"description"`
+	DescriptionHtml string         `json:"description_html"`
+	TableOfContents []*PostHeading `json:"table_of_contents"`
+	ReadingTime     int32          `json:"reading_time"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       *time.Time     `json:"updated_at"`
+}
+
+type SetPostTranslationRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description" example:"Markdown text"`
+	// Slug is derived from the title when empty.
+	Slug string `json:"slug" binding:"max=100"`
+}
+
+type GetPostTranslationsResponse struct {
+	Translations []*PostTranslation `json:"translations"`
+}
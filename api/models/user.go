@@ -28,6 +28,8 @@ type CreateUserRequest struct {
 }
 
 type GetAllUsersResponse struct {
-	Users []*User `json:"users"`
-	Count int32   `json:"count"`
+	Users      []*User `json:"users"`
+	Count      int32   `json:"count"`
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
 }
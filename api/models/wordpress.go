@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// WordPressImport reports the progress of an import. Total is known once
+// the worker has read the file.
+type WordPressImport struct {
+	ID         int64                   `json:"id"`
+	UserID     int64                   `json:"user_id"`
+	Status     string                  `json:"status" enums:"pending,running,done,failed"`
+	Total      int64                   `json:"total"`
+	Processed  int64                   `json:"processed"`
+	Failed     int64                   `json:"failed"`
+	Error      *string                 `json:"error"`
+	CreatedAt  time.Time               `json:"created_at"`
+	UpdatedAt  *time.Time              `json:"updated_at"`
+	FinishedAt *time.Time              `json:"finished_at"`
+	Errors     []*WordPressImportError `json:"errors"`
+}
+
+type WordPressImportError struct {
+	Kind      string    `json:"kind"`
+	WpID      string    `json:"wp_id"`
+	Error     string    `json:"error"`
+	CreatedAt time.Time `json:"created_at"`
+}
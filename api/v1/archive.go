@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/archive"
+)
+
+// maxImportSize is the largest archive ImportPosts accepts.
+const maxImportSize = 64 << 20
+
+// @Security ApiKeyAuth
+// @Router /posts/import [post]
+// @Summary Import posts
+// @Description Import a ZIP of Markdown files with YAML front matter (title, category, tags, date, updated, author, image, visibility). Missing categories are created and files without an author are imported as the current user. Superadmins only.
+// @Tags post
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "ZIP archive"
+// @Success 200 {object} models.ImportPostsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+func (h *handlerV1) ImportPosts(ctx *gin.Context) {
+	var file File
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+	if err := ctx.ShouldBind(&file); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	f, err := file.File.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+	defer f.Close()
+
+	result, err := archive.Import(h.Storage, f, file.File.Size, &archive.ImportOptions{
+		DefaultAuthor: payload.Email,
+		Language:      h.cfg.Languages.Default,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	response := models.ImportPostsResponse{
+		Imported: result.Imported,
+		Failed:   result.Failed,
+		Files:    make([]*models.ImportFileResult, 0, len(result.Files)),
+	}
+	for _, r := range result.Files {
+		file := models.ImportFileResult{
+			Name: r.Name,
+		}
+		if r.Err != nil {
+			msg := r.Err.Error()
+			file.Error = &msg
+		} else {
+			id := r.PostID
+			file.PostID = &id
+		}
+		response.Files = append(response.Files, &file)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/export [get]
+// @Summary Export posts
+// @Description Export every post as a ZIP of Markdown files with YAML front matter, in the format /posts/import reads. Superadmins only.
+// @Tags post
+// @Produce application/zip
+// @Success 200 {file} binary
+// @Failure 500 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+func (h *handlerV1) ExportPosts(ctx *gin.Context) {
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", `attachment; filename="posts.zip"`)
+	ctx.Status(http.StatusOK)
+
+	// the archive is streamed, so the status can't change any more
+	_, err := archive.Export(h.Storage, ctx.Writer)
+	if err != nil {
+		log.Printf("failed to export posts: %v", err)
+	}
+}
@@ -158,6 +158,7 @@ func (h *handlerV1) Verify(ctx *gin.Context) {
 	token, _, err := utils.CreateToken(h.cfg, &utils.TokenParams{
 		UserID:   user.ID,
 		Email:    user.Email,
+		UserType: user.Type,
 		Duration: time.Hour * 24 * 360,
 	})
 	if err != nil {
@@ -216,6 +217,7 @@ func (h *handlerV1) Login(ctx *gin.Context) {
 	token, _, err := utils.CreateToken(h.cfg, &utils.TokenParams{
 		UserID:   user.ID,
 		Email:    user.Email,
+		UserType: user.Type,
 		Duration: time.Hour * 24 * 360,
 	})
 	if err != nil {
@@ -316,6 +318,7 @@ func (h *handlerV1) VerifyForgotPassword(ctx *gin.Context) {
 	token, _, err := utils.CreateToken(h.cfg, &utils.TokenParams{
 		UserID:   result.ID,
 		Email:    result.Email,
+		UserType: result.Type,
 		Duration: time.Minute * 30,
 	})
 	if err != nil {
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -43,8 +45,9 @@ func (h *handlerV1) CreateCategory(ctx *gin.Context) {
 		})
 		return
 	}
+	h.invalidateSitemap(repo.SitemapCategories, category.ID)
 
-	ctx.JSON(http.StatusOK, repo.Category{
+	ctx.JSON(http.StatusOK, models.Category{
 		ID:        category.ID,
 		Title:     category.Title,
 		CreatedAt: category.CreatedAt,
@@ -74,13 +77,17 @@ func (h *handlerV1) GetCategory(ctx *gin.Context) {
 	category, err := h.Storage.Category().Get(id)
 
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, models.ResponseError{
 			Error: err.Error(),
 		})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, repo.Category{
+	ctx.JSON(http.StatusOK, models.Category{
 		ID:        category.ID,
 		Title:     category.Title,
 		CreatedAt: category.CreatedAt,
@@ -130,6 +137,7 @@ func (h *handlerV1) UpdateCategory(ctx *gin.Context) {
 		})
 		return
 	}
+	h.invalidateSitemap(repo.SitemapCategories, category.ID)
 
 	ctx.JSON(http.StatusOK, models.Category{
 		ID:        category.ID,
@@ -159,11 +167,16 @@ func (h *handlerV1) DeleteCategory(ctx *gin.Context) {
 
 	err = h.Storage.Category().Delete(id)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, models.ResponseError{
 			Error: err.Error(),
 		})
 		return
 	}
+	h.invalidateSitemap(repo.SitemapCategories, id)
 
 	ctx.JSON(http.StatusOK, models.ResponseSuccess{
 		Success: "Succesfully deleted!",
@@ -187,24 +200,160 @@ func (h *handlerV1) GetAllCategories(ctx *gin.Context) {
 		return
 	}
 
+	sort, err := parseSortParam(params.Sort)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	after, before, err := decodeCursors(params.After, params.Before, params.Sort)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
 	category, err := h.Storage.Category().GetAll(&repo.GetAllCategoryParams{
 		Limit:  int32(params.Limit),
 		Page:   int32(params.Page),
 		Search: params.Search,
+		Sort:   sort,
+		After:  after,
+		Before: before,
+	})
+
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidSortField) || errors.Is(err, repo.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getCategoriesResponse(category, params.Sort))
+}
+
+// @Security ApiKeyAuth
+// @Router /categories/trash [get]
+// @Summary Get deleted categories
+// @Description Get deleted categories. Only for admins.
+// @Tags category
+// @Accept json
+// @Produce json
+// @Param filter query models.GetAllParams false "Filter"
+// @Success 200 {object} models.GetCategoriesResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+func (h *handlerV1) GetCategoriesTrash(ctx *gin.Context) {
+	params, err := validateGetAllParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if payload.UserType != repo.UserTypeSuperadmin {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	sort, err := parseSortParam(params.Sort)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	after, before, err := decodeCursors(params.After, params.Before, params.Sort)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	category, err := h.Storage.Category().GetAll(&repo.GetAllCategoryParams{
+		Limit:   int32(params.Limit),
+		Page:    int32(params.Page),
+		Search:  params.Search,
+		Sort:    sort,
+		After:   after,
+		Before:  before,
+		Deleted: true,
 	})
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidSortField) || errors.Is(err, repo.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getCategoriesResponse(category, params.Sort))
+}
+
+// @Security ApiKeyAuth
+// @Router /categories/{id}/restore [post]
+// @Summary Restore a deleted category
+// @Description Restore a deleted category. Only for admins.
+// @Tags category
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ResponseSuccess
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+// @Failure 409 {object} models.ResponseError
+func (h *handlerV1) RestoreCategory(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
 
+	payload, err := h.GetAuthPayload(ctx)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, getCategoriesResponse(category))
+	if payload.UserType != repo.UserTypeSuperadmin {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	err = h.Storage.Category().Restore(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		if errors.Is(err, repo.ErrCategoryExists) {
+			ctx.JSON(http.StatusConflict, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+	h.invalidateSitemap(repo.SitemapCategories, id)
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Success: "Successfully restored!",
+	})
 }
 
-func getCategoriesResponse(categories *repo.GetAllCategoryResult) *models.GetCategoriesResponse {
+func getCategoriesResponse(categories *repo.GetAllCategoryResult, sort string) *models.GetCategoriesResponse {
 	response := models.GetCategoriesResponse{
 		Categories: make([]*models.Category, 0),
 		Count:      int64(categories.Count),
+		NextCursor: encodeCursor(categories.NextCursor, sort),
+		PrevCursor: encodeCursor(categories.PrevCursor, sort),
 	}
 
 	for _, c := range categories.Categories {
@@ -212,6 +361,7 @@ func getCategoriesResponse(categories *repo.GetAllCategoryResult) *models.GetCat
 			ID:        c.ID,
 			Title:     c.Title,
 			CreatedAt: c.CreatedAt,
+			DeletedAt: c.DeletedAt,
 		})
 	}
 	return &response
@@ -1,24 +1,32 @@
 package v1
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/filter"
 	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
 )
 
 // @Security ApiKeyAuth
 // @Router /comments [post]
 // @Summary Create a comment
-// @Description Create a comment
+// @Description Create a comment, or a reply when parent_id is given. Replies can only be nested up to the configured depth. Depending on the comment settings of the post the comment is held for moderation, except for the contributors of the post and admins. The content filter can hold the comment too, or reject it with 422. Users mentioned as @username are notified once the comment is approved.
 // @Tags comment
 // @Accept json
 // @Produce json
 // @Param post body models.CreateCommentRequest true "Post"
 // @Success 201 {object} models.Comment
 // @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+// @Failure 422 {object} models.ResponseError
 func (h *handlerV1) CreateComment(ctx *gin.Context) {
 	var (
 		req models.CreateCommentRequest
@@ -39,16 +47,87 @@ func (h *handlerV1) CreateComment(ctx *gin.Context) {
 		return
 	}
 
+	post, err := h.Storage.Post().Get(req.PostID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if post.Visibility == repo.PostVisibilityPrivate && !h.canBypassVisibility(ctx, post) {
+		ctx.JSON(http.StatusNotFound, errResponse(sql.ErrNoRows))
+		return
+	}
+
+	status, err := h.newCommentStatus(ctx, post, payload.UserID)
+	if err != nil {
+		if errors.Is(err, ErrCommentsClosed) {
+			ctx.JSON(http.StatusForbidden, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if req.ParentID != nil {
+		parent, err := h.Storage.Comment().Get(*req.ParentID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				ctx.JSON(http.StatusNotFound, errResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errResponse(err))
+			return
+		}
+
+		if parent.PostID != req.PostID {
+			ctx.JSON(http.StatusBadRequest, errResponse(ErrCommentParentPost))
+			return
+		}
+
+		if parent.Status != repo.CommentStatusApproved {
+			ctx.JSON(http.StatusBadRequest, errResponse(ErrCommentNotApproved))
+			return
+		}
+
+		if parent.Depth+1 > h.cfg.Comments.MaxDepth {
+			ctx.JSON(http.StatusBadRequest, errResponse(ErrCommentTooDeep))
+			return
+		}
+	}
+
+	content := filter.Content{
+		Kind:   filter.KindComment,
+		Text:   req.Description,
+		UserID: payload.UserID,
+	}
+	verdict, ok := h.filterContent(ctx, &content)
+	if !ok {
+		return
+	}
+	if verdict != nil && verdict.Action == filter.Hold {
+		status = repo.CommentStatusPending
+	}
+
 	comment, err := h.Storage.Comment().Create(&repo.Comment{
 		Description: req.Description,
 		UserID:      payload.UserID,
 		PostID:      req.PostID,
+		ParentID:    req.ParentID,
+		Status:      status,
 	})
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
+	h.recordFilterOutcome(&content, verdict, comment.ID)
+	comment.Mentions = h.setMentions(repo.MentionTargetComment, comment.ID, payload.UserID, comment.Description)
+	go h.notifyMentions(repo.MentionTargetComment, comment.ID)
+
 	c := parseCommentModel(comment)
 	ctx.JSON(http.StatusOK, c)
 }
@@ -56,7 +135,7 @@ func (h *handlerV1) CreateComment(ctx *gin.Context) {
 // @Security ApiKeyAuth
 // @Router /comments/{id} [put]
 // @Summary Update comment with it's id as param
-// @Description Update comment with it's id as param
+// @Description Update comment with it's id as param. Authors can only edit their comments within the configured time after posting them, admins can always edit. The replaced description is kept in the history of the comment. The comment goes back to moderation when the content filter holds it, and isn't changed when the filter rejects it.
 // @Tags comment
 // @Accept json
 // @Produce json
@@ -64,6 +143,9 @@ func (h *handlerV1) CreateComment(ctx *gin.Context) {
 // @Param comment body models.UpdateCommentRequest true "Comment"
 // @Success 201 {object} models.UpdateComment
 // @Failure 500 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+// @Failure 422 {object} models.ResponseError
 func (h *handlerV1) UpdateComment(ctx *gin.Context) {
 	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
 	if err != nil {
@@ -80,15 +162,65 @@ func (h *handlerV1) UpdateComment(ctx *gin.Context) {
 		return
 	}
 
-	comment, err := h.Storage.Comment().Update(&repo.UpdateComment{
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	old, err := h.Storage.Comment().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if !h.isOwnerOrAdmin(ctx, old.UserID) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	if window := h.cfg.Comments.EditWindow; window > 0 && !h.isSuperadmin(ctx) && time.Since(old.CreatedAt) > window {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrCommentEditWindow))
+		return
+	}
+
+	content := filter.Content{
+		Kind:   filter.KindComment,
+		ID:     id,
+		Text:   req.Description,
+		UserID: payload.UserID,
+	}
+	verdict, ok := h.filterContent(ctx, &content)
+	if !ok {
+		return
+	}
+
+	u := repo.UpdateComment{
 		ID:          id,
 		Description: req.Description,
-	})
+		EditedBy:    payload.UserID,
+	}
+	if verdict != nil && verdict.Action == filter.Hold {
+		u.Status = repo.CommentStatusPending
+	}
+
+	comment, err := h.Storage.Comment().Update(&u)
 
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
+	h.recordFilterOutcome(&content, verdict, comment.ID)
+	mentions := h.setMentions(repo.MentionTargetComment, comment.ID, payload.UserID, comment.Description)
+	go h.notifyMentions(repo.MentionTargetComment, comment.ID)
 
 	ctx.JSON(http.StatusOK, models.UpdateComment{
 		ID:          comment.ID,
@@ -97,29 +229,128 @@ func (h *handlerV1) UpdateComment(ctx *gin.Context) {
 		PostID:      comment.PostID,
 		CreatedAt:   comment.CreatedAt,
 		UpdatedAt:   comment.UpdatedAt,
+		Status:      comment.Status,
+		Mentions:    parseMentions(mentions),
+		Edited:      comment.EditCount > 0,
+		EditCount:   comment.EditCount,
 	})
 }
 
+// @Security ApiKeyAuth
+// @Router /comments/{id}/history [get]
+// @Summary Get the edit history of a comment
+// @Description Get the earlier descriptions of a comment, oldest first, along with the comment. Only the author, the contributors of the post and admins can see it.
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.GetCommentHistoryResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetCommentHistory(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	comment, err := h.Storage.Comment().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if !h.isOwnerOrAdmin(ctx, comment.UserID) {
+		post, err := h.Storage.Post().Get(comment.PostID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusInternalServerError, errResponse(err))
+			return
+		}
+		if post == nil || !h.canEditPost(ctx, post) {
+			ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+			return
+		}
+	}
+
+	revisions, err := h.Storage.Comment().GetRevisions(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	c := parseCommentModel(comment)
+	res := models.GetCommentHistoryResponse{
+		Revisions: make([]*models.CommentRevision, 0, len(revisions)),
+		Comment:   &c,
+	}
+	for _, r := range revisions {
+		res.Revisions = append(res.Revisions, &models.CommentRevision{
+			Description: r.Description,
+			WrittenAt:   r.WrittenAt,
+			EditedBy:    r.EditedBy,
+			EditedAt:    r.EditedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
 // @Security ApiKeyAuth
 // @Router /comments/{id} [delete]
 // @Summary Delete a comment
-// @Description Delete a comment
+// @Description Delete a comment. Only its author, the contributors of its post and admins can delete it.
 // @Tags comment
 // @Accept json
 // @Produce json
 // @Param id path int true "ID"
 // @Success 201 {object} models.ResponseSuccess
 // @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
 func (h *handlerV1) DeleteComment(ctx *gin.Context) {
 	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
 	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	comment, err := h.Storage.Comment().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
 
+	if !h.isOwnerOrAdmin(ctx, comment.UserID) {
+		// the contributors of the post moderate its comments
+		post, err := h.Storage.Post().Get(comment.PostID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusInternalServerError, errResponse(err))
+			return
+		}
+		if post == nil || !h.canEditPost(ctx, post) {
+			ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+			return
+		}
+	}
+
 	err = h.Storage.Comment().Delete(id)
 
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
@@ -129,40 +360,326 @@ func (h *handlerV1) DeleteComment(ctx *gin.Context) {
 	})
 }
 
+// @Security ApiKeyAuth
 // @Router /comments [get]
 // @Summary Get comments by giving limit, page and user_id, post_id.
-// @Description Get comments by giving limit, page and user_id, post_id.
+// @Description Get comments by giving limit, page and user_id, post_id. With threaded=true the comments come depth first with their depth and path, deleted comments that still have replies being kept as tombstones. parent_id lists the replies of a comment and max_depth leaves out deeper replies.
+// @Description Only comments on posts the user can see are listed, comments on a password protected post needing the token returned by /posts/{id}/unlock along with its post_id.
 // @Tags comment
 // @Accept json
 // @Produce json
 // @Param filter query models.GetAllCommentsParams false "Filter"
+// @Param X-Post-Token header string false "Post unlock token"
+// @Param post_token query string false "Post unlock token"
 // @Success 201 {object} models.GetAllCommentsResponse
 // @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
 func (h *handlerV1) GetAllComments(c *gin.Context) {
 	params, err := validateGetAllCommentsParams(c)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	sort, err := parseSortParam(params.Sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	cursorSort := params.Sort
+	if params.Threaded {
+		cursorSort += "+threaded"
+	}
+
+	after, before, err := decodeCursors(params.After, params.Before, cursorSort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	filter := repo.GetCommentsParams{
+		Limit:    params.Limit,
+		Page:     params.Page,
+		UserID:   params.UserID,
+		PostID:   params.PostID,
+		Sort:     sort,
+		After:    after,
+		Before:   before,
+		Threaded: params.Threaded,
+		ParentID: params.ParentID,
+		MaxDepth: params.MaxDepth,
+	}
+	if payload, err := h.GetAuthPayload(c); err == nil {
+		filter.IsSuperadmin = payload.UserType == repo.UserTypeSuperadmin
+		filter.ViewerID = payload.UserID
+	}
+	if params.PostID != 0 && h.isPostUnlocked(c, params.PostID) {
+		filter.UnlockedPostID = params.PostID
+	}
+
+	result, err := h.Storage.Comment().GetAll(&filter)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidSortField) || errors.Is(err, repo.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
 
-	result, err := h.Storage.Comment().GetAll(&repo.GetCommentsParams{
-		Limit:  params.Limit,
-		Page:   params.Page,
-		UserID: params.UserID,
-		PostID: params.PostID,
-	})
+	res := getCommentsResponse(result, cursorSort)
+	for _, comment := range res.Comments {
+		if comment.DeletedAt != nil || comment.Status != repo.CommentStatusApproved {
+			tombstoneComment(comment)
+		}
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// @Security ApiKeyAuth
+// @Router /comments/trash [get]
+// @Summary Get deleted comments
+// @Description Get deleted comments. Admins see the whole trash, other users only their own comments.
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param filter query models.GetAllCommentsParams false "Filter"
+// @Success 200 {object} models.GetAllCommentsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+func (h *handlerV1) GetCommentsTrash(c *gin.Context) {
+	params, err := validateGetAllCommentsParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	sort, err := parseSortParam(params.Sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	after, before, err := decodeCursors(params.After, params.Before, params.Sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	filter := repo.GetCommentsParams{
+		Limit:   params.Limit,
+		Page:    params.Page,
+		UserID:  params.UserID,
+		PostID:  params.PostID,
+		Sort:    sort,
+		After:   after,
+		Before:  before,
+		Deleted: true,
+	}
+	if payload.UserType != repo.UserTypeSuperadmin {
+		filter.UserID = payload.UserID
+	}
+
+	result, err := h.Storage.Comment().GetAll(&filter)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidSortField) || errors.Is(err, repo.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, getCommentsResponse(result, params.Sort))
+}
+
+// @Security ApiKeyAuth
+// @Router /comments/moderation [get]
+// @Summary Get the moderation queue
+// @Description Get the comments in a moderation status, pending by default. Admins see every comment, other users the comments on the posts they contribute to.
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param filter query models.GetAllCommentsParams false "Filter"
+// @Param status query string false "pending (default), approved, rejected or spam"
+// @Success 200 {object} models.GetAllCommentsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+func (h *handlerV1) GetModerationQueue(c *gin.Context) {
+	params, err := validateGetAllCommentsParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	status := c.DefaultQuery("status", repo.CommentStatusPending)
+	if !isCommentStatus(status) {
+		c.JSON(http.StatusBadRequest, errResponse(ErrInvalidCommentStatus))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, getCommentsResponse(result))
+	sort, err := parseSortParam(params.Sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	cursorSort := params.Sort + "@" + status
+	after, before, err := decodeCursors(params.After, params.Before, cursorSort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	filter := repo.GetCommentsParams{
+		Limit:    params.Limit,
+		Page:     params.Page,
+		UserID:   params.UserID,
+		PostID:   params.PostID,
+		Sort:     sort,
+		After:    after,
+		Before:   before,
+		ParentID: params.ParentID,
+		Status:   status,
+	}
+	if payload.UserType != repo.UserTypeSuperadmin {
+		filter.ModeratorID = payload.UserID
+		filter.ViewerID = payload.UserID
+	} else {
+		filter.IsSuperadmin = true
+	}
+
+	result, err := h.Storage.Comment().GetAll(&filter)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidSortField) || errors.Is(err, repo.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, getCommentsResponse(result, cursorSort))
+}
+
+// @Security ApiKeyAuth
+// @Router /comments/moderation [post]
+// @Summary Moderate comments
+// @Description Approve, reject, mark as spam or send back to pending up to 100 comments at once. Admins can moderate every comment, other users the comments on the posts they contribute to; the others are skipped.
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param data body models.ModerateCommentsRequest true "Data"
+// @Success 200 {object} models.ModerateCommentsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+func (h *handlerV1) ModerateComments(ctx *gin.Context) {
+	var (
+		req models.ModerateCommentsRequest
+	)
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	m := repo.ModerateComments{
+		IDs:         req.IDs,
+		Status:      req.Status,
+		ModeratorID: payload.UserID,
+	}
+	if payload.UserType != repo.UserTypeSuperadmin {
+		m.UserID = payload.UserID
+	}
+
+	n, err := h.Storage.Comment().Moderate(&m)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	// mentions in held comments are notified once they are approved
+	if req.Status == repo.CommentStatusApproved {
+		go h.notifyMentions(repo.MentionTargetComment, req.IDs...)
+	}
+
+	ctx.JSON(http.StatusOK, models.ModerateCommentsResponse{
+		Moderated: n,
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router /comments/{id}/restore [post]
+// @Summary Restore a deleted comment
+// @Description Restore a deleted comment. Users can only restore their own comments.
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ResponseSuccess
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) RestoreComment(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	var userID int64
+	if payload.UserType != repo.UserTypeSuperadmin {
+		userID = payload.UserID
+	}
+
+	err = h.Storage.Comment().Restore(id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Success: "Successfully restored!",
+	})
 }
 
-func getCommentsResponse(data *repo.GetAllCommentsResult) *models.GetAllCommentsResponse {
+func getCommentsResponse(data *repo.GetAllCommentsResult, sort string) *models.GetAllCommentsResponse {
 	response := models.GetAllCommentsResponse{
-		Comments: make([]*models.Comment, 0),
-		Count:    data.Count,
+		Comments:   make([]*models.Comment, 0),
+		Count:      data.Count,
+		NextCursor: encodeCursor(data.NextCursor, sort),
+		PrevCursor: encodeCursor(data.PrevCursor, sort),
 	}
 
 	for _, comment := range data.Comments {
@@ -173,6 +690,50 @@ func getCommentsResponse(data *repo.GetAllCommentsResult) *models.GetAllComments
 	return &response
 }
 
+// newCommentStatus decides whether a new comment of the user on the post
+// goes live or waits for moderation. Contributors of the post and admins
+// are never moderated.
+func (h *handlerV1) newCommentStatus(ctx *gin.Context, post *repo.Post, userID int64) (string, error) {
+	if h.canEditPost(ctx, post) {
+		return repo.CommentStatusApproved, nil
+	}
+
+	switch post.CommentStatus {
+	case repo.PostCommentsClosed:
+		return "", ErrCommentsClosed
+	case repo.PostCommentsModerated:
+		return repo.CommentStatusPending, nil
+	case repo.PostCommentsFirstTimeModerated:
+		approved, err := h.Storage.Comment().HasApproved(userID)
+		if err != nil {
+			return "", err
+		}
+		if !approved {
+			return repo.CommentStatusPending, nil
+		}
+	}
+
+	return repo.CommentStatusApproved, nil
+}
+
+func isCommentStatus(status string) bool {
+	switch status {
+	case repo.CommentStatusPending, repo.CommentStatusApproved, repo.CommentStatusRejected, repo.CommentStatusSpam:
+		return true
+	}
+	return false
+}
+
+// tombstoneComment hides the content and author of a deleted or
+// unapproved comment that is only listed to keep its replies in place.
+func tombstoneComment(comment *models.Comment) {
+	comment.Description = ""
+	comment.UserID = 0
+	comment.User = nil
+	comment.Mentions = nil
+	comment.Deleted = true
+}
+
 func parseCommentModel(comment *repo.Comment) models.Comment {
 	return models.Comment{
 		ID:          comment.ID,
@@ -181,11 +742,21 @@ func parseCommentModel(comment *repo.Comment) models.Comment {
 		PostID:      comment.PostID,
 		CreatedAt:   comment.CreatedAt,
 		UpdatedAt:   comment.UpdatedAt,
+		DeletedAt:   comment.DeletedAt,
 		User: &models.CommentUser{
 			FirstName:       comment.User.FirstName,
 			Lastname:        comment.User.LastName,
 			Email:           comment.User.Email,
 			ProfileImageUrl: comment.User.ProfileImageUrl,
 		},
+		ParentID:    comment.ParentID,
+		Depth:       comment.Depth,
+		Path:        comment.Path,
+		ReplyCount:  comment.ReplyCount,
+		Status:      comment.Status,
+		ModeratedAt: comment.ModeratedAt,
+		Mentions:    parseMentions(comment.Mentions),
+		Edited:      comment.EditCount > 0,
+		EditCount:   comment.EditCount,
 	}
 }
@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+)
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/contributors/{user_id} [put]
+// @Summary Add or change a contributor of a post
+// @Description Co-authors and editors can edit the post. Only the author and admins can manage contributors.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param user_id path int true "User ID"
+// @Param data body models.SetContributorRequest true "Data"
+// @Success 200 {object} models.Post
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) SetPostContributor(ctx *gin.Context) {
+	var (
+		req models.SetContributorRequest
+	)
+
+	id, userID, ok := h.parseContributorParams(ctx)
+	if !ok {
+		return
+	}
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	post, ok := h.getOwnPost(ctx, id)
+	if !ok {
+		return
+	}
+
+	if userID == post.UserID {
+		ctx.JSON(http.StatusBadRequest, errResponse(ErrPostAuthorRole))
+		return
+	}
+
+	_, err = h.Storage.User().Get(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	err = h.Storage.Post().SetContributor(id, userID, req.Role)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	post, err = h.Storage.Post().Get(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, parsePostModel(post))
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/contributors/{user_id} [delete]
+// @Summary Remove a contributor from a post
+// @Description Only the author and admins can manage contributors. The author can't be removed.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} models.ResponseSuccess
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) RemovePostContributor(ctx *gin.Context) {
+	id, userID, ok := h.parseContributorParams(ctx)
+	if !ok {
+		return
+	}
+
+	if _, ok := h.getOwnPost(ctx, id); !ok {
+		return
+	}
+
+	err := h.Storage.Post().RemoveContributor(id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Success: "Successfully removed!",
+	})
+}
+
+func (h *handlerV1) parseContributorParams(ctx *gin.Context) (int64, int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return 0, 0, false
+	}
+
+	userID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return 0, 0, false
+	}
+
+	return id, userID, true
+}
@@ -0,0 +1,283 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/pin [put]
+// @Summary Pin a post
+// @Description Pin a post globally, or within its category when category_id is given. Lower positions come first. Superadmins only.
+// @Tags curation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param data body models.PinPostRequest true "Data"
+// @Success 200 {object} models.Pin
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) PinPost(ctx *gin.Context) {
+	var (
+		req models.PinPostRequest
+	)
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, errResponse(ErrPinExpired))
+		return
+	}
+
+	post, err := h.Storage.Post().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if req.CategoryID != nil && *req.CategoryID != post.CategoryID {
+		ctx.JSON(http.StatusBadRequest, errResponse(ErrPinCategory))
+		return
+	}
+
+	pin, err := h.Storage.Curation().Pin(&repo.Pin{
+		PostID:     id,
+		CategoryID: req.CategoryID,
+		Position:   req.Position,
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, parsePinModel(pin))
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/pin [delete]
+// @Summary Unpin a post
+// @Description Remove the global pin of a post, or its category pin when category_id is given. Superadmins only.
+// @Tags curation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param category_id query int false "Category ID"
+// @Success 200 {object} models.ResponseSuccess
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) UnpinPost(ctx *gin.Context) {
+	var categoryID int64
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	if ctx.Query("category_id") != "" {
+		categoryID, err = strconv.ParseInt(ctx.Query("category_id"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
+	}
+
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	err = h.Storage.Curation().Unpin(id, categoryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Success: "Successfully unpinned!",
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/pins [get]
+// @Summary Get pins
+// @Description Get the pins that haven't expired, global ones first. Superadmins only.
+// @Tags curation
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.GetPinsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+func (h *handlerV1) GetPins(ctx *gin.Context) {
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	pins, err := h.Storage.Curation().GetPins()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	response := models.GetPinsResponse{
+		Pins: make([]*models.Pin, 0, len(pins)),
+	}
+	for _, pin := range pins {
+		p := parsePinModel(pin)
+		response.Pins = append(response.Pins, &p)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Router /posts/featured [get]
+// @Summary Get featured posts
+// @Description Get the featured posts in their curated order
+// @Tags curation
+// @Accept json
+// @Produce json
+// @Param filter query models.GetAllParams false "Filter"
+// @Success 200 {object} models.GetAllPostsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+func (h *handlerV1) GetFeaturedPosts(c *gin.Context) {
+	params, err := validateGetAllParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	// featured posts are always in their curated order first
+	cursorSort := "featured"
+
+	after, before, err := decodeCursors(params.After, params.Before, cursorSort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	filter := repo.GetPostsParams{
+		Limit:    params.Limit,
+		Page:     params.Page,
+		After:    after,
+		Before:   before,
+		Featured: true,
+	}
+	if payload, err := h.GetAuthPayload(c); err == nil {
+		filter.ViewerID = payload.UserID
+		filter.IsSuperadmin = payload.UserType == repo.UserTypeSuperadmin
+	}
+
+	result, err := h.Storage.Post().GetAll(&filter)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	err = h.localizePosts(c, result.Posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	for _, post := range result.Posts {
+		if post.Visibility == repo.PostVisibilityPassword && !h.canBypassVisibility(c, post) {
+			lockPost(post)
+		}
+	}
+
+	c.JSON(http.StatusOK, getPostsResponse(result, cursorSort))
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/featured [put]
+// @Summary Set featured posts
+// @Description Replace the featured posts, in the given order. Superadmins only.
+// @Tags curation
+// @Accept json
+// @Produce json
+// @Param data body models.SetFeaturedPostsRequest true "Data"
+// @Success 200 {object} models.ResponseSuccess
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+func (h *handlerV1) SetFeaturedPosts(ctx *gin.Context) {
+	var (
+		req models.SetFeaturedPostsRequest
+	)
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	err = h.Storage.Curation().SetFeatured(req.PostIDs)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidFeaturedPosts) {
+			ctx.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Success: "Featured posts have been updated!",
+	})
+}
+
+func parsePinModel(pin *repo.Pin) models.Pin {
+	return models.Pin{
+		PostID:     pin.PostID,
+		CategoryID: pin.CategoryID,
+		Position:   pin.Position,
+		ExpiresAt:  pin.ExpiresAt,
+		CreatedAt:  pin.CreatedAt,
+	}
+}
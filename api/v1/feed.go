@@ -0,0 +1,290 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/feed"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+const (
+	feedSize        = 20
+	feedExcerptSize = 300
+
+	feedContentFull    = "full"
+	feedContentExcerpt = "excerpt"
+)
+
+type feedFormat struct {
+	contentType string
+	write       func(*feed.Feed) ([]byte, error)
+}
+
+var feedFormats = map[string]*feedFormat{
+	"posts.rss":  {contentType: "application/rss+xml; charset=utf-8", write: feed.RSS},
+	"posts.atom": {contentType: "application/atom+xml; charset=utf-8", write: feed.Atom},
+	"posts.json": {contentType: "application/feed+json; charset=utf-8", write: feed.JSON},
+}
+
+// cachedFeed is a rendered feed as it is kept in Redis.
+type cachedFeed struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// @Router /feeds/{file} [get]
+// @Summary Get the posts feed
+// @Description Get the latest public posts as RSS (posts.rss), Atom (posts.atom) or JSON Feed (posts.json). Supports If-None-Match and If-Modified-Since.
+// @Tags feed
+// @Produce xml
+// @Produce json
+// @Param file path string true "posts.rss, posts.atom or posts.json"
+// @Param content query string false "full (default) or excerpt"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetPostsFeed(ctx *gin.Context) {
+	h.serveFeed(ctx, "posts", func() (*feed.Feed, error) {
+		return h.postsFeed(h.cfg.Site.Title, &repo.GetPostsParams{})
+	})
+}
+
+// @Router /feeds/categories/{id}/{file} [get]
+// @Summary Get the posts feed of a category
+// @Description Get the latest public posts of a category as RSS (posts.rss), Atom (posts.atom) or JSON Feed (posts.json). Supports If-None-Match and If-Modified-Since.
+// @Tags feed
+// @Produce xml
+// @Produce json
+// @Param id path int true "Category ID"
+// @Param file path string true "posts.rss, posts.atom or posts.json"
+// @Param content query string false "full (default) or excerpt"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetCategoryFeed(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	h.serveFeed(ctx, "category_"+strconv.FormatInt(id, 10), func() (*feed.Feed, error) {
+		category, err := h.Storage.Category().Get(id)
+		if err != nil {
+			return nil, err
+		}
+
+		return h.postsFeed(h.cfg.Site.Title+" - "+category.Title, &repo.GetPostsParams{
+			CategoryID: id,
+		})
+	})
+}
+
+// @Router /feeds/users/{id}/{file} [get]
+// @Summary Get the posts feed of an author
+// @Description Get the latest public posts of an author as RSS (posts.rss), Atom (posts.atom) or JSON Feed (posts.json). Supports If-None-Match and If-Modified-Since.
+// @Tags feed
+// @Produce xml
+// @Produce json
+// @Param id path int true "User ID"
+// @Param file path string true "posts.rss, posts.atom or posts.json"
+// @Param content query string false "full (default) or excerpt"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetUserFeed(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	h.serveFeed(ctx, "user_"+strconv.FormatInt(id, 10), func() (*feed.Feed, error) {
+		user, err := h.Storage.User().Get(id)
+		if err != nil {
+			return nil, err
+		}
+
+		return h.postsFeed(h.cfg.Site.Title+" - "+user.FirstName+" "+user.LastName, &repo.GetPostsParams{
+			UserID: id,
+		})
+	})
+}
+
+// serveFeed renders the feed built by build in the format asked for,
+// caching the result under scope, and answers conditional requests.
+func (h *handlerV1) serveFeed(ctx *gin.Context, scope string, build func() (*feed.Feed, error)) {
+	format, ok := feedFormats[ctx.Param("file")]
+	if !ok {
+		ctx.JSON(http.StatusNotFound, errResponse(ErrUnknownFeed))
+		return
+	}
+
+	content := ctx.DefaultQuery("content", feedContentFull)
+	if content != feedContentFull && content != feedContentExcerpt {
+		ctx.JSON(http.StatusBadRequest, errResponse(ErrInvalidFeedContent))
+		return
+	}
+
+	key := FeedKey + scope + "_" + ctx.Param("file") + "_" + content
+
+	var cached cachedFeed
+	if data, err := h.inMemory.Get(key); err != nil || json.Unmarshal([]byte(data), &cached) != nil {
+		f, err := build()
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				ctx.JSON(http.StatusNotFound, errResponse(err))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errResponse(err))
+			return
+		}
+
+		// the feed is cached for every query, so only the parameters
+		// it depends on make its URL
+		f.FeedURL = h.cfg.Site.URL + ctx.Request.URL.Path
+		if content != feedContentFull {
+			f.FeedURL += "?content=" + content
+		}
+		if content == feedContentExcerpt {
+			for _, item := range f.Items {
+				item.Content = ""
+			}
+		}
+
+		cached.Body, err = format.write(f)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errResponse(err))
+			return
+		}
+		sum := sha256.Sum256(cached.Body)
+		cached.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+		cached.LastModified = f.Updated
+
+		data, err := json.Marshal(cached)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errResponse(err))
+			return
+		}
+		if err := h.inMemory.Set(key, string(data), h.cfg.Site.FeedCacheDuration); err != nil {
+			log.Printf("failed to cache feed %s: %v", key, err)
+		}
+	}
+
+	ctx.Header("ETag", cached.ETag)
+	ctx.Header("Last-Modified", cached.LastModified.UTC().Format(http.TimeFormat))
+	ctx.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.cfg.Site.FeedCacheDuration.Seconds())))
+
+	if notModified(ctx.Request, cached.ETag, cached.LastModified) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.Data(http.StatusOK, format.contentType, cached.Body)
+}
+
+// notModified reports whether a conditional request can be answered with
+// 304. If-None-Match takes precedence over If-Modified-Since.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// postsFeed builds a feed of the latest public posts matching params.
+// Password protected posts are left out.
+func (h *handlerV1) postsFeed(title string, params *repo.GetPostsParams) (*feed.Feed, error) {
+	params.Limit = feedSize
+	params.Page = 1
+
+	result, err := h.Storage.Post().GetAll(params)
+	if err != nil {
+		return nil, err
+	}
+
+	f := feed.Feed{
+		Title:       title,
+		Description: title,
+		Link:        h.cfg.Site.URL,
+		Items:       make([]*feed.Item, 0, len(result.Posts)),
+	}
+
+	categories := make(map[int64]string)
+	for _, post := range result.Posts {
+		if post.Visibility != repo.PostVisibilityPublic {
+			continue
+		}
+
+		category, ok := categories[post.CategoryID]
+		if !ok {
+			c, err := h.Storage.Category().Get(post.CategoryID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			if c != nil {
+				category = c.Title
+			}
+			categories[post.CategoryID] = category
+		}
+
+		item := feed.Item{
+			ID:        h.postURL(post.ID),
+			Title:     post.Title,
+			Summary:   feed.Excerpt(post.DescriptionHtml, feedExcerptSize),
+			Content:   post.DescriptionHtml,
+			Author:    postAuthorName(post),
+			Image:     h.absoluteURL(post.ImageUrl),
+			Published: post.CreatedAt,
+			Updated:   post.CreatedAt,
+		}
+		item.Link = item.ID
+		if post.UpdatedAt != nil {
+			item.Updated = *post.UpdatedAt
+		}
+		if category != "" {
+			item.Categories = append(item.Categories, category)
+		}
+		item.Categories = append(item.Categories, post.Tags...)
+
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Items = append(f.Items, &item)
+	}
+
+	if f.Updated.IsZero() {
+		f.Updated = time.Now()
+	}
+
+	return &f, nil
+}
@@ -0,0 +1,197 @@
+package v1
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/config"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/filter"
+	"github.com/nurmuhammaddeveloper/blog_db/storage"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+const (
+	// duplicateMinWords keeps short replies like "thanks" from ever being
+	// duplicates.
+	duplicateMinWords  = 5
+	duplicateMaxOthers = 2
+)
+
+// newContentFilters builds the filter pipelines of comments and posts. The
+// scorers run after the built-in checks.
+func newContentFilters(cfg *config.Config, strg storage.StorageI, scorers []*filter.ScoreChecker) map[string]*filter.Pipeline {
+	build := func(maxLinks int) *filter.Pipeline {
+		checkers := []filter.Checker{
+			&filter.BannedWords{
+				Hold:   cfg.Filter.HoldWords,
+				Reject: cfg.Filter.RejectWords,
+			},
+			&filter.LinkLimit{
+				Max: maxLinks,
+			},
+			&filter.Duplicates{
+				Count: func(c *filter.Content) (int64, int64, error) {
+					since := time.Now().Add(-cfg.Filter.DuplicateWindow)
+					return strg.Filter().CountDuplicates(c.Hash(), c.UserID, c.Kind, c.ID, since)
+				},
+				MinWords:  duplicateMinWords,
+				MaxOthers: duplicateMaxOthers,
+			},
+			&filter.NewAccount{
+				MinAge: cfg.Filter.NewAccountAge,
+			},
+		}
+		for _, s := range scorers {
+			checkers = append(checkers, s)
+		}
+		return filter.New(checkers...)
+	}
+
+	return map[string]*filter.Pipeline{
+		filter.KindComment: build(cfg.Filter.CommentMaxLinks),
+		filter.KindPost:    build(cfg.Filter.PostMaxLinks),
+	}
+}
+
+// @Security ApiKeyAuth
+// @Router /filter/outcomes [get]
+// @Summary Get the content filter outcomes
+// @Description Get what the content filter decided about comments and posts and why, latest first. Only admins can see them.
+// @Tags filter
+// @Accept json
+// @Produce json
+// @Param filter query models.GetFilterOutcomesParams false "Filter"
+// @Success 200 {object} models.GetFilterOutcomesResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+func (h *handlerV1) GetFilterOutcomes(ctx *gin.Context) {
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	params, err := validateGetFilterOutcomesParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	result, err := h.Storage.Filter().GetAll(params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	res := models.GetFilterOutcomesResponse{
+		Outcomes: make([]*models.FilterOutcome, 0, len(result.Outcomes)),
+		Count:    result.Count,
+	}
+	for _, o := range result.Outcomes {
+		res.Outcomes = append(res.Outcomes, &models.FilterOutcome{
+			ID:          o.ID,
+			TargetType:  o.TargetType,
+			TargetID:    o.TargetID,
+			UserID:      o.UserID,
+			Action:      o.Action,
+			Reasons:     o.Reasons,
+			ContentHash: o.ContentHash,
+			CreatedAt:   o.CreatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// filterContent runs content of the requester through its filter. The
+// result is nil for admins, whose content isn't filtered. It responds and
+// returns false when the content is rejected or can't be checked.
+func (h *handlerV1) filterContent(ctx *gin.Context, c *filter.Content) (*filter.Result, bool) {
+	if h.isSuperadmin(ctx) {
+		return nil, true
+	}
+
+	user, err := h.Storage.User().Get(c.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return nil, false
+	}
+	c.UserCreatedAt = user.CreatedAt
+
+	res, err := h.contentFilters[c.Kind].Run(c)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return nil, false
+	}
+
+	if res.Action == filter.Reject {
+		h.recordFilterOutcome(c, res, 0)
+		ctx.JSON(http.StatusUnprocessableEntity, errResponse(
+			fmt.Errorf("%w: %s", ErrContentRejected, strings.Join(res.Reasons, "; ")),
+		))
+		return nil, false
+	}
+
+	return res, true
+}
+
+// recordFilterOutcome keeps the decision about content saved as targetID,
+// zero when it was rejected. A nil result has nothing to record.
+func (h *handlerV1) recordFilterOutcome(c *filter.Content, res *filter.Result, targetID int64) {
+	if res == nil {
+		return
+	}
+
+	o := repo.FilterOutcome{
+		TargetType:  c.Kind,
+		UserID:      c.UserID,
+		Action:      string(res.Action),
+		Reasons:     res.Reasons,
+		ContentHash: c.Hash(),
+	}
+	if targetID != 0 {
+		o.TargetID = &targetID
+	}
+
+	if _, err := h.Storage.Filter().Record(&o); err != nil {
+		log.Printf("failed to record the filter outcome of %s %d: %v", c.Kind, targetID, err)
+	}
+}
+
+func validateGetFilterOutcomesParams(ctx *gin.Context) (*repo.GetFilterOutcomesParams, error) {
+	var targetId, userId int64
+
+	limit, page, err := parsePagination(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Query("target_id") != "" {
+		targetId, err = strconv.ParseInt(ctx.Query("target_id"), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ctx.Query("user_id") != "" {
+		userId, err = strconv.ParseInt(ctx.Query("user_id"), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &repo.GetFilterOutcomesParams{
+		Limit:      limit,
+		Page:       page,
+		TargetType: ctx.Query("target_type"),
+		TargetID:   targetId,
+		UserID:     userId,
+		Action:     ctx.Query("action"),
+	}, nil
+}
@@ -2,12 +2,18 @@ package v1
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nurmuhammaddeveloper/blog_db/api/models"
 	"github.com/nurmuhammaddeveloper/blog_db/config"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/filter"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/utils"
 	"github.com/nurmuhammaddeveloper/blog_db/storage"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
 )
 
 var (
@@ -17,30 +23,81 @@ var (
 	ErrIncorrectCode        = errors.New("incorrect verification code")
 	ErrCodeExpired          = errors.New("verification is expired")
 	ErrForbidden            = errors.New("forbidden")
+	ErrEmptySearchQuery     = errors.New("search query is required")
+	ErrUnknownSearchType    = errors.New("unknown search type")
+	ErrInvalidSortDirection = errors.New("sort direction must be asc or desc")
+	ErrAfterAndBefore       = errors.New("after and before can't be used together")
+	ErrPostPasswordRequired = errors.New("password is required for password protected posts")
+	ErrPostLocked           = errors.New("post is password protected")
+	ErrWrongPostPassword    = errors.New("wrong post password")
+	ErrInvalidPeriod        = errors.New("period must be week, month or all")
+	ErrInvalidStatsRange    = errors.New("from must not be after to and the range must not exceed a year")
+	ErrPostAuthorRole       = errors.New("the role of the post author can't be changed")
+	ErrPinCategory          = errors.New("a post can only be pinned within its own category")
+	ErrPinExpired           = errors.New("expires_at must be in the future")
+	ErrUnknownFeed          = errors.New("feed must be posts.rss, posts.atom or posts.json")
+	ErrInvalidFeedContent   = errors.New("content must be full or excerpt")
+	ErrOEmbedFormat         = errors.New("only the json format is supported")
+	ErrInvalidOEmbedSize    = errors.New("maxwidth and maxheight must be positive integers")
+	ErrPostNotPublic        = errors.New("post is not public")
+	ErrUnsupportedLanguage  = errors.New("language is not supported")
+	ErrTranslationLanguage  = errors.New("a post and its translations must all be in different languages")
+	ErrEmptySlug            = errors.New("slug must have at least one letter or digit")
+	ErrCommentParentPost    = errors.New("a reply must be on the same post as its parent")
+	ErrCommentTooDeep       = errors.New("replies can't be nested this deep")
+	ErrCommentsClosed       = errors.New("comments are closed on this post")
+	ErrCommentNotApproved   = errors.New("only approved comments can be replied to")
+	ErrInvalidCommentStatus = errors.New("status must be pending, approved, rejected or spam")
+	ErrContentRejected      = errors.New("content was rejected")
+	ErrCommentEditWindow    = errors.New("the comment can no longer be edited")
+	ErrPostHiddenByReport   = errors.New("the visibility of a post hidden by a report can't be changed")
+	ErrInvalidLimit         = fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+	ErrInvalidPage          = errors.New("page must be at least 1")
 )
 
 const (
 	RegisterCodeKey   = "register_code_"
 	ForgotPasswordKey = "forgot_password_key_"
+	PostAccessKey     = "post_access_"
+	PostViewKey       = "post_view_"
+	RelatedPostsKey   = "related_posts_"
+	FeedKey           = "feed_"
+	SitemapKey        = "sitemap_"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	defaultStatsDays  = 30
+	maxStatsDays      = 366
+	defaultStatsLimit = 5
+
+	defaultListLimit = 10
+	maxListLimit     = 100
 )
 
 type handlerV1 struct {
 	cfg      *config.Config
 	Storage  storage.StorageI
 	inMemory storage.InMemoryStorageI
+	// contentFilters are keyed by the kind of content they check.
+	contentFilters map[string]*filter.Pipeline
 }
 
 type HandlerV1Options struct {
 	Cfg      *config.Config
 	Storage  *storage.StorageI
 	InMemory *storage.InMemoryStorageI
+	// Scorers plug extra spam and abuse scoring into the content filter.
+	Scorers []*filter.ScoreChecker
 }
 
 func New(options *HandlerV1Options) *handlerV1 {
 	return &handlerV1{
-		cfg:      options.Cfg,
-		Storage:  *options.Storage,
-		inMemory: *options.InMemory,
+		cfg:            options.Cfg,
+		Storage:        *options.Storage,
+		inMemory:       *options.InMemory,
+		contentFilters: newContentFilters(options.Cfg, *options.Storage, options.Scorers),
 	}
 }
 
@@ -50,53 +107,65 @@ func errResponse(err error) *models.ResponseError {
 	}
 }
 
-func validateGetAllParams(ctx *gin.Context) (*models.GetAllParams, error) {
-	var (
-		limit int64 = 10
-		page  int64 = 1
-		err   error
-	)
-	if ctx.Query("limit") != "" {
-		limit, err = strconv.ParseInt(ctx.Query("limit"), 10, 64)
-		if err != nil {
-			return nil, err
-		}
+// parseLimit reads the limit query parameter, rejecting one out of range.
+func parseLimit(ctx *gin.Context, defaultLimit int64) (int64, error) {
+	if ctx.Query("limit") == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.ParseInt(ctx.Query("limit"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if limit < 1 || limit > maxListLimit {
+		return 0, ErrInvalidLimit
 	}
+	return limit, nil
+}
 
+// parsePagination reads the limit and page query parameters of a list,
+// rejecting those out of range.
+func parsePagination(ctx *gin.Context) (limit, page int64, err error) {
+	limit, err = parseLimit(ctx, defaultListLimit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	page = 1
 	if ctx.Query("page") != "" {
 		page, err = strconv.ParseInt(ctx.Query("page"), 10, 64)
 		if err != nil {
-			return nil, err
+			return 0, 0, err
 		}
+		if page < 1 {
+			return 0, 0, ErrInvalidPage
+		}
+	}
+	return limit, page, nil
+}
+
+func validateGetAllParams(ctx *gin.Context) (*models.GetAllParams, error) {
+	limit, page, err := parsePagination(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	return &models.GetAllParams{
 		Limit:  limit,
 		Page:   page,
 		Search: ctx.Query("search"),
+		Sort:   ctx.Query("sort"),
+		After:  ctx.Query("after"),
+		Before: ctx.Query("before"),
 	}, nil
 }
 
 func validateGetAllPostsParams(ctx *gin.Context) (*models.GetAllPostsParams, error) {
-	var (
-		limit              int64 = 10
-		page               int64 = 1
-		err                error
-		userId, categoryId int64
-		sortByDate         string
-	)
-	if ctx.Query("limit") != "" {
-		limit, err = strconv.ParseInt(ctx.Query("limit"), 10, 64)
-		if err != nil {
-			return nil, err
-		}
-	}
+	var userId, categoryId int64
 
-	if ctx.Query("page") != "" {
-		page, err = strconv.ParseInt(ctx.Query("page"), 10, 64)
-		if err != nil {
-			return nil, err
-		}
+	limit, page, err := parsePagination(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	if ctx.Query("user_id") != "" {
@@ -112,60 +181,243 @@ func validateGetAllPostsParams(ctx *gin.Context) (*models.GetAllPostsParams, err
 			return nil, err
 		}
 	}
-	if ctx.Query("sort") != "" &&
-		(ctx.Query("sort") == "desc" || ctx.Query("sort") == "asc") {
-		sortByDate = ctx.Query("sort")
+
+	period := ctx.Query("period")
+	switch period {
+	case "", "week", "month", "all":
+	default:
+		return nil, ErrInvalidPeriod
+	}
+
+	var pinnedFirst bool
+	if ctx.Query("pinned_first") != "" {
+		pinnedFirst, err = strconv.ParseBool(ctx.Query("pinned_first"))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &models.GetAllPostsParams{
-		Limit:      limit,
-		Page:       page,
-		Search:     ctx.Query("search"),
-		UserID:     userId,
-		CategoryID: categoryId,
-		SortByDate: sortByDate,
+		Limit:       limit,
+		Page:        page,
+		Search:      ctx.Query("search"),
+		UserID:      userId,
+		CategoryID:  categoryId,
+		Sort:        ctx.Query("sort"),
+		Period:      period,
+		After:       ctx.Query("after"),
+		Before:      ctx.Query("before"),
+		PinnedFirst: pinnedFirst,
 	}, nil
 }
 
 func validateGetAllCommentsParams(ctx *gin.Context) (*models.GetAllCommentsParams, error) {
 	var (
-		limit          int64 = 10
-		page           int64 = 1
-		err            error
 		userId, postId int64
+		parentId       int64
+		threaded       bool
+		maxDepth       *int32
 	)
-	if ctx.Query("limit") != "" {
-		limit, err = strconv.ParseInt(ctx.Query("limit"), 10, 64)
+
+	limit, page, err := parsePagination(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Query("user_id") != "" {
+		userId, err = strconv.ParseInt(ctx.Query("user_id"), 10, 64)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if ctx.Query("page") != "" {
-		page, err = strconv.ParseInt(ctx.Query("page"), 10, 64)
+	if ctx.Query("post_id") != "" {
+		postId, err = strconv.ParseInt(ctx.Query("post_id"), 10, 64)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if ctx.Query("user_id") != "" {
-		userId, err = strconv.ParseInt(ctx.Query("user_id"), 10, 64)
+	if ctx.Query("parent_id") != "" {
+		parentId, err = strconv.ParseInt(ctx.Query("parent_id"), 10, 64)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	if ctx.Query("post_id") != "" {
-		postId, err = strconv.ParseInt(ctx.Query("post_id"), 10, 64)
+	if ctx.Query("threaded") != "" {
+		threaded, err = strconv.ParseBool(ctx.Query("threaded"))
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	if ctx.Query("max_depth") != "" {
+		depth, err := strconv.ParseInt(ctx.Query("max_depth"), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		d := int32(depth)
+		maxDepth = &d
+	}
+
 	return &models.GetAllCommentsParams{
-		Limit:  limit,
-		Page:   page,
-		UserID: userId,
-		PostID: postId,
+		Limit:    limit,
+		Page:     page,
+		UserID:   userId,
+		PostID:   postId,
+		Sort:     ctx.Query("sort"),
+		After:    ctx.Query("after"),
+		Before:   ctx.Query("before"),
+		Threaded: threaded,
+		ParentID: parentId,
+		MaxDepth: maxDepth,
+	}, nil
+}
+
+// validateStatsParams reads the from and to dates of a stats request,
+// defaulting to the last 30 days, and the number of top posts.
+func validateStatsParams(ctx *gin.Context) (*repo.GetStatsParams, error) {
+	limit, err := parseLimit(ctx, defaultStatsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, 1-defaultStatsDays)
+
+	if ctx.Query("to") != "" {
+		to, err = time.Parse(dateLayout, ctx.Query("to"))
+		if err != nil {
+			return nil, err
+		}
+		from = to.AddDate(0, 0, 1-defaultStatsDays)
+	}
+
+	if ctx.Query("from") != "" {
+		from, err = time.Parse(dateLayout, ctx.Query("from"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if from.After(to) || to.Sub(from) >= maxStatsDays*24*time.Hour {
+		return nil, ErrInvalidStatsRange
+	}
+
+	return &repo.GetStatsParams{
+		From:  from,
+		To:    to,
+		Limit: limit,
 	}, nil
 }
+
+func validateSearchParams(ctx *gin.Context) (*models.SearchParams, error) {
+	var (
+		types = strings.Join([]string{
+			repo.SearchTypePosts,
+			repo.SearchTypeUsers,
+			repo.SearchTypeCategories,
+		}, ",")
+	)
+
+	query := strings.TrimSpace(ctx.Query("q"))
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	limit, err := parseLimit(ctx, defaultListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Query("type") != "" {
+		types = ctx.Query("type")
+		for _, t := range strings.Split(types, ",") {
+			if t != repo.SearchTypePosts && t != repo.SearchTypeUsers && t != repo.SearchTypeCategories {
+				return nil, ErrUnknownSearchType
+			}
+		}
+	}
+
+	return &models.SearchParams{
+		Query: query,
+		Type:  types,
+		Limit: limit,
+	}, nil
+}
+
+// parseSortParam parses a sort query like "views_count:desc,created_at:asc".
+// The direction defaults to desc. A bare "asc" or "desc" sorts by
+// created_at, which is what older clients send.
+func parseSortParam(sort string) ([]*repo.SortField, error) {
+	var fields []*repo.SortField
+
+	if sort == "" {
+		return fields, nil
+	}
+
+	if sort == "asc" || sort == "desc" {
+		return []*repo.SortField{{Field: "created_at", Desc: sort == "desc"}}, nil
+	}
+
+	for _, key := range strings.Split(sort, ",") {
+		field, direction, _ := strings.Cut(strings.TrimSpace(key), ":")
+		if direction != "" && direction != "asc" && direction != "desc" {
+			return nil, ErrInvalidSortDirection
+		}
+
+		fields = append(fields, &repo.SortField{
+			Field: field,
+			Desc:  direction != "asc",
+		})
+	}
+
+	return fields, nil
+}
+
+// rankingSort points sort=top at the precomputed score of the period,
+// which defaults to the last week.
+func rankingSort(sort []*repo.SortField, period string) {
+	if period == "" {
+		period = "week"
+	}
+
+	for _, s := range sort {
+		if s.Field == "top" {
+			s.Field = "top_" + period
+		}
+	}
+}
+
+// decodeCursors decodes the after and before tokens of a listing. Tokens
+// are bound to the sort they were issued for.
+func decodeCursors(after, before, sort string) (*repo.Cursor, *repo.Cursor, error) {
+	if after != "" && before != "" {
+		return nil, nil, ErrAfterAndBefore
+	}
+
+	var cursors [2]*repo.Cursor
+	for i, token := range []string{after, before} {
+		if token == "" {
+			continue
+		}
+
+		values, err := utils.DecodeCursor(token, sort)
+		if err != nil {
+			return nil, nil, err
+		}
+		cursors[i] = &repo.Cursor{Values: values}
+	}
+
+	return cursors[0], cursors[1], nil
+}
+
+func encodeCursor(cursor *repo.Cursor, sort string) *string {
+	if cursor == nil {
+		return nil
+	}
+
+	token := utils.EncodeCursor(sort, cursor.Values)
+	return &token
+}
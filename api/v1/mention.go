@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	emailPkg "github.com/nurmuhammaddeveloper/blog_db/pkg/email"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/utils"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// setMentions stores the users mentioned in the text of the content
+// written by userID. Mentions are secondary to the content, so failing to
+// store them is only logged.
+func (h *handlerV1) setMentions(targetType string, targetID, userID int64, text string) []*repo.Mention {
+	mentions, err := h.Storage.Mention().Set(targetType, targetID, userID, utils.ParseMentions(text))
+	if err != nil {
+		log.Printf("failed to set the mentions of %s %d: %v", targetType, targetID, err)
+		return nil
+	}
+	return mentions
+}
+
+// notifyMentions emails the users mentioned in the content who weren't
+// told yet. Mentions in content that can't be seen wait until it can.
+func (h *handlerV1) notifyMentions(targetType string, targetIDs ...int64) {
+	mentions, err := h.Storage.Mention().ClaimNotifications(targetType, targetIDs)
+	if err != nil {
+		log.Printf("failed to notify the mentions of %s %v: %v", targetType, targetIDs, err)
+		return
+	}
+
+	for _, m := range mentions {
+		link := h.postURL(m.PostID)
+		if m.TargetType == repo.MentionTargetComment {
+			link += "#comment-" + strconv.FormatInt(m.TargetID, 10)
+		}
+
+		author := "Someone"
+		if m.MentionedBy != nil {
+			if u, err := h.Storage.User().Get(*m.MentionedBy); err == nil {
+				author = strings.TrimSpace(u.FirstName + " " + u.LastName)
+			}
+		}
+
+		user, err := h.Storage.User().Get(m.UserID)
+		if err != nil {
+			log.Printf("failed to notify user %d about a mention in %s %d: %v", m.UserID, m.TargetType, m.TargetID, err)
+			continue
+		}
+
+		err = emailPkg.SendEmail(h.cfg, &emailPkg.SendEmailRequest{
+			To:      []string{user.Email},
+			Subject: author + " mentioned you",
+			Body: map[string]string{
+				"author": author,
+				"target": m.TargetType,
+				"link":   link,
+			},
+			Type: emailPkg.MentionEmail,
+		})
+		if err != nil {
+			log.Printf("failed to notify user %d about a mention in %s %d: %v", m.UserID, m.TargetType, m.TargetID, err)
+		}
+	}
+}
+
+func parseMentions(mentions []*repo.Mention) []*models.Mention {
+	res := make([]*models.Mention, 0, len(mentions))
+	for _, m := range mentions {
+		res = append(res, &models.Mention{
+			UserID:   m.UserID,
+			Username: m.Username,
+		})
+	}
+	return res
+}
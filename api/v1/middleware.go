@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nurmuhammaddeveloper/blog_db/pkg/utils"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
 )
 
 func (h *handlerV1) AuthMiddleWare(ctx *gin.Context) {
@@ -28,6 +29,17 @@ func (h *handlerV1) AuthMiddleWare(ctx *gin.Context) {
 	ctx.Next()
 }
 
+// OptionalAuthMiddleWare sets the auth payload when a token is provided,
+// but lets anonymous requests through.
+func (h *handlerV1) OptionalAuthMiddleWare(ctx *gin.Context) {
+	if len(ctx.GetHeader(os.Getenv("AUTHORIZATION_HEADER_KEY"))) == 0 {
+		ctx.Next()
+		return
+	}
+
+	h.AuthMiddleWare(ctx)
+}
+
 func (h *handlerV1) GetAuthPayload(ctx *gin.Context) (*utils.Payload, error) {
 	i, exist := ctx.Get(os.Getenv("AUTHORIZATION_PAYLOAD_KEY"))
 	if !exist {
@@ -40,3 +52,21 @@ func (h *handlerV1) GetAuthPayload(ctx *gin.Context) (*utils.Payload, error) {
 	}
 	return payload, nil
 }
+
+// isOwnerOrAdmin reports whether the requester is the given user or an admin.
+func (h *handlerV1) isOwnerOrAdmin(ctx *gin.Context, userID int64) bool {
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		return false
+	}
+	return payload.UserID == userID || payload.UserType == repo.UserTypeSuperadmin
+}
+
+// isSuperadmin reports whether the requester is a superadmin.
+func (h *handlerV1) isSuperadmin(ctx *gin.Context) bool {
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		return false
+	}
+	return payload.UserType == repo.UserTypeSuperadmin
+}
@@ -1,18 +1,45 @@
 package v1
 
 import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/filter"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/markdown"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/utils"
+	"github.com/nurmuhammaddeveloper/blog_db/storage"
 	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
 )
 
+const (
+	// PostTokenHeader carries the token returned by UnlockPost.
+	PostTokenHeader = "X-Post-Token"
+
+	postAccessTokenDuration = 30 * time.Minute
+
+	// dailyStatsKeyDuration keeps the daily view counters around until the
+	// rollup of the next day has surely read them.
+	dailyStatsKeyDuration = 72 * time.Hour
+
+	maxRelatedPosts           = 20
+	relatedPostsCacheDuration = time.Hour
+)
+
 // @Security ApiKeyAuth
 // @Router /posts [post]
 // @Summary Create a post
-// @Description Create a post
+// @Description Create a post. When the content filter holds the post it is created private until an admin reviews it, and when the filter rejects it nothing is created. Users mentioned as @username in the description are notified once the post is public.
 // @Tags post
 // @Accept json
 // @Produce json
@@ -20,6 +47,7 @@ import (
 // @Success 201 {object} models.Post
 // @Failure 500 {object} models.ResponseError
 // @Failure 400 {object} models.ResponseError
+// @Failure 422 {object} models.ResponseError
 func (h *handlerV1) CreatePost(ctx *gin.Context) {
 	var (
 		req models.CreatePostRequest
@@ -32,41 +60,90 @@ func (h *handlerV1) CreatePost(ctx *gin.Context) {
 		return
 	}
 
-	post, err := h.Storage.Post().Create(&repo.Post{
-		Title:       req.Title,
-		Description: req.Description,
-		ImageUrl:    req.ImageUrl,
-		UserID:      req.UserID,
-		CategoryID:  req.CategoryID,
-	})
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	p := repo.Post{
+		Title:         req.Title,
+		Description:   req.Description,
+		ImageUrl:      req.ImageUrl,
+		Visibility:    req.Visibility,
+		Tags:          utils.NormalizeTags(req.Tags),
+		UserID:        payload.UserID,
+		CategoryID:    req.CategoryID,
+		Language:      req.Language,
+		CommentStatus: req.CommentStatus,
+	}
+
+	if p.Language == "" {
+		p.Language = h.cfg.Languages.Default
+	}
+	if !h.isSupportedLanguage(p.Language) {
+		ctx.JSON(http.StatusBadRequest, errResponse(ErrUnsupportedLanguage))
+		return
+	}
+
+	if p.Visibility == repo.PostVisibilityPassword && req.Password == nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(ErrPostPasswordRequired))
+		return
+	}
 
+	p.Password, err = hashPostPassword(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, models.Post{
-		ID:          post.ID,
-		Title:       post.Title,
-		Description: post.Description,
-		ImageUrl:    post.ImageUrl,
-		UserID:      post.UserID,
-		CategoryID:  post.CategoryID,
-		CreatedAt:   post.CreatedAt,
-	})
+	err = renderPostDescription(&p)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	content := postContent(&p, payload.UserID)
+	verdict, ok := h.filterContent(ctx, content)
+	if !ok {
+		return
+	}
+	if verdict != nil && verdict.Action == filter.Hold {
+		p.Visibility = repo.PostVisibilityPrivate
+	}
+
+	post, err := h.Storage.Post().Create(&p)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+	h.recordFilterOutcome(content, verdict, post.ID)
+	post.Mentions = h.setMentions(repo.MentionTargetPost, post.ID, payload.UserID, post.Description)
+	go h.notifyMentions(repo.MentionTargetPost, post.ID)
+	h.invalidatePostSitemaps(post)
+
+	ctx.JSON(http.StatusOK, parsePostModel(post))
 }
 
 // @Security ApiKeyAuth
 // @Router /posts/{id} [get]
 // @Summary Get a post with it's id
 // @Description Create a post with it's id
+// @Description Private posts are only visible to their author and admins. Password protected posts need the token returned by /posts/{id}/unlock.
 // @Tags post
 // @Accept json
 // @Produce json
 // @Param id path int true "ID"
+// @Param X-Post-Token header string false "Post unlock token"
+// @Param post_token query string false "Post unlock token"
+// @Param lang query string false "Language, before Accept-Language and the default language"
+// @Param Accept-Language header string false "Accept-Language"
 // @Success 201 {object} models.Post
 // @Failure 500 {object} models.ResponseError
 // @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
 func (h *handlerV1) GetPost(ctx *gin.Context) {
 	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
 	if err != nil {
@@ -77,10 +154,82 @@ func (h *handlerV1) GetPost(ctx *gin.Context) {
 	res, err := h.Storage.Post().Get(id)
 
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
 
+	h.servePost(ctx, res, nil)
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/slug/{lang}/{slug} [get]
+// @Summary Get a post by the slug of a translation
+// @Description Serves the post in the language of the translation with the slug. Visibility is checked as for GET /posts/{id}.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param lang path string true "Language"
+// @Param slug path string true "Slug"
+// @Param X-Post-Token header string false "Post unlock token"
+// @Param post_token query string false "Post unlock token"
+// @Success 200 {object} models.Post
+// @Failure 500 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetPostBySlug(ctx *gin.Context) {
+	t, err := h.Storage.Post().GetTranslationBySlug(ctx.Param("lang"), ctx.Param("slug"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	res, err := h.Storage.Post().Get(t.PostID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	h.servePost(ctx, res, t)
+}
+
+// servePost writes a post the user can see, in the translation t when it
+// is given and otherwise in the preferred language of the request.
+func (h *handlerV1) servePost(ctx *gin.Context, res *repo.Post, t *repo.PostTranslation) {
+	if !h.canBypassVisibility(ctx, res) {
+		switch res.Visibility {
+		case repo.PostVisibilityPrivate:
+			ctx.JSON(http.StatusNotFound, errResponse(sql.ErrNoRows))
+			return
+		case repo.PostVisibilityPassword:
+			if !h.isPostUnlocked(ctx, res.ID) {
+				ctx.JSON(http.StatusForbidden, errResponse(ErrPostLocked))
+				return
+			}
+		}
+	}
+
+	h.countView(ctx, res.ID)
+
+	if t != nil {
+		applyTranslation(res, t)
+	} else if err := h.localizePosts(ctx, []*repo.Post{res}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+	ctx.Header("Content-Language", res.Language)
+
 	post := parsePostModel(res)
 
 	likesInfo, err := h.Storage.Like().GetLikesDislikesCount(post.ID)
@@ -93,6 +242,16 @@ func (h *handlerV1) GetPost(ctx *gin.Context) {
 		LikesCount:    likesInfo.Likes,
 		DislikesCount: likesInfo.Dislikes,
 	}
+
+	series, err := h.Storage.Series().GetByPost(post.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+	if series != nil {
+		post.Series = h.parsePostSeries(ctx, series, post.ID)
+	}
+
 	ctx.JSON(http.StatusOK, post)
 }
 
@@ -104,10 +263,17 @@ func (h *handlerV1) GetPost(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ID"
+// @Description Only the author, co-authors, editors and admins can update a post.
+// @Description When the content filter holds the post it is made private, keeping its password, until an admin reviews it, and when the filter rejects it the post isn't changed.
+// @Description The visibility of a post hidden by a report can't be changed until a moderator dismisses the report.
 // @Param post body models.UpdatePostRequest true "Post"
 // @Success 201 {object} models.Post
 // @Failure 500 {object} models.ResponseError
 // @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+// @Failure 409 {object} models.ResponseError
+// @Failure 422 {object} models.ResponseError
 func (h *handlerV1) UpdatePost(ctx *gin.Context) {
 	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
 	if err != nil {
@@ -126,32 +292,110 @@ func (h *handlerV1) UpdatePost(ctx *gin.Context) {
 		return
 	}
 
-	post, err := h.Storage.Post().Update(&repo.Post{
-		ID:          id,
-		Title:       req.Title,
-		Description: req.Description,
-		ImageUrl:    req.ImageUrl,
-		UserID:      req.UserID,
-		CategoryID:  req.CategoryID,
-		ViewsCount:  req.ViewsCount,
-	})
+	old, err := h.Storage.Post().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if !h.canEditPost(ctx, old) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	if req.Visibility != "" && req.Visibility != old.Visibility {
+		hidden, err := h.Storage.Report().IsHidden(repo.ReportTargetPost, id)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errResponse(err))
+			return
+		}
+		if hidden {
+			ctx.JSON(http.StatusConflict, errResponse(ErrPostHiddenByReport))
+			return
+		}
+	}
+
+	p := repo.Post{
+		ID:            id,
+		Title:         req.Title,
+		Description:   req.Description,
+		ImageUrl:      req.ImageUrl,
+		Visibility:    req.Visibility,
+		Tags:          utils.NormalizeTags(req.Tags),
+		UserID:        old.UserID,
+		CategoryID:    req.CategoryID,
+		Language:      req.Language,
+		CommentStatus: req.CommentStatus,
+	}
+
+	if p.Language != "" {
+		if !h.isSupportedLanguage(p.Language) {
+			ctx.JSON(http.StatusBadRequest, errResponse(ErrUnsupportedLanguage))
+			return
+		}
+		for _, lang := range old.Translations {
+			if lang == p.Language {
+				ctx.JSON(http.StatusBadRequest, errResponse(ErrTranslationLanguage))
+				return
+			}
+		}
+	}
 
+	// the current password is kept, so there has to be one
+	if p.Visibility == repo.PostVisibilityPassword && req.Password == nil && old.Password == nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(ErrPostPasswordRequired))
+		return
+	}
+
+	p.Password, err = hashPostPassword(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, models.Post{
-		ID:          post.ID,
-		Title:       post.Title,
-		Description: post.Description,
-		ImageUrl:    post.ImageUrl,
-		UserID:      post.UserID,
-		CategoryID:  post.CategoryID,
-		ViewsCount:  post.ViewsCount,
-		CreatedAt:   post.CreatedAt,
-		UpdatedAt:   post.UpdatedAt,
-	})
+	err = renderPostDescription(&p)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	content := postContent(&p, payload.UserID)
+	verdict, ok := h.filterContent(ctx, content)
+	if !ok {
+		return
+	}
+	if verdict != nil && verdict.Action == filter.Hold {
+		p.Visibility = repo.PostVisibilityPrivate
+	}
+
+	post, err := h.Storage.Post().Update(&p)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+	h.recordFilterOutcome(content, verdict, post.ID)
+	post.Mentions = h.setMentions(repo.MentionTargetPost, post.ID, payload.UserID, post.Description)
+	go h.notifyMentions(repo.MentionTargetPost, post.ID)
+	h.invalidateRelatedPosts(post.ID)
+	h.invalidatePostSitemaps(old)
+	h.invalidatePostSitemaps(post)
+
+	ctx.JSON(http.StatusOK, parsePostModel(post))
 }
 
 // @Security ApiKeyAuth
@@ -165,6 +409,8 @@ func (h *handlerV1) UpdatePost(ctx *gin.Context) {
 // @Success 201 {object} models.ResponseSuccess
 // @Failure 500 {object} models.ResponseError
 // @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
 func (h *handlerV1) DeletePost(ctx *gin.Context) {
 	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
 	if err != nil {
@@ -172,12 +418,23 @@ func (h *handlerV1) DeletePost(ctx *gin.Context) {
 		return
 	}
 
+	post, ok := h.getOwnPost(ctx, id)
+	if !ok {
+		return
+	}
+
 	err = h.Storage.Post().Delete(id)
 
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
+	h.invalidateRelatedPosts(id)
+	h.invalidatePostSitemaps(post)
 
 	ctx.JSON(http.StatusOK, models.ResponseSuccess{
 		Success: "Successfully deleted!",
@@ -187,10 +444,16 @@ func (h *handlerV1) DeletePost(ctx *gin.Context) {
 // @Router /posts [get]
 // @Summary Get posts by giving limit, page and search for something.
 // @Description Get posts by giving limit, page and search for something.
+// @Description sort accepts a comma separated list of created_at, updated_at, title, views_count, likes_count and comments_count, each optionally followed by :asc or :desc.
+// @Description sort=trending ranks posts by their activity decayed by age, and sort=top by their activity in the given period. Both are recomputed every few minutes.
+// @Description pinned_first=true lists the pinned posts first, in their pin order.
+// @Description Posts are served in the first available language of lang, then Accept-Language, then the default language.
 // @Tags post
 // @Accept json
 // @Produce json
 // @Param filter query models.GetAllPostsParams false "Filter"
+// @Param lang query string false "Language"
+// @Param Accept-Language header string false "Accept-Language"
 // @Success 201 {object} models.GetAllPostsResponse
 // @Failure 500 {object} models.ResponseError
 // @Failure 400 {object} models.ResponseError
@@ -201,26 +464,524 @@ func (h *handlerV1) GetAllPosts(c *gin.Context) {
 		return
 	}
 
-	result, err := h.Storage.Post().GetAll(&repo.GetPostsParams{
-		Limit:      params.Limit,
-		Page:       params.Page,
-		Search:     params.Search,
-		UserID:     params.UserID,
-		CategoryID: params.CategoryID,
-		SortByDate: params.SortByDate,
-	})
+	sort, err := parseSortParam(params.Sort)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+	rankingSort(sort, params.Period)
+
+	// the same sort means different rankings for different periods
+	cursorSort := params.Sort
+	if params.Period != "" {
+		cursorSort += "@" + params.Period
+	}
+	if params.PinnedFirst {
+		cursorSort += "+pinned"
+	}
+
+	after, before, err := decodeCursors(params.After, params.Before, cursorSort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	filter := repo.GetPostsParams{
+		Limit:       params.Limit,
+		Page:        params.Page,
+		Search:      params.Search,
+		UserID:      params.UserID,
+		CategoryID:  params.CategoryID,
+		Sort:        sort,
+		After:       after,
+		Before:      before,
+		PinnedFirst: params.PinnedFirst,
+	}
+	if payload, err := h.GetAuthPayload(c); err == nil {
+		filter.ViewerID = payload.UserID
+		filter.IsSuperadmin = payload.UserType == repo.UserTypeSuperadmin
+	}
+
+	result, err := h.Storage.Post().GetAll(&filter)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidSortField) || errors.Is(err, repo.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, getPostsResponse(result))
+	err = h.localizePosts(c, result.Posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	for _, post := range result.Posts {
+		if post.Visibility == repo.PostVisibilityPassword && !h.canBypassVisibility(c, post) {
+			lockPost(post)
+		}
+	}
+
+	c.JSON(http.StatusOK, getPostsResponse(result, cursorSort))
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/trash [get]
+// @Summary Get deleted posts
+// @Description Get deleted posts. Admins see the whole trash, other users only their own posts.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param filter query models.GetAllParams false "Filter"
+// @Success 200 {object} models.GetAllPostsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+func (h *handlerV1) GetPostsTrash(c *gin.Context) {
+	params, err := validateGetAllParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	sort, err := parseSortParam(params.Sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	after, before, err := decodeCursors(params.After, params.Before, params.Sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	filter := repo.GetPostsParams{
+		Limit:        params.Limit,
+		Page:         params.Page,
+		Search:       params.Search,
+		Sort:         sort,
+		After:        after,
+		Before:       before,
+		ViewerID:     payload.UserID,
+		IsSuperadmin: payload.UserType == repo.UserTypeSuperadmin,
+		Deleted:      true,
+	}
+	if !filter.IsSuperadmin {
+		filter.UserID = payload.UserID
+	}
+
+	result, err := h.Storage.Post().GetAll(&filter)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidSortField) || errors.Is(err, repo.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, getPostsResponse(result, params.Sort))
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/restore [post]
+// @Summary Restore a deleted post
+// @Description Restore a deleted post. Users can only restore their own posts.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ResponseSuccess
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) RestorePost(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	var userID int64
+	if payload.UserType != repo.UserTypeSuperadmin {
+		userID = payload.UserID
+	}
+
+	err = h.Storage.Post().Restore(id, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+	h.invalidateRelatedPosts(id)
+	if post, err := h.Storage.Post().Get(id); err == nil {
+		h.invalidatePostSitemaps(post)
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Success: "Successfully restored!",
+	})
+}
+
+// @Router /posts/{id}/related [get]
+// @Summary Get posts related to a post
+// @Description Ranks other public posts by shared category and tags, title similarity and users who liked both posts.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param limit query int false "Limit" default(5)
+// @Success 200 {object} models.RelatedPostsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetRelatedPosts(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	var limit int64 = 5
+	if ctx.Query("limit") != "" {
+		limit, err = strconv.ParseInt(ctx.Query("limit"), 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
+	}
+	if limit < 1 || limit > maxRelatedPosts {
+		limit = maxRelatedPosts
+	}
+
+	post, err := h.Storage.Post().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if post.Visibility == repo.PostVisibilityPrivate && !h.canBypassVisibility(ctx, post) {
+		ctx.JSON(http.StatusNotFound, errResponse(sql.ErrNoRows))
+		return
+	}
+
+	related, err := h.getRelatedPosts(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if int64(len(related)) > limit {
+		related = related[:limit]
+	}
+
+	ctx.JSON(http.StatusOK, models.RelatedPostsResponse{
+		Posts: related,
+	})
+}
+
+// getRelatedPosts returns the related posts of a post from the cache, or
+// computes and caches them. The cache always holds maxRelatedPosts posts.
+// Cached posts that were since deleted or stopped being public are left
+// out, and the cache is dropped so the next request fills their places.
+func (h *handlerV1) getRelatedPosts(id int64) ([]*models.RelatedPost, error) {
+	key := RelatedPostsKey + strconv.FormatInt(id, 10)
+
+	var related []*models.RelatedPost
+	if cached, err := h.inMemory.Get(key); err == nil && json.Unmarshal([]byte(cached), &related) == nil {
+		return h.publicRelatedPosts(id, related)
+	}
+
+	posts, err := h.Storage.Post().GetRelated(id, maxRelatedPosts)
+	if err != nil {
+		return nil, err
+	}
+
+	related = make([]*models.RelatedPost, 0, len(posts))
+	for _, p := range posts {
+		related = append(related, &models.RelatedPost{
+			ID:         p.ID,
+			Title:      p.Title,
+			ImageUrl:   p.ImageUrl,
+			UserID:     p.UserID,
+			CategoryID: p.CategoryID,
+			CreatedAt:  p.CreatedAt,
+			Tags:       p.Tags,
+			Score:      p.Score,
+		})
+	}
+
+	data, err := json.Marshal(related)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.inMemory.Set(key, string(data), relatedPostsCacheDuration); err != nil {
+		log.Printf("failed to cache related posts of post %d: %v", id, err)
+	}
+
+	return related, nil
+}
+
+func (h *handlerV1) publicRelatedPosts(id int64, related []*models.RelatedPost) ([]*models.RelatedPost, error) {
+	ids := make([]int64, 0, len(related))
+	for _, p := range related {
+		ids = append(ids, p.ID)
+	}
+
+	public, err := h.Storage.Post().GetPublicIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(public) == len(related) {
+		return related, nil
+	}
+
+	isPublic := make(map[int64]bool, len(public))
+	for _, pid := range public {
+		isPublic[pid] = true
+	}
+
+	res := make([]*models.RelatedPost, 0, len(public))
+	for _, p := range related {
+		if isPublic[p.ID] {
+			res = append(res, p)
+		}
+	}
+	h.invalidateRelatedPosts(id)
+
+	return res, nil
+}
+
+func (h *handlerV1) invalidateRelatedPosts(id int64) {
+	if err := h.inMemory.Del(RelatedPostsKey + strconv.FormatInt(id, 10)); err != nil {
+		log.Printf("failed to invalidate related posts of post %d: %v", id, err)
+	}
+}
+
+// @Router /posts/{id}/unlock [post]
+// @Summary Unlock a password protected post
+// @Description Checks the password of a post and returns a short-lived token to pass to GET /posts/{id} as the X-Post-Token header or the post_token query parameter.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param data body models.UnlockPostRequest true "Data"
+// @Success 200 {object} models.UnlockPostResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) UnlockPost(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	var req models.UnlockPostRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	post, err := h.Storage.Post().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if post.Visibility != repo.PostVisibilityPassword || post.Password == nil {
+		ctx.JSON(http.StatusNotFound, errResponse(sql.ErrNoRows))
+		return
+	}
+
+	if err := utils.CheckPassword(req.Password, *post.Password); err != nil {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrWrongPostPassword))
+		return
+	}
+
+	token := uuid.NewString()
+	err = h.inMemory.Set(PostAccessKey+token, strconv.FormatInt(post.ID, 10), postAccessTokenDuration)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.UnlockPostResponse{
+		Token:     token,
+		ExpiresAt: time.Now().Add(postAccessTokenDuration),
+	})
 }
 
-func getPostsResponse(data *repo.GetAllPostResult) *models.GetAllPostsResponse {
+// countView buffers a view of the post unless it comes from a bot or the
+// same viewer already viewed it within the configured window. The buffer is
+// flushed to Postgres by the worker. Failures only cost a view, so they are
+// logged instead of failing the request.
+func (h *handlerV1) countView(ctx *gin.Context, postID int64) {
+	if utils.IsBot(ctx.Request.UserAgent()) {
+		return
+	}
+
+	var viewer string
+	if payload, err := h.GetAuthPayload(ctx); err == nil {
+		viewer = "user_" + strconv.FormatInt(payload.UserID, 10)
+	} else {
+		sum := sha256.Sum256([]byte(ctx.ClientIP() + "|" + ctx.Request.UserAgent()))
+		viewer = hex.EncodeToString(sum[:])
+	}
+
+	var (
+		id      = strconv.FormatInt(postID, 10)
+		day     = time.Now().UTC().Format("2006-01-02")
+		viewers = storage.PostDailyViewersKey + day + "_" + id
+	)
+
+	err := h.inMemory.PFAdd(viewers, viewer)
+	if err == nil {
+		err = h.inMemory.Expire(viewers, dailyStatsKeyDuration)
+	}
+	if err != nil {
+		log.Printf("failed to count viewer of post %d: %v", postID, err)
+	}
+
+	first, err := h.inMemory.SetNX(PostViewKey+id+"_"+viewer, "1", h.cfg.Views.Window)
+	if err != nil {
+		log.Printf("failed to count view of post %d: %v", postID, err)
+		return
+	}
+	if !first {
+		return
+	}
+
+	err = h.inMemory.HIncrBy(storage.PostViewsKey, id, 1)
+	if err == nil {
+		err = h.inMemory.HIncrBy(storage.PostDailyViewsKey+day, id, 1)
+	}
+	if err == nil {
+		err = h.inMemory.Expire(storage.PostDailyViewsKey+day, dailyStatsKeyDuration)
+	}
+	if err != nil {
+		log.Printf("failed to count view of post %d: %v", postID, err)
+	}
+}
+
+// getOwnPost loads a post the requester is the author of, or responds with
+// the matching error.
+func (h *handlerV1) getOwnPost(ctx *gin.Context, id int64) (*repo.Post, bool) {
+	post, err := h.Storage.Post().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return nil, false
+	}
+
+	if !h.isOwnerOrAdmin(ctx, post.UserID) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return nil, false
+	}
+
+	return post, true
+}
+
+// canBypassVisibility reports whether the requester is a contributor of the
+// post or an admin, who can always see it.
+func (h *handlerV1) canBypassVisibility(ctx *gin.Context, post *repo.Post) bool {
+	return h.canEditPost(ctx, post)
+}
+
+// canEditPost reports whether the requester is one of the contributors of
+// the post or an admin.
+func (h *handlerV1) canEditPost(ctx *gin.Context, post *repo.Post) bool {
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		return false
+	}
+	if payload.UserType == repo.UserTypeSuperadmin {
+		return true
+	}
+
+	for _, c := range post.Contributors {
+		if c.UserID == payload.UserID {
+			return true
+		}
+	}
+	return false
+}
+
+// isPostUnlocked reports whether the request carries a token returned by
+// UnlockPost for the post.
+func (h *handlerV1) isPostUnlocked(ctx *gin.Context, postID int64) bool {
+	token := ctx.GetHeader(PostTokenHeader)
+	if token == "" {
+		token = ctx.Query("post_token")
+	}
+	if token == "" {
+		return false
+	}
+
+	id, err := h.inMemory.Get(PostAccessKey + token)
+	if err != nil {
+		return false
+	}
+	return id == strconv.FormatInt(postID, 10)
+}
+
+// lockPost hides the content of a password protected post.
+func lockPost(post *repo.Post) {
+	post.Description = ""
+	post.DescriptionHtml = ""
+	post.TableOfContents = nil
+	post.ReadingTime = 0
+	post.Headline = nil
+}
+
+func hashPostPassword(password *string) (*string, error) {
+	if password == nil {
+		return nil, nil
+	}
+
+	hashed, err := utils.HashPassword(*password)
+	if err != nil {
+		return nil, err
+	}
+	return &hashed, nil
+}
+
+func getPostsResponse(data *repo.GetAllPostResult, sort string) *models.GetAllPostsResponse {
 	response := models.GetAllPostsResponse{
-		Posts: make([]*models.Post, 0),
-		Count: data.Count,
+		Posts:      make([]*models.Post, 0),
+		Count:      data.Count,
+		NextCursor: encodeCursor(data.NextCursor, sort),
+		PrevCursor: encodeCursor(data.PrevCursor, sort),
 	}
 
 	for _, post := range data.Posts {
@@ -231,16 +992,93 @@ func getPostsResponse(data *repo.GetAllPostResult) *models.GetAllPostsResponse {
 	return &response
 }
 
+// renderPostDescription renders the Markdown description of a post to
+// sanitized HTML and fills in the fields derived from it.
+func renderPostDescription(post *repo.Post) error {
+	result, err := markdown.Render(post.Description)
+	if err != nil {
+		return err
+	}
+
+	post.DescriptionHtml = result.HTML
+	post.ReadingTime = int32(result.ReadingTime)
+	post.TableOfContents = parseHeadings(result.TableOfContents)
+
+	return nil
+}
+
+func parseHeadings(headings []*markdown.Heading) []*repo.PostHeading {
+	res := make([]*repo.PostHeading, 0, len(headings))
+	for _, h := range headings {
+		res = append(res, &repo.PostHeading{
+			Level: h.Level,
+			ID:    h.ID,
+			Text:  h.Text,
+		})
+	}
+	return res
+}
+
+// postContent is what the content filter checks of a post written by the
+// user.
+func postContent(p *repo.Post, userID int64) *filter.Content {
+	return &filter.Content{
+		Kind:   filter.KindPost,
+		ID:     p.ID,
+		Text:   p.Title + "\n\n" + p.Description,
+		UserID: userID,
+	}
+}
+
 func parsePostModel(post *repo.Post) models.Post {
-	return models.Post{
-		ID:          post.ID,
-		Title:       post.Title,
-		Description: post.Title,
-		ImageUrl:    post.ImageUrl,
-		ViewsCount:  post.ViewsCount,
-		UserID:      post.UserID,
-		CategoryID:  post.CategoryID,
-		CreatedAt:   post.CreatedAt,
-		UpdatedAt:   post.UpdatedAt,
+	p := models.Post{
+		ID:              post.ID,
+		Title:           post.Title,
+		Description:     post.Description,
+		DescriptionHtml: post.DescriptionHtml,
+		TableOfContents: make([]*models.PostHeading, 0, len(post.TableOfContents)),
+		ReadingTime:     post.ReadingTime,
+		ImageUrl:        post.ImageUrl,
+		Visibility:      post.Visibility,
+		Tags:            post.Tags,
+		ViewsCount:      post.ViewsCount,
+		UserID:          post.UserID,
+		CategoryID:      post.CategoryID,
+		CreatedAt:       post.CreatedAt,
+		UpdatedAt:       post.UpdatedAt,
+		DeletedAt:       post.DeletedAt,
+		Contributors:    make([]*models.PostContributor, 0, len(post.Contributors)),
+		Language:        post.Language,
+		Languages:       append([]string{post.Language}, post.Translations...),
+		Slug:            post.Slug,
+		CommentStatus:   post.CommentStatus,
+		Mentions:        parseMentions(post.Mentions),
+	}
+	sort.Strings(p.Languages)
+
+	for _, h := range post.TableOfContents {
+		p.TableOfContents = append(p.TableOfContents, &models.PostHeading{
+			Level: h.Level,
+			ID:    h.ID,
+			Text:  h.Text,
+		})
 	}
+
+	for _, c := range post.Contributors {
+		p.Contributors = append(p.Contributors, &models.PostContributor{
+			UserID:          c.UserID,
+			Role:            c.Role,
+			FirstName:       c.FirstName,
+			LastName:        c.LastName,
+			Username:        c.Username,
+			ProfileImageUrl: c.ProfileImageUrl,
+		})
+	}
+
+	if post.Headline != nil {
+		p.SearchRank = &post.SearchRank
+		p.Highlight = post.Headline
+	}
+
+	return p
 }
@@ -0,0 +1,331 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	emailPkg "github.com/nurmuhammaddeveloper/blog_db/pkg/email"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// @Security ApiKeyAuth
+// @Router /reports [post]
+// @Summary Report a post, a comment or a user
+// @Description Flag content with a reason. Reports of the same content are merged, and reporting it again replaces your earlier reason. The content is hidden once enough users reported it, until a moderator looks at it.
+// @Tags report
+// @Accept json
+// @Produce json
+// @Param data body models.CreateReportRequest true "Data"
+// @Success 201 {object} models.Report
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) CreateReport(ctx *gin.Context) {
+	var (
+		req models.CreateReportRequest
+	)
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	var post *repo.Post
+	switch req.TargetType {
+	case repo.ReportTargetPost:
+		post, err = h.Storage.Post().Get(req.TargetID)
+		if err == nil && post.Visibility == repo.PostVisibilityPrivate && !h.canBypassVisibility(ctx, post) {
+			err = sql.ErrNoRows
+		}
+	case repo.ReportTargetComment:
+		_, err = h.Storage.Comment().Get(req.TargetID)
+	case repo.ReportTargetUser:
+		_, err = h.Storage.User().Get(req.TargetID)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	report, err := h.Storage.Report().Flag(req.TargetType, req.TargetID, &repo.ReportFlag{
+		UserID:  payload.UserID,
+		Reason:  req.Reason,
+		Details: req.Details,
+	}, h.cfg.Reports.HideThreshold)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if post != nil && report.Hidden {
+		h.invalidatePostSitemaps(post)
+	}
+
+	ctx.JSON(http.StatusCreated, parseReportModel(report))
+}
+
+// @Security ApiKeyAuth
+// @Router /reports [get]
+// @Summary Get the reports queue
+// @Description Get the reports, the open ones by default, the most reported content first. Only moderators can see them.
+// @Tags report
+// @Accept json
+// @Produce json
+// @Param filter query models.GetReportsParams false "Filter"
+// @Success 200 {object} models.GetReportsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+func (h *handlerV1) GetReports(ctx *gin.Context) {
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	params, err := validateGetReportsParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	result, err := h.Storage.Report().GetAll(params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	res := models.GetReportsResponse{
+		Reports: make([]*models.Report, 0, len(result.Reports)),
+		Count:   result.Count,
+	}
+	for _, r := range result.Reports {
+		res.Reports = append(res.Reports, parseReportModel(r))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// @Security ApiKeyAuth
+// @Router /reports/{id} [get]
+// @Summary Get a report with its flags
+// @Description Only moderators can see reports.
+// @Tags report
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.Report
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetReport(ctx *gin.Context) {
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	report, err := h.Storage.Report().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, parseReportModel(report))
+}
+
+// @Security ApiKeyAuth
+// @Router /reports/{id}/resolve [post]
+// @Summary Resolve a report
+// @Description Uphold an open report, hiding the content if it isn't yet. The users who reported it are notified. Only moderators can resolve reports.
+// @Tags report
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param data body models.CloseReportRequest true "Data"
+// @Success 200 {object} models.Report
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) ResolveReport(ctx *gin.Context) {
+	h.closeReport(ctx, repo.ReportStatusResolved)
+}
+
+// @Security ApiKeyAuth
+// @Router /reports/{id}/dismiss [post]
+// @Summary Dismiss a report
+// @Description Dismiss an open report, showing the content again if the report hid it. The users who reported it are notified. Only moderators can dismiss reports.
+// @Tags report
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param data body models.CloseReportRequest true "Data"
+// @Success 200 {object} models.Report
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) DismissReport(ctx *gin.Context) {
+	h.closeReport(ctx, repo.ReportStatusDismissed)
+}
+
+func (h *handlerV1) closeReport(ctx *gin.Context, status string) {
+	var (
+		req models.CloseReportRequest
+	)
+
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	report, err := h.Storage.Report().Close(id, status, payload.UserID, req.Resolution)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if report.TargetType == repo.ReportTargetPost {
+		if post, err := h.Storage.Post().Get(report.TargetID); err == nil {
+			h.invalidatePostSitemaps(post)
+		}
+	}
+
+	go h.notifyReporters(report)
+
+	ctx.JSON(http.StatusOK, parseReportModel(report))
+}
+
+// notifyReporters emails the users who flagged the content of a closed
+// report about the outcome.
+func (h *handlerV1) notifyReporters(report *repo.Report) {
+	outcome := "found it breaks the rules, so it was taken down"
+	if report.Status == repo.ReportStatusDismissed {
+		outcome = "found it doesn't break the rules"
+	}
+
+	body := map[string]string{
+		"target":  report.TargetType,
+		"outcome": outcome,
+	}
+	if report.Resolution != nil {
+		body["resolution"] = *report.Resolution
+	}
+
+	for _, flag := range report.Flags {
+		user, err := h.Storage.User().Get(flag.UserID)
+		if err != nil {
+			log.Printf("failed to notify user %d about report %d: %v", flag.UserID, report.ID, err)
+			continue
+		}
+
+		err = emailPkg.SendEmail(h.cfg, &emailPkg.SendEmailRequest{
+			To:      []string{user.Email},
+			Subject: "Your report was reviewed",
+			Body:    body,
+			Type:    emailPkg.ReportOutcomeEmail,
+		})
+		if err != nil {
+			log.Printf("failed to notify user %d about report %d: %v", flag.UserID, report.ID, err)
+		}
+	}
+}
+
+func validateGetReportsParams(ctx *gin.Context) (*repo.GetReportsParams, error) {
+	var targetId int64
+
+	limit, page, err := parsePagination(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Query("target_id") != "" {
+		targetId, err = strconv.ParseInt(ctx.Query("target_id"), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &repo.GetReportsParams{
+		Limit:      limit,
+		Page:       page,
+		Status:     ctx.DefaultQuery("status", repo.ReportStatusOpen),
+		TargetType: ctx.Query("target_type"),
+		TargetID:   targetId,
+	}, nil
+}
+
+func parseReportModel(report *repo.Report) *models.Report {
+	res := models.Report{
+		ID:         report.ID,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		Status:     report.Status,
+		FlagsCount: report.FlagsCount,
+		Hidden:     report.Hidden,
+		Resolution: report.Resolution,
+		ResolvedBy: report.ResolvedBy,
+		ResolvedAt: report.ResolvedAt,
+		CreatedAt:  report.CreatedAt,
+		UpdatedAt:  report.UpdatedAt,
+	}
+
+	for _, f := range report.Flags {
+		res.Flags = append(res.Flags, &models.ReportFlag{
+			UserID:    f.UserID,
+			Reason:    f.Reason,
+			Details:   f.Details,
+			CreatedAt: f.CreatedAt,
+		})
+	}
+
+	return &res
+}
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// @Router /search [get]
+// @Summary Full-text search over posts, users and categories
+// @Description Search supports "quoted phrases" and prefix* queries. Results are ranked and highlighted.
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param filter query models.SearchParams false "Filter"
+// @Success 200 {object} models.SearchResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+func (h *handlerV1) Search(c *gin.Context) {
+	params, err := validateSearchParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	result, err := h.Storage.Search().Search(&repo.SearchParams{
+		Query: params.Query,
+		Types: strings.Split(params.Type, ","),
+		Limit: params.Limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, getSearchResponse(result))
+}
+
+func getSearchResponse(data *repo.SearchResult) *models.SearchResponse {
+	response := models.SearchResponse{
+		Posts:           make([]*models.SearchPost, 0),
+		PostsCount:      data.PostsCount,
+		Users:           make([]*models.SearchUser, 0),
+		UsersCount:      data.UsersCount,
+		Categories:      make([]*models.SearchCategory, 0),
+		CategoriesCount: data.CategoriesCount,
+	}
+
+	for _, p := range data.Posts {
+		response.Posts = append(response.Posts, &models.SearchPost{
+			ID:                   p.ID,
+			Title:                p.Title,
+			TitleHighlight:       p.TitleHighlight,
+			DescriptionHighlight: p.DescriptionHighlight,
+			ImageUrl:             p.ImageUrl,
+			UserID:               p.UserID,
+			CategoryID:           p.CategoryID,
+			CreatedAt:            p.CreatedAt,
+			Rank:                 p.Rank,
+		})
+	}
+
+	for _, u := range data.Users {
+		response.Users = append(response.Users, &models.SearchUser{
+			ID:              u.ID,
+			FirstName:       u.FirstName,
+			LastName:        u.LastName,
+			UserName:        u.UserName,
+			ProfileImageUrl: u.ProfileImageUrl,
+			Highlight:       u.Highlight,
+			Rank:            u.Rank,
+		})
+	}
+
+	for _, c := range data.Categories {
+		response.Categories = append(response.Categories, &models.SearchCategory{
+			ID:        c.ID,
+			Title:     c.Title,
+			Highlight: c.Highlight,
+			Rank:      c.Rank,
+		})
+	}
+
+	return &response
+}
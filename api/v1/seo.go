@@ -0,0 +1,385 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/feed"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/seo"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+const (
+	metaDescriptionSize = 160
+
+	oEmbedWidth    = 600
+	oEmbedHeight   = 400
+	oEmbedCacheAge = 3600
+)
+
+var postPathRegexp = regexp.MustCompile(`^/posts/(\d+)/?$`)
+
+// @Router /posts/{id}/seo [get]
+// @Summary Get the SEO metadata of a post
+// @Description Get the metadata used when a post is shared. Fields without a custom value are derived from the post.
+// @Tags seo
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.PostSEO
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetPostSEO(ctx *gin.Context) {
+	post, ok := h.getVisiblePost(ctx)
+	if !ok {
+		return
+	}
+
+	s, err := h.Storage.Post().GetSEO(post.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, h.parsePostSEO(ctx, post, s))
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/seo [put]
+// @Summary Set the SEO metadata of a post
+// @Description Replace the custom metadata of a post. Null or empty fields are derived from the post. Only the author, co-authors, editors and admins can set it.
+// @Tags seo
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param data body models.SetPostSEORequest true "Data"
+// @Success 200 {object} models.PostSEO
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) SetPostSEO(ctx *gin.Context) {
+	var (
+		req models.SetPostSEORequest
+	)
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	post, err := h.Storage.Post().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if !h.canEditPost(ctx, post) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	s, err := h.Storage.Post().SetSEO(&repo.PostSEO{
+		PostID:          id,
+		MetaTitle:       nilIfEmpty(req.MetaTitle),
+		MetaDescription: nilIfEmpty(req.MetaDescription),
+		CanonicalUrl:    nilIfEmpty(req.CanonicalUrl),
+		ImageUrl:        nilIfEmpty(req.ImageUrl),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, h.parsePostSEO(ctx, post, s))
+}
+
+// @Router /posts/{id}/head [get]
+// @Summary Get the HTML head of a post
+// @Description Get the title, meta and link tags of a post page, Open Graph and Twitter card tags included, for server side rendered frontends.
+// @Tags seo
+// @Produce html
+// @Param id path int true "ID"
+// @Success 200 {string} string
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetPostHead(ctx *gin.Context) {
+	post, ok := h.getVisiblePost(ctx)
+	if !ok {
+		return
+	}
+
+	s, err := h.Storage.Post().GetSEO(post.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	meta := h.parsePostSEO(ctx, post, s)
+	m := seo.Meta{
+		Title:        meta.MetaTitle,
+		Description:  meta.MetaDescription,
+		CanonicalURL: meta.CanonicalUrl,
+		SiteName:     h.cfg.Site.Title,
+		Author:       postAuthorName(post),
+		Tags:         post.Tags,
+		Published:    post.CreatedAt,
+		OEmbedURL:    h.cfg.Site.URL + "/v1/oembed?url=" + url.QueryEscape(h.postURL(post.ID)),
+	}
+	if meta.ImageUrl != nil {
+		m.Image = *meta.ImageUrl
+	}
+	if post.UpdatedAt != nil {
+		m.Modified = *post.UpdatedAt
+	}
+
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(seo.Head(&m)))
+}
+
+// @Router /oembed [get]
+// @Summary oEmbed provider
+// @Description Get an embeddable representation of a post from its URL, see https://oembed.com. Only JSON is supported.
+// @Tags seo
+// @Produce json
+// @Param filter query models.OEmbedParams false "Filter"
+// @Success 200 {object} models.OEmbed
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 401 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+// @Failure 501 {object} models.ResponseError
+func (h *handlerV1) GetOEmbed(ctx *gin.Context) {
+	if format := ctx.Query("format"); format != "" && format != "json" {
+		ctx.JSON(http.StatusNotImplemented, errResponse(ErrOEmbedFormat))
+		return
+	}
+
+	width, height, err := oEmbedSize(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	id, ok := h.parsePostURL(ctx.Query("url"))
+	if !ok {
+		ctx.JSON(http.StatusNotFound, errResponse(sql.ErrNoRows))
+		return
+	}
+
+	post, err := h.Storage.Post().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if post.Visibility == repo.PostVisibilityPrivate || post.Visibility == repo.PostVisibilityPassword {
+		ctx.JSON(http.StatusUnauthorized, errResponse(ErrPostNotPublic))
+		return
+	}
+
+	s, err := h.Storage.Post().GetSEO(post.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	meta := h.parsePostSEO(ctx, post, s)
+	link := html.EscapeString(meta.CanonicalUrl)
+	author := postAuthorName(post)
+
+	var b strings.Builder
+	b.WriteString(`<blockquote class="post-embed" cite="` + link + `">`)
+	b.WriteString(`<p><a href="` + link + `">` + html.EscapeString(meta.MetaTitle) + `</a></p>`)
+	if meta.MetaDescription != "" {
+		b.WriteString(`<p>` + html.EscapeString(meta.MetaDescription) + `</p>`)
+	}
+	footer := h.cfg.Site.Title
+	if author != "" {
+		footer = author + " · " + footer
+	}
+	b.WriteString(`<footer>` + html.EscapeString(footer) + `</footer>`)
+	b.WriteString(`</blockquote>`)
+
+	// the size of post images isn't known, and oEmbed requires it along
+	// with thumbnail_url, so no thumbnail is returned
+	res := models.OEmbed{
+		Type:         "rich",
+		Version:      "1.0",
+		Title:        meta.MetaTitle,
+		AuthorName:   author,
+		ProviderName: h.cfg.Site.Title,
+		ProviderUrl:  h.cfg.Site.URL,
+		CacheAge:     oEmbedCacheAge,
+		Html:         b.String(),
+		Width:        width,
+		Height:       height,
+	}
+	if author != "" {
+		res.AuthorUrl = h.cfg.Site.URL + "/users/" + strconv.FormatInt(post.UserID, 10)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// getVisiblePost loads the post of the id param, hiding private posts from
+// those who can't edit them. Otherwise it responds and returns false.
+func (h *handlerV1) getVisiblePost(ctx *gin.Context) (*repo.Post, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return nil, false
+	}
+
+	post, err := h.Storage.Post().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return nil, false
+	}
+
+	if post.Visibility == repo.PostVisibilityPrivate && !h.canBypassVisibility(ctx, post) {
+		ctx.JSON(http.StatusNotFound, errResponse(sql.ErrNoRows))
+		return nil, false
+	}
+
+	return post, true
+}
+
+// parsePostSEO fills in the metadata s doesn't set from the post. The
+// description of a locked post isn't derived, it would give its content
+// away.
+func (h *handlerV1) parsePostSEO(ctx *gin.Context, post *repo.Post, s *repo.PostSEO) models.PostSEO {
+	res := models.PostSEO{
+		MetaTitle:    post.Title,
+		CanonicalUrl: h.postURL(post.ID),
+		ImageUrl:     h.absoluteURL(post.ImageUrl),
+		Custom: models.SetPostSEORequest{
+			MetaTitle:       s.MetaTitle,
+			MetaDescription: s.MetaDescription,
+			CanonicalUrl:    s.CanonicalUrl,
+			ImageUrl:        s.ImageUrl,
+		},
+	}
+
+	locked := post.Visibility == repo.PostVisibilityPassword &&
+		!h.canBypassVisibility(ctx, post) && !h.isPostUnlocked(ctx, post.ID)
+	if !locked {
+		res.MetaDescription = feed.Excerpt(post.DescriptionHtml, metaDescriptionSize)
+	}
+
+	if s.MetaTitle != nil {
+		res.MetaTitle = *s.MetaTitle
+	}
+	if s.MetaDescription != nil {
+		res.MetaDescription = *s.MetaDescription
+	}
+	if s.CanonicalUrl != nil {
+		res.CanonicalUrl = *s.CanonicalUrl
+	}
+	if s.ImageUrl != nil {
+		res.ImageUrl = h.absoluteURL(s.ImageUrl)
+	}
+
+	return res
+}
+
+// parsePostURL returns the id of the post a URL of the site links to.
+func (h *handlerV1) parsePostURL(raw string) (int64, bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return 0, false
+	}
+
+	site, err := url.Parse(h.cfg.Site.URL)
+	if err != nil || !strings.EqualFold(u.Host, site.Host) {
+		return 0, false
+	}
+
+	m := postPathRegexp.FindStringSubmatch(strings.TrimPrefix(u.Path, site.Path))
+	if m == nil {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(m[1], 10, 64)
+	return id, err == nil
+}
+
+func oEmbedSize(ctx *gin.Context) (int, int, error) {
+	width, height := oEmbedWidth, oEmbedHeight
+
+	for _, p := range []struct {
+		name string
+		size *int
+	}{{"maxwidth", &width}, {"maxheight", &height}} {
+		if ctx.Query(p.name) == "" {
+			continue
+		}
+		max, err := strconv.Atoi(ctx.Query(p.name))
+		if err != nil || max < 1 {
+			return 0, 0, ErrInvalidOEmbedSize
+		}
+		if max < *p.size {
+			*p.size = max
+		}
+	}
+
+	return width, height, nil
+}
+
+func (h *handlerV1) postURL(id int64) string {
+	return h.cfg.Site.URL + "/posts/" + strconv.FormatInt(id, 10)
+}
+
+// absoluteURL makes links to the site's own files, like uploaded images,
+// absolute.
+func (h *handlerV1) absoluteURL(u *string) *string {
+	if u == nil || !strings.HasPrefix(*u, "/") || strings.HasPrefix(*u, "//") {
+		return u
+	}
+	abs := h.cfg.Site.URL + *u
+	return &abs
+}
+
+// postAuthorName returns the full name of the author of a post.
+func postAuthorName(post *repo.Post) string {
+	if len(post.Contributors) == 0 {
+		return ""
+	}
+	author := post.Contributors[0]
+	return strings.TrimSpace(author.FirstName + " " + author.LastName)
+}
+
+func nilIfEmpty(s *string) *string {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return nil
+	}
+	return s
+}
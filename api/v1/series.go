@@ -0,0 +1,290 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// @Security ApiKeyAuth
+// @Router /series [post]
+// @Summary Create a series
+// @Description Create a series of posts owned by the current user
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param series body models.CreateSeriesRequest true "Series"
+// @Success 201 {object} models.Series
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+func (h *handlerV1) CreateSeries(ctx *gin.Context) {
+	var req models.CreateSeriesRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	series, err := h.Storage.Series().Create(&repo.Series{
+		Title:       req.Title,
+		Description: req.Description,
+		UserID:      payload.UserID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, h.parseSeriesModel(ctx, series))
+}
+
+// @Router /series/{id} [get]
+// @Summary Get a series with its posts
+// @Description Get a series with its posts in order
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.Series
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetSeries(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	series, err := h.Storage.Series().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, h.parseSeriesModel(ctx, series))
+}
+
+// @Security ApiKeyAuth
+// @Router /series/{id} [put]
+// @Summary Update a series
+// @Description Update the title and description of a series. Only for its author and admins.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param series body models.CreateSeriesRequest true "Series"
+// @Success 200 {object} models.Series
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) UpdateSeries(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	var req models.CreateSeriesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	series, ok := h.getOwnSeries(ctx, id)
+	if !ok {
+		return
+	}
+
+	series.Title = req.Title
+	series.Description = req.Description
+
+	series, err = h.Storage.Series().Update(series)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, h.parseSeriesModel(ctx, series))
+}
+
+// @Security ApiKeyAuth
+// @Router /series/{id} [delete]
+// @Summary Delete a series
+// @Description Delete a series. Its posts are kept. Only for its author and admins.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.ResponseSuccess
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) DeleteSeries(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	if _, ok := h.getOwnSeries(ctx, id); !ok {
+		return
+	}
+
+	err = h.Storage.Series().Delete(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Success: "Successfully deleted!",
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router /series/{id}/posts [put]
+// @Summary Set the posts of a series
+// @Description Replace the posts of a series, in reading order. The posts must belong to the author of the series and to no other series.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param posts body models.SetSeriesPostsRequest true "Posts"
+// @Success 200 {object} models.Series
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) SetSeriesPosts(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	var req models.SetSeriesPostsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	if _, ok := h.getOwnSeries(ctx, id); !ok {
+		return
+	}
+
+	err = h.Storage.Series().SetPosts(id, req.PostIDs)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidSeriesPosts) {
+			ctx.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	series, err := h.Storage.Series().Get(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, h.parseSeriesModel(ctx, series))
+}
+
+// getOwnSeries loads a series the requester may change, writing the error
+// response otherwise.
+func (h *handlerV1) getOwnSeries(ctx *gin.Context, id int64) (*repo.Series, bool) {
+	series, err := h.Storage.Series().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return nil, false
+	}
+
+	if !h.isOwnerOrAdmin(ctx, series.UserID) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return nil, false
+	}
+
+	return series, true
+}
+
+// seriesPosts returns the posts of a series the requester may see. Private
+// posts are only listed for the author and admins.
+func (h *handlerV1) seriesPosts(ctx *gin.Context, series *repo.Series) []*models.SeriesPost {
+	owner := h.isOwnerOrAdmin(ctx, series.UserID)
+
+	posts := make([]*models.SeriesPost, 0, len(series.Posts))
+	for _, p := range series.Posts {
+		if p.Visibility == repo.PostVisibilityPrivate && !owner {
+			continue
+		}
+		posts = append(posts, &models.SeriesPost{
+			ID:       p.ID,
+			Title:    p.Title,
+			Position: p.Position,
+		})
+	}
+
+	return posts
+}
+
+func (h *handlerV1) parseSeriesModel(ctx *gin.Context, series *repo.Series) models.Series {
+	return models.Series{
+		ID:          series.ID,
+		Title:       series.Title,
+		Description: series.Description,
+		UserID:      series.UserID,
+		CreatedAt:   series.CreatedAt,
+		UpdatedAt:   series.UpdatedAt,
+		Posts:       h.seriesPosts(ctx, series),
+	}
+}
+
+// parsePostSeries describes the series of a post for the post page, with
+// the posts before and after it.
+func (h *handlerV1) parsePostSeries(ctx *gin.Context, series *repo.Series, postID int64) *models.PostSeries {
+	result := models.PostSeries{
+		ID:    series.ID,
+		Title: series.Title,
+		Posts: h.seriesPosts(ctx, series),
+	}
+	result.Total = len(result.Posts)
+
+	for i, p := range result.Posts {
+		if p.ID != postID {
+			continue
+		}
+
+		result.Position = p.Position
+		if i > 0 {
+			result.Prev = result.Posts[i-1]
+		}
+		if i+1 < len(result.Posts) {
+			result.Next = result.Posts[i+1]
+		}
+	}
+
+	return &result
+}
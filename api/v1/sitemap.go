@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/sitemap"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+const sitemapContentType = "application/xml; charset=utf-8"
+
+// sitemapKinds are the sitemaps listed in the index, in order, with the
+// path of their pages on the site.
+var sitemapKinds = []struct {
+	kind string
+	path string
+}{
+	{kind: repo.SitemapPosts, path: "/posts/"},
+	{kind: repo.SitemapCategories, path: "/categories/"},
+	{kind: repo.SitemapUsers, path: "/users/"},
+}
+
+// GetRobots serves robots.txt.
+func (h *handlerV1) GetRobots(ctx *gin.Context) {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if len(h.cfg.Site.RobotsDisallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	for _, path := range h.cfg.Site.RobotsDisallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	b.WriteString("\nSitemap: " + h.cfg.Site.URL + "/sitemap.xml\n")
+
+	ctx.String(http.StatusOK, b.String())
+}
+
+// GetSitemapIndex serves sitemap.xml, the index of the sitemap chunks.
+func (h *handlerV1) GetSitemapIndex(ctx *gin.Context) {
+	h.serveSitemap(ctx, SitemapKey+"index", func() ([]byte, error) {
+		var sitemaps []*sitemap.URL
+		for _, k := range sitemapKinds {
+			chunks, err := h.Storage.Sitemap().GetChunks(k.kind, sitemap.MaxURLs)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, c := range chunks {
+				sitemaps = append(sitemaps, &sitemap.URL{
+					Loc:     fmt.Sprintf("%s/sitemaps/%s-%d.xml", h.cfg.Site.URL, k.kind, c.Number),
+					LastMod: c.LastMod,
+				})
+			}
+		}
+
+		return sitemap.Index(sitemaps)
+	})
+}
+
+// GetSitemap serves a sitemap chunk, /sitemaps/{kind}-{number}.xml.
+func (h *handlerV1) GetSitemap(ctx *gin.Context) {
+	name := strings.TrimSuffix(ctx.Param("file"), ".xml")
+	sep := strings.LastIndexByte(name, '-')
+	if sep < 0 || !strings.HasSuffix(ctx.Param("file"), ".xml") {
+		ctx.JSON(http.StatusNotFound, errResponse(repo.ErrUnknownSitemap))
+		return
+	}
+
+	kind := name[:sep]
+	chunk, err := strconv.ParseInt(name[sep+1:], 10, 64)
+	if err != nil || chunk < 1 {
+		ctx.JSON(http.StatusNotFound, errResponse(repo.ErrUnknownSitemap))
+		return
+	}
+
+	var path string
+	for _, k := range sitemapKinds {
+		if k.kind == kind {
+			path = k.path
+		}
+	}
+	if path == "" {
+		ctx.JSON(http.StatusNotFound, errResponse(repo.ErrUnknownSitemap))
+		return
+	}
+
+	h.serveSitemap(ctx, sitemapChunkKey(kind, chunk), func() ([]byte, error) {
+		entries, err := h.Storage.Sitemap().GetEntries(kind, chunk, sitemap.MaxURLs)
+		if err != nil {
+			return nil, err
+		}
+
+		urls := make([]*sitemap.URL, 0, len(entries))
+		for _, e := range entries {
+			urls = append(urls, &sitemap.URL{
+				Loc:     h.cfg.Site.URL + path + strconv.FormatInt(e.ID, 10),
+				LastMod: e.LastMod,
+			})
+		}
+
+		return sitemap.URLSet(urls)
+	})
+}
+
+// serveSitemap serves the sitemap cached under key, generating it with
+// build when it isn't cached.
+func (h *handlerV1) serveSitemap(ctx *gin.Context, key string, build func() ([]byte, error)) {
+	if cached, err := h.inMemory.Get(key); err == nil {
+		ctx.Data(http.StatusOK, sitemapContentType, []byte(cached))
+		return
+	}
+
+	data, err := build()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if err := h.inMemory.Set(key, string(data), h.cfg.Site.SitemapCacheDuration); err != nil {
+		log.Printf("failed to cache sitemap %s: %v", key, err)
+	}
+
+	ctx.Data(http.StatusOK, sitemapContentType, data)
+}
+
+func sitemapChunkKey(kind string, chunk int64) string {
+	return SitemapKey + kind + "_" + strconv.FormatInt(chunk, 10)
+}
+
+// invalidateSitemap drops the cached chunk holding id and the index, so
+// only they are regenerated on the next request.
+func (h *handlerV1) invalidateSitemap(kind string, id int64) {
+	key := sitemapChunkKey(kind, (id-1)/sitemap.MaxURLs+1)
+	if err := h.inMemory.Del(key, SitemapKey+"index"); err != nil {
+		log.Printf("failed to invalidate sitemap %s: %v", key, err)
+	}
+}
+
+// invalidatePostSitemaps drops the chunks listing a post, its author and
+// its category.
+func (h *handlerV1) invalidatePostSitemaps(post *repo.Post) {
+	h.invalidateSitemap(repo.SitemapPosts, post.ID)
+	h.invalidateSitemap(repo.SitemapUsers, post.UserID)
+	h.invalidateSitemap(repo.SitemapCategories, post.CategoryID)
+}
@@ -0,0 +1,154 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/stats [get]
+// @Summary Get daily stats of a post
+// @Description Get views, unique viewers, likes, dislikes and comments of a post per day. Only for the author and admins. Defaults to the last 30 days.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param filter query models.StatsParams false "Filter"
+// @Success 200 {object} models.PostStatsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetPostStats(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	params, err := validateStatsParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	post, err := h.Storage.Post().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if post.UserID != payload.UserID && payload.UserType != repo.UserTypeSuperadmin {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	params.PostID = id
+	days, err := h.Storage.Stats().GetPostStats(params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	response := models.PostStatsResponse{
+		PostID: id,
+		From:   params.From.Format(dateLayout),
+		To:     params.To.Format(dateLayout),
+	}
+	response.Days, response.Totals = parseDailyStats(days)
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Security ApiKeyAuth
+// @Router /users/me/stats [get]
+// @Summary Get daily stats of my posts
+// @Description Get the summed daily stats of all posts of the current user and the posts with the most views in the range. Defaults to the last 30 days.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param filter query models.StatsParams false "Filter"
+// @Success 200 {object} models.UserStatsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+func (h *handlerV1) GetUserStats(ctx *gin.Context) {
+	params, err := validateStatsParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	params.UserID = payload.UserID
+	stats, err := h.Storage.Stats().GetUserStats(params)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	response := models.UserStatsResponse{
+		From:     params.From.Format(dateLayout),
+		To:       params.To.Format(dateLayout),
+		TopPosts: make([]*models.TopPost, 0, len(stats.TopPosts)),
+	}
+	response.Days, response.Totals = parseDailyStats(stats.Days)
+
+	for _, p := range stats.TopPosts {
+		response.TopPosts = append(response.TopPosts, &models.TopPost{
+			ID:       p.ID,
+			Title:    p.Title,
+			Views:    p.Views,
+			Likes:    p.Likes,
+			Comments: p.Comments,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func parseDailyStats(days []*repo.DailyStats) ([]*models.DailyStats, models.StatsTotals) {
+	var (
+		result = make([]*models.DailyStats, 0, len(days))
+		totals models.StatsTotals
+	)
+
+	for _, d := range days {
+		result = append(result, &models.DailyStats{
+			Day:           d.Day.Format(dateLayout),
+			Views:         d.Views,
+			UniqueViewers: d.UniqueViewers,
+			Likes:         d.Likes,
+			Dislikes:      d.Dislikes,
+			Comments:      d.Comments,
+		})
+
+		totals.Views += d.Views
+		totals.UniqueViewers += d.UniqueViewers
+		totals.Likes += d.Likes
+		totals.Dislikes += d.Dislikes
+		totals.Comments += d.Comments
+	}
+
+	return result, totals
+}
@@ -0,0 +1,311 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/archive"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/markdown"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/utils"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/translations [get]
+// @Summary Get the translations of a post
+// @Description Only the author, co-authors, editors and admins can list the translations.
+// @Tags translation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.GetPostTranslationsResponse
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetPostTranslations(ctx *gin.Context) {
+	post, ok := h.getEditablePost(ctx)
+	if !ok {
+		return
+	}
+
+	translations, err := h.Storage.Post().GetTranslations([]int64{post.ID}, nil)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	res := models.GetPostTranslationsResponse{
+		Translations: make([]*models.PostTranslation, 0, len(translations)),
+	}
+	for _, t := range translations {
+		res.Translations = append(res.Translations, parsePostTranslationModel(t))
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/translations/{lang} [put]
+// @Summary Set a translation of a post
+// @Description Add or replace the translation of a post in a language other than its own. The description is Markdown. Only the author, co-authors, editors and admins can translate a post.
+// @Tags translation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param lang path string true "Language"
+// @Param data body models.SetPostTranslationRequest true "Data"
+// @Success 200 {object} models.PostTranslation
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+// @Failure 409 {object} models.ResponseError
+func (h *handlerV1) SetPostTranslation(ctx *gin.Context) {
+	var (
+		req models.SetPostTranslationRequest
+	)
+
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	lang := ctx.Param("lang")
+	if !h.isSupportedLanguage(lang) {
+		ctx.JSON(http.StatusBadRequest, errResponse(ErrUnsupportedLanguage))
+		return
+	}
+
+	post, ok := h.getEditablePost(ctx)
+	if !ok {
+		return
+	}
+
+	if lang == post.Language {
+		ctx.JSON(http.StatusBadRequest, errResponse(ErrTranslationLanguage))
+		return
+	}
+
+	slug := req.Slug
+	if slug == "" {
+		slug = req.Title
+	}
+
+	t := repo.PostTranslation{
+		PostID:      post.ID,
+		Language:    lang,
+		Title:       req.Title,
+		Slug:        archive.Slug(slug),
+		Description: req.Description,
+	}
+	if t.Slug == "" {
+		ctx.JSON(http.StatusBadRequest, errResponse(ErrEmptySlug))
+		return
+	}
+
+	result, err := markdown.Render(t.Description)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+	t.DescriptionHtml = result.HTML
+	t.ReadingTime = int32(result.ReadingTime)
+	t.TableOfContents = parseHeadings(result.TableOfContents)
+
+	translation, err := h.Storage.Post().SetTranslation(&t)
+	if err != nil {
+		if errors.Is(err, repo.ErrSlugExists) {
+			ctx.JSON(http.StatusConflict, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, parsePostTranslationModel(translation))
+}
+
+// @Security ApiKeyAuth
+// @Router /posts/{id}/translations/{lang} [delete]
+// @Summary Delete a translation of a post
+// @Description Only the author, co-authors, editors and admins can delete a translation.
+// @Tags translation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Param lang path string true "Language"
+// @Success 200 {object} models.ResponseSuccess
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) DeletePostTranslation(ctx *gin.Context) {
+	post, ok := h.getEditablePost(ctx)
+	if !ok {
+		return
+	}
+
+	err := h.Storage.Post().DeleteTranslation(post.ID, ctx.Param("lang"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.ResponseSuccess{
+		Success: "Successfully deleted!",
+	})
+}
+
+// getEditablePost loads the post of the id param and checks the user can
+// edit it. Otherwise it responds and returns false.
+func (h *handlerV1) getEditablePost(ctx *gin.Context) (*repo.Post, bool) {
+	post, ok := h.getVisiblePost(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	if !h.canEditPost(ctx, post) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return nil, false
+	}
+
+	return post, true
+}
+
+func (h *handlerV1) isSupportedLanguage(lang string) bool {
+	for _, l := range h.cfg.Languages.Supported {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// preferredLanguages returns the fallback chain of the languages to serve
+// posts in: the lang query parameter, then the Accept-Language header and
+// then the default language. Unsupported languages are skipped.
+func (h *handlerV1) preferredLanguages(ctx *gin.Context) []string {
+	candidates := append([]string{ctx.Query("lang")}, utils.ParseAcceptLanguage(ctx.GetHeader("Accept-Language"))...)
+	candidates = append(candidates, h.cfg.Languages.Default)
+
+	langs := make([]string, 0, len(candidates))
+	seen := make(map[string]bool)
+	for _, l := range candidates {
+		if h.isSupportedLanguage(l) && !seen[l] {
+			seen[l] = true
+			langs = append(langs, l)
+		}
+	}
+	return langs
+}
+
+// localizePosts serves every post in the first preferred language it is
+// available in, keeping its own language when it isn't available in any.
+func (h *handlerV1) localizePosts(ctx *gin.Context, posts []*repo.Post) error {
+	ctx.Header("Vary", "Accept-Language")
+
+	chain := h.preferredLanguages(ctx)
+
+	targets := make(map[int64]string)
+	var ids []int64
+	for _, post := range posts {
+		if lang := postLanguage(post, chain); lang != post.Language {
+			targets[post.ID] = lang
+			ids = append(ids, post.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	translations, err := h.Storage.Post().GetTranslations(ids, chain)
+	if err != nil {
+		return err
+	}
+
+	byPost := make(map[int64]*repo.PostTranslation)
+	for _, t := range translations {
+		if targets[t.PostID] == t.Language {
+			byPost[t.PostID] = t
+		}
+	}
+
+	for _, post := range posts {
+		if t, ok := byPost[post.ID]; ok {
+			applyTranslation(post, t)
+		}
+	}
+
+	return nil
+}
+
+// postLanguage returns the first language of chain the post is available
+// in, or its own language.
+func postLanguage(post *repo.Post, chain []string) string {
+	for _, lang := range chain {
+		if lang == post.Language {
+			return lang
+		}
+		for _, t := range post.Translations {
+			if t == lang {
+				return lang
+			}
+		}
+	}
+	return post.Language
+}
+
+// applyTranslation replaces the content of a post with a translation.
+func applyTranslation(post *repo.Post, t *repo.PostTranslation) {
+	translations := []string{post.Language}
+	for _, lang := range post.Translations {
+		if lang != t.Language {
+			translations = append(translations, lang)
+		}
+	}
+	sort.Strings(translations)
+
+	post.Title = t.Title
+	post.Description = t.Description
+	post.DescriptionHtml = t.DescriptionHtml
+	post.TableOfContents = t.TableOfContents
+	post.ReadingTime = t.ReadingTime
+	post.Language = t.Language
+	post.Translations = translations
+	post.Slug = &t.Slug
+}
+
+func parsePostTranslationModel(t *repo.PostTranslation) *models.PostTranslation {
+	res := models.PostTranslation{
+		PostID:          t.PostID,
+		Language:        t.Language,
+		Title:           t.Title,
+		Slug:            t.Slug,
+		Description:     t.Description,
+		DescriptionHtml: t.DescriptionHtml,
+		TableOfContents: make([]*models.PostHeading, 0, len(t.TableOfContents)),
+		ReadingTime:     t.ReadingTime,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+	}
+
+	for _, h := range t.TableOfContents {
+		res.TableOfContents = append(res.TableOfContents, &models.PostHeading{
+			Level: h.Level,
+			ID:    h.ID,
+			Text:  h.Text,
+		})
+	}
+
+	return &res
+}
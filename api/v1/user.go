@@ -92,6 +92,19 @@ func (h *handlerV1) GetUser(c *gin.Context) {
 		return
 	}
 
+	// profiles hidden by reports are only seen by their owners and admins
+	if !h.isOwnerOrAdmin(c, resp.ID) {
+		hidden, err := h.Storage.Report().IsHidden(repo.ReportTargetUser, resp.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errResponse(err))
+			return
+		}
+		if hidden {
+			c.JSON(http.StatusNotFound, errResponse(sql.ErrNoRows))
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, models.User{
 		ID:              resp.ID,
 		FirstName:       resp.FirstName,
@@ -252,24 +265,45 @@ func (h *handlerV1) GetAllUsers(c *gin.Context) {
 		return
 	}
 
+	sort, err := parseSortParam(params.Sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	after, before, err := decodeCursors(params.After, params.Before, params.Sort)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
 	result, err := h.Storage.User().GetAll(&repo.GetAllUserParams{
 		Limit:  int32(params.Limit),
 		Page:   int32(params.Page),
 		Search: params.Search,
+		Sort:   sort,
+		After:  after,
+		Before: before,
 	})
 
 	if err != nil {
+		if errors.Is(err, repo.ErrInvalidSortField) || errors.Is(err, repo.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, errResponse(err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errResponse(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, getUsersResponse(result))
+	c.JSON(http.StatusOK, getUsersResponse(result, params.Sort))
 }
 
-func getUsersResponse(data *repo.GetAllUsersResult) *models.GetAllUsersResponse {
+func getUsersResponse(data *repo.GetAllUsersResult, sort string) *models.GetAllUsersResponse {
 	response := models.GetAllUsersResponse{
-		Users: make([]*models.User, 0),
-		Count: data.Count,
+		Users:      make([]*models.User, 0),
+		Count:      data.Count,
+		NextCursor: encodeCursor(data.NextCursor, sort),
+		PrevCursor: encodeCursor(data.PrevCursor, sort),
 	}
 
 	for _, user := range data.Users {
@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nurmuhammaddeveloper/blog_db/api/models"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// maxWordPressImportSize is the largest export file CreateWordPressImport
+// accepts.
+const maxWordPressImportSize = 256 << 20
+
+// @Security ApiKeyAuth
+// @Router /imports/wordpress [post]
+// @Summary Import a WordPress export
+// @Description Queue a WordPress eXtended RSS export for import. Authors, categories, posts, approved comments and media are imported in the background, poll /imports/wordpress/{id} for progress. Importing the same site again skips what was imported before. Superadmins only.
+// @Tags import
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "WXR file"
+// @Success 202 {object} models.WordPressImport
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+func (h *handlerV1) CreateWordPressImport(ctx *gin.Context) {
+	var file File
+
+	payload, err := h.GetAuthPayload(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxWordPressImportSize)
+	if err := ctx.ShouldBind(&file); err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	if err := os.MkdirAll(h.cfg.Import.Dir, os.ModePerm); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	fileName := uuid.New().String() + ".xml"
+	err = ctx.SaveUploadedFile(file.File, filepath.Join(h.cfg.Import.Dir, fileName))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	imp, err := h.Storage.WordPress().Create(&repo.WordPressImport{
+		UserID:   payload.UserID,
+		FileName: fileName,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, parseWordPressImportModel(imp))
+}
+
+// @Security ApiKeyAuth
+// @Router /imports/wordpress/{id} [get]
+// @Summary Get a WordPress import
+// @Description Get the progress of a WordPress import along with the latest items that failed. Superadmins only.
+// @Tags import
+// @Accept json
+// @Produce json
+// @Param id path int true "ID"
+// @Success 200 {object} models.WordPressImport
+// @Failure 500 {object} models.ResponseError
+// @Failure 400 {object} models.ResponseError
+// @Failure 403 {object} models.ResponseError
+// @Failure 404 {object} models.ResponseError
+func (h *handlerV1) GetWordPressImport(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errResponse(err))
+		return
+	}
+
+	if !h.isSuperadmin(ctx) {
+		ctx.JSON(http.StatusForbidden, errResponse(ErrForbidden))
+		return
+	}
+
+	imp, err := h.Storage.WordPress().Get(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, parseWordPressImportModel(imp))
+}
+
+func parseWordPressImportModel(imp *repo.WordPressImport) models.WordPressImport {
+	result := models.WordPressImport{
+		ID:         imp.ID,
+		UserID:     imp.UserID,
+		Status:     imp.Status,
+		Total:      imp.Total,
+		Processed:  imp.Processed,
+		Failed:     imp.Failed,
+		Error:      imp.Error,
+		CreatedAt:  imp.CreatedAt,
+		UpdatedAt:  imp.UpdatedAt,
+		FinishedAt: imp.FinishedAt,
+		Errors:     make([]*models.WordPressImportError, 0, len(imp.Errors)),
+	}
+
+	for _, e := range imp.Errors {
+		result.Errors = append(result.Errors, &models.WordPressImportError{
+			Kind:      e.Kind,
+			WpID:      e.WpID,
+			Error:     e.Error,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	return result
+}
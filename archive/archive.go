@@ -0,0 +1,329 @@
+// Package archive imports and exports posts as a ZIP of Markdown files with
+// YAML front matter.
+package archive
+
+import (
+	"archive/zip"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/markdown"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/utils"
+	"github.com/nurmuhammaddeveloper/blog_db/storage"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+const (
+	// MaxFileSize is the largest Markdown file that is imported.
+	MaxFileSize = 1 << 20
+	// MaxFiles is the most files an archive may hold.
+	MaxFiles = 10000
+
+	exportPageSize = 100
+)
+
+var (
+	ErrTooManyFiles  = fmt.Errorf("archive must not hold more than %d files", MaxFiles)
+	ErrFileTooLarge  = fmt.Errorf("file is larger than %d bytes", MaxFileSize)
+	ErrNotMarkdown   = errors.New("not a Markdown file")
+	ErrNoAuthor      = errors.New("front matter has no author and there is no default one")
+	ErrUnknownAuthor = errors.New("no user with the author email")
+	ErrVisibility    = errors.New("visibility must be public, unlisted, private or password")
+)
+
+type ImportOptions struct {
+	// DefaultAuthor is the email of the author of files that don't name one.
+	DefaultAuthor string
+	// Language is the language the posts are written in.
+	Language string
+}
+
+// FileResult is the outcome of importing one file, PostID is set on success.
+type FileResult struct {
+	Name   string
+	PostID int64
+	Err    error
+}
+
+type ImportResult struct {
+	Imported int
+	Failed   int
+	Files    []*FileResult
+}
+
+// Import creates a post for every Markdown file of a ZIP archive, along with
+// the categories they need. A file that can't be imported doesn't stop the
+// others. Password protected posts become private, since their password
+// isn't exported.
+func Import(strg storage.StorageI, r io.ReaderAt, size int64, opts *ImportOptions) (*ImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if len(zr.File) > MaxFiles {
+		return nil, ErrTooManyFiles
+	}
+
+	im := importer{
+		strg:       strg,
+		opts:       opts,
+		authors:    make(map[string]int64),
+		categories: make(map[string]int64),
+	}
+
+	result := ImportResult{
+		Files: make([]*FileResult, 0),
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || skipFile(f.Name) {
+			continue
+		}
+
+		file := FileResult{Name: f.Name}
+		file.PostID, file.Err = im.importFile(f)
+		if file.Err != nil {
+			result.Failed++
+		} else {
+			result.Imported++
+		}
+		result.Files = append(result.Files, &file)
+	}
+
+	return &result, nil
+}
+
+// skipFile reports whether a file is metadata added by the archiver.
+func skipFile(name string) bool {
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".")
+}
+
+type importer struct {
+	strg       storage.StorageI
+	opts       *ImportOptions
+	authors    map[string]int64
+	categories map[string]int64
+}
+
+func (im *importer) importFile(f *zip.File) (int64, error) {
+	if ext := strings.ToLower(path.Ext(f.Name)); ext != ".md" && ext != ".markdown" {
+		return 0, ErrNotMarkdown
+	}
+	if f.UncompressedSize64 > MaxFileSize {
+		return 0, ErrFileTooLarge
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	// the header size can't be trusted
+	data, err := io.ReadAll(io.LimitReader(rc, MaxFileSize+1))
+	if err != nil {
+		return 0, err
+	}
+	if len(data) > MaxFileSize {
+		return 0, ErrFileTooLarge
+	}
+
+	fm, body, err := Parse(data)
+	if err != nil {
+		return 0, err
+	}
+
+	post := repo.Post{
+		Title:       fm.Title,
+		Description: body,
+		ImageUrl:    fm.Image,
+		Visibility:  fm.Visibility,
+		Tags:        utils.NormalizeTags(fm.Tags),
+		Language:    im.opts.Language,
+		UpdatedAt:   fm.Updated,
+	}
+	if fm.Date != nil {
+		post.CreatedAt = *fm.Date
+	}
+
+	switch post.Visibility {
+	case "", repo.PostVisibilityPublic, repo.PostVisibilityUnlisted, repo.PostVisibilityPrivate:
+	case repo.PostVisibilityPassword:
+		post.Visibility = repo.PostVisibilityPrivate
+	default:
+		return 0, ErrVisibility
+	}
+
+	post.UserID, err = im.author(fm.Author)
+	if err != nil {
+		return 0, err
+	}
+
+	post.CategoryID, err = im.category(fm.Category)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := markdown.Render(post.Description)
+	if err != nil {
+		return 0, err
+	}
+	post.DescriptionHtml = result.HTML
+	post.ReadingTime = int32(result.ReadingTime)
+	post.TableOfContents = make([]*repo.PostHeading, 0, len(result.TableOfContents))
+	for _, h := range result.TableOfContents {
+		post.TableOfContents = append(post.TableOfContents, &repo.PostHeading{
+			Level: h.Level,
+			ID:    h.ID,
+			Text:  h.Text,
+		})
+	}
+
+	created, err := im.strg.Post().Create(&post)
+	if err != nil {
+		return 0, err
+	}
+
+	return created.ID, nil
+}
+
+func (im *importer) author(email string) (int64, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		email = strings.ToLower(im.opts.DefaultAuthor)
+	}
+	if email == "" {
+		return 0, ErrNoAuthor
+	}
+
+	if id, ok := im.authors[email]; ok {
+		return id, nil
+	}
+
+	user, err := im.strg.User().GetByEmail(email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: %s", ErrUnknownAuthor, email)
+		}
+		return 0, err
+	}
+
+	im.authors[email] = user.ID
+	return user.ID, nil
+}
+
+// category returns the id of the category with the title, creating it when
+// there is none.
+func (im *importer) category(title string) (int64, error) {
+	if id, ok := im.categories[title]; ok {
+		return id, nil
+	}
+
+	category, err := im.strg.Category().GetByTitle(title)
+	if errors.Is(err, sql.ErrNoRows) {
+		category, err = im.strg.Category().Create(&repo.Category{Title: title})
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	im.categories[title] = category.ID
+	return category.ID, nil
+}
+
+// Export writes every live post to w as a ZIP archive that Import reads,
+// and returns how many posts it wrote.
+func Export(strg storage.StorageI, w io.Writer) (int, error) {
+	zw := zip.NewWriter(w)
+
+	var (
+		count      int
+		after      *repo.Cursor
+		authors    = make(map[int64]string)
+		categories = make(map[int64]string)
+	)
+	for {
+		page, err := strg.Post().GetAll(&repo.GetPostsParams{
+			Limit:           exportPageSize,
+			Sort:            []*repo.SortField{{Field: "created_at"}},
+			After:           after,
+			AllVisibilities: true,
+		})
+		if err != nil {
+			return count, err
+		}
+
+		for _, post := range page.Posts {
+			fm := FrontMatter{
+				Title:      post.Title,
+				Tags:       post.Tags,
+				Date:       &post.CreatedAt,
+				Updated:    post.UpdatedAt,
+				Image:      post.ImageUrl,
+				Visibility: post.Visibility,
+			}
+
+			fm.Author, err = lookup(authors, post.UserID, func(id int64) (string, error) {
+				user, err := strg.User().Get(id)
+				if err != nil {
+					return "", err
+				}
+				return user.Email, nil
+			})
+			if err != nil {
+				return count, err
+			}
+
+			fm.Category, err = lookup(categories, post.CategoryID, func(id int64) (string, error) {
+				category, err := strg.Category().Get(id)
+				if err != nil {
+					return "", err
+				}
+				return category.Title, nil
+			})
+			if err != nil {
+				return count, err
+			}
+
+			data, err := Format(&fm, post.Description)
+			if err != nil {
+				return count, err
+			}
+
+			f, err := zw.Create(fmt.Sprintf("%d-%s.md", post.ID, Slug(post.Title)))
+			if err != nil {
+				return count, err
+			}
+			if _, err := f.Write(data); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if page.NextCursor == nil {
+			break
+		}
+		after = page.NextCursor
+	}
+
+	return count, zw.Close()
+}
+
+// lookup caches the names of the users and categories of exported posts.
+// Ones that are gone are exported with an empty name.
+func lookup(cache map[int64]string, id int64, get func(id int64) (string, error)) (string, error) {
+	if name, ok := cache[id]; ok {
+		return name, nil
+	}
+
+	name, err := get(id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	cache[id] = name
+	return name, nil
+}
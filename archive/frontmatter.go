@@ -0,0 +1,107 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrNoFrontMatter = errors.New("file must start with a YAML front matter block")
+	ErrNoTitle       = errors.New("front matter has no title")
+	ErrNoCategory    = errors.New("front matter has no category")
+)
+
+const frontMatterDelimiter = "---"
+
+// FrontMatter is the YAML block at the top of an exported post.
+type FrontMatter struct {
+	Title      string     `yaml:"title"`
+	Category   string     `yaml:"category"`
+	Tags       []string   `yaml:"tags,omitempty"`
+	Date       *time.Time `yaml:"date,omitempty"`
+	Updated    *time.Time `yaml:"updated,omitempty"`
+	Author     string     `yaml:"author,omitempty"`
+	Image      *string    `yaml:"image,omitempty"`
+	Visibility string     `yaml:"visibility,omitempty"`
+}
+
+// Parse splits a Markdown file into its front matter and body.
+func Parse(data []byte) (*FrontMatter, string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	src := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	if !strings.HasPrefix(src, frontMatterDelimiter+"\n") {
+		return nil, "", ErrNoFrontMatter
+	}
+	src = src[len(frontMatterDelimiter)+1:]
+
+	var meta, body string
+	switch {
+	case strings.HasPrefix(src, frontMatterDelimiter+"\n"):
+		body = src[len(frontMatterDelimiter)+1:]
+	default:
+		end := strings.Index(src, "\n"+frontMatterDelimiter+"\n")
+		if end < 0 {
+			if !strings.HasSuffix(src, "\n"+frontMatterDelimiter) {
+				return nil, "", ErrNoFrontMatter
+			}
+			end = len(src) - len(frontMatterDelimiter) - 1
+			src += "\n"
+		}
+		meta, body = src[:end], src[end+len(frontMatterDelimiter)+2:]
+	}
+
+	var fm FrontMatter
+	if err := yaml.Unmarshal([]byte(meta), &fm); err != nil {
+		return nil, "", err
+	}
+
+	fm.Title = strings.TrimSpace(fm.Title)
+	fm.Category = strings.TrimSpace(fm.Category)
+	if fm.Title == "" {
+		return nil, "", ErrNoTitle
+	}
+	if fm.Category == "" {
+		return nil, "", ErrNoCategory
+	}
+
+	return &fm, strings.TrimPrefix(body, "\n"), nil
+}
+
+// Format writes a post as Markdown with front matter, the way Parse reads it.
+func Format(fm *FrontMatter, body string) ([]byte, error) {
+	meta, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.Write(meta)
+	buf.WriteString(frontMatterDelimiter + "\n\n")
+	buf.WriteString(body)
+	if !strings.HasSuffix(body, "\n") {
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+var nonSlugChars = regexp.MustCompile(`[^\pL\pN]+`)
+
+// Slug turns a title into a URL and file name friendly string.
+func Slug(title string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if runes := []rune(slug); len(runes) > 60 {
+		slug = strings.TrimRight(string(runes[:60]), "-")
+	}
+	if slug == "" {
+		return "post"
+	}
+	return slug
+}
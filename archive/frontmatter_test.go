@@ -0,0 +1,61 @@
+package archive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatParse(t *testing.T) {
+	date := time.Date(2022, 11, 5, 10, 30, 0, 0, time.UTC)
+	image := "https://example.com/cover.png"
+	fm := &FrontMatter{
+		Title:      "Hello: world",
+		Category:   "News",
+		Tags:       []string{"go", "blog"},
+		Date:       &date,
+		Author:     "author@example.com",
+		Image:      &image,
+		Visibility: "public",
+	}
+
+	data, err := Format(fm, "# Hello\n\nBody text")
+	require.NoError(t, err)
+
+	parsed, body, err := Parse(data)
+	require.NoError(t, err)
+	require.Equal(t, fm, parsed)
+	require.Equal(t, "# Hello\n\nBody text\n", body)
+}
+
+func TestParse(t *testing.T) {
+	fm, body, err := Parse([]byte("\ufeff---\r\ntitle: Title\r\ncategory: Go\r\n---\r\nBody\r\n"))
+	require.NoError(t, err)
+	require.Equal(t, "Title", fm.Title)
+	require.Equal(t, "Go", fm.Category)
+	require.Equal(t, "Body\n", body)
+
+	fm, body, err = Parse([]byte("---\ntitle: Title\ncategory: Go\n---"))
+	require.NoError(t, err)
+	require.Equal(t, "Title", fm.Title)
+	require.Equal(t, "", body)
+
+	_, _, err = Parse([]byte("# No front matter"))
+	require.ErrorIs(t, err, ErrNoFrontMatter)
+
+	_, _, err = Parse([]byte("---\ntitle: Title\n---\nBody"))
+	require.ErrorIs(t, err, ErrNoCategory)
+
+	_, _, err = Parse([]byte("---\ncategory: Go\n---\nBody"))
+	require.ErrorIs(t, err, ErrNoTitle)
+
+	_, _, err = Parse([]byte("---\ntitle: Title\ncategory: Go\nBody"))
+	require.ErrorIs(t, err, ErrNoFrontMatter)
+}
+
+func TestSlug(t *testing.T) {
+	require.Equal(t, "hello-world", Slug("Hello, World!"))
+	require.Equal(t, "привет-мир", Slug("Привет мир"))
+	require.Equal(t, "post", Slug("!!!"))
+}
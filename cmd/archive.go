@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nurmuhammaddeveloper/blog_db/archive"
+	"github.com/nurmuhammaddeveloper/blog_db/config"
+	"github.com/nurmuhammaddeveloper/blog_db/storage"
+)
+
+var errUsage = errors.New(`usage:
+	main import [-author email] posts.zip
+	main export posts.zip`)
+
+// runCommand runs a subcommand given on the command line instead of the
+// server.
+func runCommand(cfg *config.Config, strg storage.StorageI, args []string) error {
+	switch args[0] {
+	case "import":
+		return importPosts(cfg, strg, args[1:])
+	case "export":
+		return exportPosts(strg, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func importPosts(cfg *config.Config, strg storage.StorageI, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	author := flags.String("author", "", "email of the author of files that don't name one")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	result, err := archive.Import(strg, f, info.Size(), &archive.ImportOptions{
+		DefaultAuthor: *author,
+		Language:      cfg.Languages.Default,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range result.Files {
+		if file.Err != nil {
+			fmt.Printf("%s: %v\n", file.Name, file.Err)
+		} else {
+			fmt.Printf("%s: post %d\n", file.Name, file.PostID)
+		}
+	}
+	fmt.Printf("imported %d posts, %d failed\n", result.Imported, result.Failed)
+
+	return nil
+}
+
+func exportPosts(strg storage.StorageI, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	count, err := archive.Export(strg, f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("exported %d posts\n", count)
+
+	return f.Close()
+}
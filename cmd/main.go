@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/go-redis/redis/v9"
 	"github.com/jmoiron/sqlx"
@@ -11,6 +15,7 @@ import (
 	_ "github.com/nurmuhammaddeveloper/blog_db/api/docs"
 	"github.com/nurmuhammaddeveloper/blog_db/config"
 	"github.com/nurmuhammaddeveloper/blog_db/storage"
+	"github.com/nurmuhammaddeveloper/blog_db/worker"
 )
 
 func main() {
@@ -29,6 +34,17 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
+
+	strg := storage.NewStoragePg(psqlConn)
+
+	if len(os.Args) > 1 {
+		err = runCommand(&cfg, strg, os.Args[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	fmt.Println("Configuration: ", cfg)
 	fmt.Println("Connected Succesfully!")
 
@@ -36,19 +52,31 @@ func main() {
 		Addr: cfg.Redis.Addr,
 	})
 
-	strg := storage.NewStoragePg(psqlConn)
 	inMemory := storage.NewInMemoryStorage(rdb)
 
+	// the worker's jobs stop when the process is asked to
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	worker.New(&worker.Options{
+		Cfg:      &cfg,
+		Storage:  strg,
+		InMemory: inMemory,
+	}).Start(ctx)
+
 	apiServer := api.New(&api.RoutetOptions{
 		Cfg:      &cfg,
 		Storage:  strg,
 		InMemory: inMemory,
 	})
 
-	err = apiServer.Run(cfg.HttpPort)
-	if err != nil {
-		log.Fatalf("failed to run server: %s", err)
-	}
+	go func() {
+		err := apiServer.Run(cfg.HttpPort)
+		if err != nil {
+			log.Fatalf("failed to run server: %s", err)
+		}
+	}()
 
+	<-ctx.Done()
 	log.Print("Server Stopped!")
 }
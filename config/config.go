@@ -1,6 +1,9 @@
 package config
 
 import (
+	"strings"
+	"time"
+
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
@@ -11,6 +14,15 @@ type Config struct {
 	Authorization Authorization
 	Smtp          Smtp
 	Redis         Redis
+	Trash         Trash
+	Views         Views
+	Stats         Stats
+	Import        Import
+	Site          Site
+	Languages     Languages
+	Comments      Comments
+	Filter        Filter
+	Reports       Reports
 }
 
 type PostgresConfig struct {
@@ -34,11 +46,110 @@ type Redis struct {
 	Addr string
 }
 
+type Trash struct {
+	// RetentionDays is how long deleted items stay in the trash.
+	RetentionDays int
+}
+
+type Views struct {
+	// Window is how long repeated views of a post by the same viewer are
+	// counted once.
+	Window time.Duration
+	// FlushInterval is how often buffered views are written to Postgres.
+	FlushInterval time.Duration
+}
+
+type Stats struct {
+	// RollupInterval is how often the daily post stats are recomputed.
+	RollupInterval time.Duration
+	// RankingsInterval is how often trending and top scores are recomputed.
+	RankingsInterval time.Duration
+}
+
+type Import struct {
+	// Dir is where uploaded WordPress exports wait for the worker.
+	Dir string
+	// Interval is how often the worker looks for imports to run.
+	Interval time.Duration
+}
+
+type Site struct {
+	// URL is the public address of the blog, used for links in feeds.
+	URL   string
+	Title string
+	// FeedCacheDuration is how long generated feeds are cached.
+	FeedCacheDuration time.Duration
+	// SitemapCacheDuration bounds how long a sitemap chunk is cached when
+	// nothing invalidates it, e.g. after a background import.
+	SitemapCacheDuration time.Duration
+	// RobotsDisallow lists the paths robots.txt keeps crawlers out of.
+	RobotsDisallow []string
+}
+
+type Languages struct {
+	// Supported are the languages posts are written and translated in.
+	Supported []string
+	// Default is the language readers fall back to, the first supported
+	// one.
+	Default string
+}
+
+type Comments struct {
+	// MaxDepth is how deep replies can be nested, top level comments
+	// being at depth 0.
+	MaxDepth int32
+	// EditWindow is how long authors can edit their comments after
+	// posting them, zero for no limit. Admins can always edit.
+	EditWindow time.Duration
+}
+
+// Filter configures the content filter of comments and posts.
+type Filter struct {
+	// HoldWords send content for moderation, RejectWords reject it.
+	HoldWords   []string
+	RejectWords []string
+	// CommentMaxLinks and PostMaxLinks are how many links can be posted
+	// before the content is held.
+	CommentMaxLinks int
+	PostMaxLinks    int
+	// DuplicateWindow is how long back duplicate content is looked for.
+	DuplicateWindow time.Duration
+	// NewAccountAge is how old an account has to be to post links without
+	// them being held.
+	NewAccountAge time.Duration
+}
+
+type Reports struct {
+	// HideThreshold is how many users have to report content for it to
+	// be hidden until a moderator looks at it.
+	HideThreshold int64
+}
+
 func Load(path string) Config {
 	godotenv.Load(path + "/.env")
 
 	conf := viper.New()
 	conf.AutomaticEnv()
+	conf.SetDefault("TRASH_RETENTION_DAYS", 30)
+	conf.SetDefault("VIEWS_WINDOW", "30m")
+	conf.SetDefault("VIEWS_FLUSH_INTERVAL", "10s")
+	conf.SetDefault("STATS_ROLLUP_INTERVAL", "15m")
+	conf.SetDefault("RANKINGS_REFRESH_INTERVAL", "5m")
+	conf.SetDefault("IMPORT_DIR", "imports")
+	conf.SetDefault("IMPORT_INTERVAL", "10s")
+	conf.SetDefault("SITE_URL", "http://localhost:8080")
+	conf.SetDefault("SITE_TITLE", "Blog")
+	conf.SetDefault("FEED_CACHE_DURATION", "5m")
+	conf.SetDefault("SITEMAP_CACHE_DURATION", "1h")
+	conf.SetDefault("ROBOTS_DISALLOW", "/v1/auth/,/swagger/")
+	conf.SetDefault("LANGUAGES", "uz,ru,en")
+	conf.SetDefault("COMMENTS_MAX_DEPTH", 5)
+	conf.SetDefault("COMMENTS_EDIT_WINDOW", "15m")
+	conf.SetDefault("FILTER_COMMENT_MAX_LINKS", 2)
+	conf.SetDefault("FILTER_POST_MAX_LINKS", 30)
+	conf.SetDefault("FILTER_DUPLICATE_WINDOW", "24h")
+	conf.SetDefault("FILTER_NEW_ACCOUNT_AGE", "24h")
+	conf.SetDefault("REPORTS_HIDE_THRESHOLD", 5)
 
 	cfg := Config{
 		HttpPort: conf.GetString("HTTP_PORT"),
@@ -59,6 +170,60 @@ func Load(path string) Config {
 		Redis: Redis{
 			Addr: conf.GetString("REDIS_ADDR"),
 		},
+		Trash: Trash{
+			RetentionDays: conf.GetInt("TRASH_RETENTION_DAYS"),
+		},
+		Views: Views{
+			Window:        conf.GetDuration("VIEWS_WINDOW"),
+			FlushInterval: conf.GetDuration("VIEWS_FLUSH_INTERVAL"),
+		},
+		Stats: Stats{
+			RollupInterval:   conf.GetDuration("STATS_ROLLUP_INTERVAL"),
+			RankingsInterval: conf.GetDuration("RANKINGS_REFRESH_INTERVAL"),
+		},
+		Import: Import{
+			Dir:      conf.GetString("IMPORT_DIR"),
+			Interval: conf.GetDuration("IMPORT_INTERVAL"),
+		},
+		Site: Site{
+			URL:                  strings.TrimSuffix(conf.GetString("SITE_URL"), "/"),
+			Title:                conf.GetString("SITE_TITLE"),
+			FeedCacheDuration:    conf.GetDuration("FEED_CACHE_DURATION"),
+			SitemapCacheDuration: conf.GetDuration("SITEMAP_CACHE_DURATION"),
+			RobotsDisallow:       splitList(conf.GetString("ROBOTS_DISALLOW")),
+		},
+		Languages: Languages{
+			Supported: splitList(strings.ToLower(conf.GetString("LANGUAGES"))),
+		},
+		Comments: Comments{
+			MaxDepth:   conf.GetInt32("COMMENTS_MAX_DEPTH"),
+			EditWindow: conf.GetDuration("COMMENTS_EDIT_WINDOW"),
+		},
+		Filter: Filter{
+			HoldWords:       splitList(strings.ToLower(conf.GetString("FILTER_HOLD_WORDS"))),
+			RejectWords:     splitList(strings.ToLower(conf.GetString("FILTER_REJECT_WORDS"))),
+			CommentMaxLinks: conf.GetInt("FILTER_COMMENT_MAX_LINKS"),
+			PostMaxLinks:    conf.GetInt("FILTER_POST_MAX_LINKS"),
+			DuplicateWindow: conf.GetDuration("FILTER_DUPLICATE_WINDOW"),
+			NewAccountAge:   conf.GetDuration("FILTER_NEW_ACCOUNT_AGE"),
+		},
+		Reports: Reports{
+			HideThreshold: conf.GetInt64("REPORTS_HIDE_THRESHOLD"),
+		},
+	}
+	if len(cfg.Languages.Supported) > 0 {
+		cfg.Languages.Default = cfg.Languages.Supported[0]
 	}
 	return cfg
 }
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(s string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS likes_user_id_idx;
+DROP INDEX IF EXISTS posts_title_trgm_idx;
+DROP TABLE IF EXISTS "post_tags";
+DROP TABLE IF EXISTS "tags";
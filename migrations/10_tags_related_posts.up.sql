@@ -0,0 +1,17 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS "tags"(
+    "id" SERIAL PRIMARY KEY,
+    "name" VARCHAR(50) NOT NULL UNIQUE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "post_tags"(
+    "post_id" INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    "tag_id" INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY ("post_id", "tag_id")
+);
+CREATE INDEX IF NOT EXISTS post_tags_tag_id_idx ON post_tags(tag_id);
+
+CREATE INDEX IF NOT EXISTS posts_title_trgm_idx ON posts USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS likes_user_id_idx ON likes(user_id) WHERE status;
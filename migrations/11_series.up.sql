@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS "series"(
+    "id" SERIAL PRIMARY KEY,
+    "title" VARCHAR NOT NULL,
+    "description" TEXT NOT NULL DEFAULT '',
+    "user_id" INTEGER NOT NULL REFERENCES users(id),
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS series_user_id_idx ON series(user_id);
+
+-- a post belongs to at most one series
+CREATE TABLE IF NOT EXISTS "series_posts"(
+    "series_id" INTEGER NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+    "post_id" INTEGER NOT NULL UNIQUE REFERENCES posts(id) ON DELETE CASCADE,
+    "position" INTEGER NOT NULL,
+    PRIMARY KEY ("series_id", "post_id"),
+    UNIQUE ("series_id", "position")
+);
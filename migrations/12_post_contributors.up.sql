@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "post_contributors"(
+    "post_id" INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    "user_id" INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "role" VARCHAR(20) NOT NULL CHECK ("role" IN('author', 'co-author', 'editor')),
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("post_id", "user_id")
+);
+CREATE INDEX IF NOT EXISTS post_contributors_user_id_idx ON post_contributors(user_id);
+
+-- the author is posts.user_id, there is exactly one per post
+CREATE UNIQUE INDEX IF NOT EXISTS post_contributors_author_idx ON post_contributors(post_id) WHERE role = 'author';
+
+INSERT INTO post_contributors(post_id, user_id, role, created_at)
+SELECT id, user_id, 'author', created_at FROM posts
+ON CONFLICT DO NOTHING;
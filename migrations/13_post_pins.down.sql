@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "featured_posts";
+DROP TABLE IF EXISTS "post_pins";
@@ -0,0 +1,15 @@
+-- a pin without a category is global
+CREATE TABLE IF NOT EXISTS "post_pins"(
+    "post_id" INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    "category_id" INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    "position" INTEGER NOT NULL DEFAULT 0,
+    "expires_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS post_pins_post_category_idx ON post_pins(post_id, coalesce(category_id, 0));
+
+CREATE TABLE IF NOT EXISTS "featured_posts"(
+    "post_id" INTEGER PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    "position" INTEGER NOT NULL UNIQUE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
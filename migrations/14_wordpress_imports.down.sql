@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "wordpress_items";
+DROP TABLE IF EXISTS "wordpress_import_errors";
+DROP TABLE IF EXISTS "wordpress_imports";
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS "wordpress_imports"(
+    "id" SERIAL PRIMARY KEY,
+    "user_id" INTEGER NOT NULL REFERENCES users(id),
+    "file_name" VARCHAR NOT NULL,
+    "status" VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK ("status" IN('pending', 'running', 'done', 'failed')),
+    "total" INTEGER NOT NULL DEFAULT 0,
+    "processed" INTEGER NOT NULL DEFAULT 0,
+    "failed" INTEGER NOT NULL DEFAULT 0,
+    "error" TEXT,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE,
+    "finished_at" TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS "wordpress_import_errors"(
+    "import_id" INTEGER NOT NULL REFERENCES wordpress_imports(id) ON DELETE CASCADE,
+    "kind" VARCHAR(20) NOT NULL,
+    "wp_id" VARCHAR NOT NULL,
+    "error" TEXT NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS wordpress_import_errors_import_id_idx ON wordpress_import_errors(import_id);
+
+-- what every WordPress item of a site was imported as, so that running
+-- the same export again skips it
+CREATE TABLE IF NOT EXISTS "wordpress_items"(
+    "site" VARCHAR NOT NULL,
+    "kind" VARCHAR(20) NOT NULL,
+    "wp_id" VARCHAR NOT NULL,
+    "local_id" INTEGER NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("site", "kind", "wp_id")
+);
@@ -0,0 +1,9 @@
+-- overrides of the metadata derived from a post, null means derived
+CREATE TABLE IF NOT EXISTS "post_seo"(
+    "post_id" INTEGER PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    "meta_title" VARCHAR(100),
+    "meta_description" VARCHAR(300),
+    "canonical_url" VARCHAR,
+    "image_url" VARCHAR,
+    "updated_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,16 @@
+ALTER TABLE "posts" ADD COLUMN IF NOT EXISTS "language" VARCHAR(8) NOT NULL DEFAULT 'uz';
+
+CREATE TABLE IF NOT EXISTS "post_translations"(
+    "post_id" INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    "language" VARCHAR(8) NOT NULL,
+    "title" VARCHAR NOT NULL,
+    "slug" VARCHAR NOT NULL,
+    "description" TEXT NOT NULL,
+    "description_html" TEXT NOT NULL DEFAULT '',
+    "table_of_contents" JSONB NOT NULL DEFAULT '[]',
+    "reading_time" INTEGER NOT NULL DEFAULT 0,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY ("post_id", "language")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS post_translations_language_slug_idx ON post_translations(language, slug);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS comments_path_idx;
+ALTER TABLE "comments" DROP COLUMN IF EXISTS "path", DROP COLUMN IF EXISTS "depth";
+
+DROP INDEX IF EXISTS comments_parent_id_idx;
+ALTER TABLE "comments" DROP COLUMN IF EXISTS "parent_id";
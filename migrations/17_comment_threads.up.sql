@@ -0,0 +1,16 @@
+ALTER TABLE "comments" ADD COLUMN IF NOT EXISTS "parent_id" INTEGER REFERENCES comments(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON comments(parent_id);
+
+-- path holds the ids from the root of the thread down to the comment itself
+ALTER TABLE "comments"
+    ADD COLUMN IF NOT EXISTS "depth" INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "path" INTEGER[] NOT NULL DEFAULT '{}';
+
+WITH RECURSIVE tree AS (
+    SELECT id, 0 AS depth, ARRAY[id] AS path FROM comments WHERE parent_id IS NULL
+    UNION ALL
+    SELECT c.id, t.depth + 1, t.path || c.id FROM comments c INNER JOIN tree t ON c.parent_id = t.id
+)
+UPDATE comments c SET depth = tree.depth, path = tree.path FROM tree WHERE tree.id = c.id;
+
+CREATE INDEX IF NOT EXISTS comments_path_idx ON comments USING GIN(path);
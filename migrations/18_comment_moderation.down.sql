@@ -0,0 +1,6 @@
+ALTER TABLE "posts" DROP COLUMN IF EXISTS "comment_status";
+DROP INDEX IF EXISTS comments_status_idx;
+ALTER TABLE "comments"
+    DROP COLUMN IF EXISTS "moderated_at",
+    DROP COLUMN IF EXISTS "moderated_by",
+    DROP COLUMN IF EXISTS "status";
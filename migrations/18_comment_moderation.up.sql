@@ -0,0 +1,9 @@
+ALTER TABLE "comments"
+    ADD COLUMN IF NOT EXISTS "status" VARCHAR(20) NOT NULL DEFAULT 'approved'
+        CHECK ("status" IN('pending', 'approved', 'rejected', 'spam')),
+    ADD COLUMN IF NOT EXISTS "moderated_by" INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS "moderated_at" TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS comments_status_idx ON comments(status) WHERE status <> 'approved';
+
+ALTER TABLE "posts" ADD COLUMN IF NOT EXISTS "comment_status" VARCHAR(20) NOT NULL DEFAULT 'open'
+    CHECK ("comment_status" IN('open', 'closed', 'moderated', 'first_time_moderated'));
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "filter_outcomes"(
+    "id" SERIAL PRIMARY KEY,
+    "target_type" VARCHAR(20) NOT NULL CHECK ("target_type" IN('comment', 'post')),
+    -- rejected content has no id
+    "target_id" INTEGER,
+    "user_id" INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "action" VARCHAR(20) NOT NULL CHECK ("action" IN('allow', 'hold', 'reject')),
+    "reasons" TEXT[] NOT NULL DEFAULT '{}',
+    "content_hash" VARCHAR(64) NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS filter_outcomes_content_hash_idx ON filter_outcomes(content_hash, created_at);
+CREATE INDEX IF NOT EXISTS filter_outcomes_target_idx ON filter_outcomes(target_type, target_id);
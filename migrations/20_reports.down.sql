@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "report_flags";
+DROP TABLE IF EXISTS "reports";
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS "reports"(
+    "id" SERIAL PRIMARY KEY,
+    "target_type" VARCHAR(20) NOT NULL CHECK ("target_type" IN('post', 'comment', 'user')),
+    "target_id" INTEGER NOT NULL,
+    "status" VARCHAR(20) NOT NULL DEFAULT 'open' CHECK ("status" IN('open', 'resolved', 'dismissed')),
+    "flags_count" INTEGER NOT NULL DEFAULT 0,
+    -- hidden is set while the report keeps the target hidden, previous_state
+    -- is the visibility or status to give it back when it is shown again
+    "hidden" BOOLEAN NOT NULL DEFAULT FALSE,
+    "previous_state" VARCHAR(20),
+    "resolution" TEXT,
+    "resolved_by" INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    "resolved_at" TIMESTAMP WITH TIME ZONE,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP WITH TIME ZONE
+);
+-- reports of the same target are merged while open
+CREATE UNIQUE INDEX IF NOT EXISTS reports_open_target_idx ON reports(target_type, target_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS reports_hidden_target_idx ON reports(target_type, target_id) WHERE hidden;
+
+CREATE TABLE IF NOT EXISTS "report_flags"(
+    "report_id" INTEGER NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+    "user_id" INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "reason" VARCHAR(20) NOT NULL
+        CHECK ("reason" IN('spam', 'harassment', 'hate', 'violence', 'sexual', 'misinformation', 'other')),
+    "details" TEXT,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("report_id", "user_id")
+);
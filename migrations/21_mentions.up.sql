@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "mentions"(
+    "target_type" VARCHAR(20) NOT NULL CHECK ("target_type" IN('comment', 'post')),
+    "target_id" INTEGER NOT NULL,
+    "user_id" INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "mentioned_by" INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    -- notified_at is set once the mentioned user was told, which waits
+    -- until the content can be seen
+    "notified_at" TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY ("target_type", "target_id", "user_id")
+);
+CREATE INDEX IF NOT EXISTS mentions_user_id_idx ON mentions(user_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "comment_revisions";
+ALTER TABLE "comments" DROP COLUMN IF EXISTS "edit_count";
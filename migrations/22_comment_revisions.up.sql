@@ -0,0 +1,13 @@
+ALTER TABLE "comments" ADD COLUMN IF NOT EXISTS "edit_count" INTEGER NOT NULL DEFAULT 0;
+
+-- comment_revisions keeps what a comment said before each edit, with who
+-- edited it and when
+CREATE TABLE IF NOT EXISTS "comment_revisions"(
+    "id" SERIAL PRIMARY KEY,
+    "comment_id" INTEGER NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    "description" TEXT NOT NULL,
+    "written_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    "edited_by" INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    "edited_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS comment_revisions_comment_id_idx ON comment_revisions(comment_id);
@@ -0,0 +1,33 @@
+-- only approved comments count towards the rankings
+DROP MATERIALIZED VIEW IF EXISTS post_rankings;
+
+CREATE MATERIALIZED VIEW post_rankings AS
+WITH totals AS (
+    SELECT
+        p.id,
+        p.created_at,
+        p.views_count
+            + 3 * (SELECT count(1) FROM likes l WHERE l.post_id = p.id AND l.status)
+            + 5 * (SELECT count(1) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL AND c.status = 'approved') AS score
+    FROM posts p
+    WHERE p.deleted_at IS NULL
+), recent AS (
+    SELECT
+        s.post_id,
+        sum(s.views + 3 * s.likes + 5 * s.comments) FILTER (WHERE s.day > current_date - 7) AS week,
+        sum(s.views + 3 * s.likes + 5 * s.comments) AS month
+    FROM post_daily_stats s
+    WHERE s.day > current_date - 30
+    GROUP BY s.post_id
+)
+SELECT
+    t.id AS post_id,
+    (t.score / power(extract(EPOCH FROM now() - t.created_at) / 3600 + 2, 1.5))::DOUBLE PRECISION AS trending,
+    coalesce(r.week, 0)::BIGINT AS top_week,
+    coalesce(r.month, 0)::BIGINT AS top_month,
+    t.score::BIGINT AS top_all
+FROM totals t
+LEFT JOIN recent r ON r.post_id = t.id;
+
+-- needed to refresh the view concurrently
+CREATE UNIQUE INDEX IF NOT EXISTS post_rankings_post_id_idx ON post_rankings(post_id);
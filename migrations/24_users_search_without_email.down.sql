@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS users_search_idx;
+
+CREATE INDEX IF NOT EXISTS users_search_idx ON users USING GIN(
+    to_tsvector('simple', first_name || ' ' || last_name || ' ' || coalesce(username, '') || ' ' || email)
+);
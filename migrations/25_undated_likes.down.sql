@@ -0,0 +1 @@
+-- the likes can't be dated again, they stay out of the daily stats
@@ -0,0 +1,27 @@
+-- migration 8 dated the likes it found with the time it ran, one timestamp
+-- shared by all of them. They are undated instead and taken back out of the
+-- daily stats of that day, the rollup counting likes by when they were made
+WITH backfill AS (
+    SELECT created_at FROM likes
+    WHERE created_at = (SELECT min(created_at) FROM likes)
+    GROUP BY created_at
+    HAVING count(1) > 1
+), undated AS (
+    UPDATE likes l SET created_at = NULL
+    FROM backfill b
+    WHERE l.created_at = b.created_at
+    RETURNING l.post_id, l.status, (b.created_at AT TIME ZONE 'UTC')::date AS day
+)
+UPDATE post_daily_stats s SET
+    likes = greatest(s.likes - u.likes, 0),
+    dislikes = greatest(s.dislikes - u.dislikes, 0)
+FROM (
+    SELECT
+        post_id,
+        day,
+        count(1) FILTER (WHERE status) AS likes,
+        count(1) FILTER (WHERE NOT status) AS dislikes
+    FROM undated
+    GROUP BY post_id, day
+) u
+WHERE s.post_id = u.post_id AND s.day = u.day;
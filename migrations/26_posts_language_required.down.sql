@@ -0,0 +1 @@
+ALTER TABLE "posts" ALTER COLUMN "language" SET DEFAULT 'uz';
@@ -0,0 +1,3 @@
+-- posts are always created with a language, the configured default one
+-- when none is given
+ALTER TABLE "posts" ALTER COLUMN "language" DROP DEFAULT;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS categories_search_idx;
+DROP INDEX IF EXISTS users_search_idx;
+DROP INDEX IF EXISTS posts_search_vector_idx;
+DROP TRIGGER IF EXISTS posts_search_vector_trigger ON posts;
+DROP FUNCTION IF EXISTS posts_search_vector_update;
+ALTER TABLE "posts" DROP COLUMN IF EXISTS "search_vector";
@@ -0,0 +1,26 @@
+ALTER TABLE "posts" ADD COLUMN IF NOT EXISTS "search_vector" TSVECTOR;
+
+CREATE OR REPLACE FUNCTION posts_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(NEW.description, '')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER posts_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, description ON posts
+    FOR EACH ROW EXECUTE FUNCTION posts_search_vector_update();
+
+UPDATE posts SET search_vector =
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B');
+
+CREATE INDEX IF NOT EXISTS posts_search_vector_idx ON posts USING GIN(search_vector);
+
+CREATE INDEX IF NOT EXISTS users_search_idx ON users USING GIN(
+    to_tsvector('simple', first_name || ' ' || last_name || ' ' || coalesce(username, '') || ' ' || email)
+);
+
+CREATE INDEX IF NOT EXISTS categories_search_idx ON categories USING GIN(to_tsvector('simple', title));
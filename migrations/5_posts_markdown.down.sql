@@ -0,0 +1,4 @@
+ALTER TABLE "posts"
+    DROP COLUMN IF EXISTS "description_html",
+    DROP COLUMN IF EXISTS "table_of_contents",
+    DROP COLUMN IF EXISTS "reading_time";
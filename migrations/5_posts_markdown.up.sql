@@ -0,0 +1,9 @@
+ALTER TABLE "posts"
+    ADD COLUMN IF NOT EXISTS "description_html" TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "table_of_contents" JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS "reading_time" INTEGER NOT NULL DEFAULT 0;
+
+-- descriptions written before Markdown support are plain text
+UPDATE posts SET
+    description_html = '<p>' || replace(replace(replace(description, '&', '&amp;'), '<', '&lt;'), '>', '&gt;') || '</p>',
+    reading_time = ceil(array_length(regexp_split_to_array(trim(description), '\s+'), 1) / 200.0);
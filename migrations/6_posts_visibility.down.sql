@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS posts_visibility_idx;
+ALTER TABLE "posts" DROP CONSTRAINT IF EXISTS posts_password_check;
+ALTER TABLE "posts"
+    DROP COLUMN IF EXISTS "visibility",
+    DROP COLUMN IF EXISTS "password";
@@ -0,0 +1,9 @@
+ALTER TABLE "posts"
+    ADD COLUMN IF NOT EXISTS "visibility" VARCHAR(20) NOT NULL DEFAULT 'public'
+        CHECK ("visibility" IN('public', 'unlisted', 'private', 'password')),
+    ADD COLUMN IF NOT EXISTS "password" VARCHAR;
+
+ALTER TABLE "posts" ADD CONSTRAINT posts_password_check
+    CHECK ("visibility" <> 'password' OR "password" IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS posts_visibility_idx ON posts(visibility);
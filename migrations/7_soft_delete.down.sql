@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS categories_deleted_at_idx;
+DROP INDEX IF EXISTS comments_deleted_at_idx;
+DROP INDEX IF EXISTS posts_deleted_at_idx;
+
+DROP INDEX IF EXISTS categories_title_key;
+ALTER TABLE "categories" ADD CONSTRAINT categories_title_key UNIQUE ("title");
+
+ALTER TABLE "categories" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "comments" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "posts" DROP COLUMN IF EXISTS "deleted_at";
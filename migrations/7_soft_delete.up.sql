@@ -0,0 +1,11 @@
+ALTER TABLE "posts" ADD COLUMN IF NOT EXISTS "deleted_at" TIMESTAMP WITH TIME ZONE;
+ALTER TABLE "comments" ADD COLUMN IF NOT EXISTS "deleted_at" TIMESTAMP WITH TIME ZONE;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "deleted_at" TIMESTAMP WITH TIME ZONE;
+
+-- a deleted category must not block creating a new one with the same title
+ALTER TABLE "categories" DROP CONSTRAINT IF EXISTS categories_title_key;
+CREATE UNIQUE INDEX IF NOT EXISTS categories_title_key ON categories(title) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS posts_deleted_at_idx ON posts(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS comments_deleted_at_idx ON comments(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS categories_deleted_at_idx ON categories(deleted_at) WHERE deleted_at IS NOT NULL;
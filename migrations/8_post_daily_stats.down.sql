@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS comments_created_at_idx;
+DROP INDEX IF EXISTS likes_created_at_idx;
+DROP TABLE IF EXISTS "post_daily_stats";
+ALTER TABLE "likes" DROP COLUMN IF EXISTS "created_at";
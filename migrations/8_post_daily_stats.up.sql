@@ -0,0 +1,16 @@
+ALTER TABLE "likes" ADD COLUMN IF NOT EXISTS "created_at" TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS "post_daily_stats"(
+    "post_id" INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    "day" DATE NOT NULL,
+    "views" INTEGER NOT NULL DEFAULT 0,
+    "unique_viewers" INTEGER NOT NULL DEFAULT 0,
+    "likes" INTEGER NOT NULL DEFAULT 0,
+    "dislikes" INTEGER NOT NULL DEFAULT 0,
+    "comments" INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY ("post_id", "day")
+);
+CREATE INDEX IF NOT EXISTS post_daily_stats_day_idx ON post_daily_stats(day);
+
+CREATE INDEX IF NOT EXISTS likes_created_at_idx ON likes(created_at);
+CREATE INDEX IF NOT EXISTS comments_created_at_idx ON comments(created_at);
@@ -19,6 +19,8 @@ type SendEmailRequest struct {
 const (
 	VerificationEmail   = "verification_email"
 	ForgotPasswordEmail = "forgot_password_email"
+	ReportOutcomeEmail  = "report_outcome_email"
+	MentionEmail        = "mention_email"
 )
 
 func SendEmail(cfg *config.Config, req *SendEmailRequest) error {
@@ -54,6 +56,10 @@ func getTemplatePath(emailType string) string {
 		return "./templates/verification_email.html"
 	case ForgotPasswordEmail:
 		return "./templates/forgot_password_email.html"
+	case ReportOutcomeEmail:
+		return "./templates/report_outcome_email.html"
+	case MentionEmail:
+		return "./templates/mention_email.html"
 	}
 	return ""
 }
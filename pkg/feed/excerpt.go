@@ -0,0 +1,28 @@
+package feed
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+var strict = bluemonday.StrictPolicy()
+
+// Excerpt returns the text of an HTML document cut to at most max
+// characters at a word boundary. An ellipsis marks a cut text.
+func Excerpt(content string, max int) string {
+	text := html.UnescapeString(strict.Sanitize(content))
+	text = strings.Join(strings.Fields(text), " ")
+
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+
+	runes := []rune(text)[:max]
+	if i := strings.LastIndexByte(string(runes), ' '); i > 0 {
+		return string(runes)[:i] + "…"
+	}
+	return string(runes) + "…"
+}
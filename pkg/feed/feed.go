@@ -0,0 +1,255 @@
+// Package feed writes RSS 2.0, Atom and JSON Feed documents.
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Feed is the format independent content of a feed.
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the page the feed is about, FeedURL the feed itself.
+	Link    string
+	FeedURL string
+	Updated time.Time
+	Items   []*Item
+}
+
+// Item is a feed entry. Content is HTML and may be empty when only the
+// plain text Summary is published.
+type Item struct {
+	ID         string
+	Title      string
+	Link       string
+	Author     string
+	Categories []string
+	Summary    string
+	Content    string
+	Image      *string
+	Published  time.Time
+	Updated    time.Time
+}
+
+type rss struct {
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	AtomNS       string     `xml:"xmlns:atom,attr"`
+	ContentNS    string     `xml:"xmlns:content,attr"`
+	DublinCoreNS string     `xml:"xmlns:dc,attr"`
+	Channel      rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Self          atomLink   `xml:"atom:link"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+	Content     *cdata   `xml:"content:encoded,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+// RSS writes the feed as RSS 2.0.
+func RSS(f *Feed) ([]byte, error) {
+	doc := rss{
+		Version:      "2.0",
+		AtomNS:       "http://www.w3.org/2005/Atom",
+		ContentNS:    "http://purl.org/rss/1.0/modules/content/",
+		DublinCoreNS: "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   f.Description,
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+			Self:          atomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+			Items:         make([]*rssItem, 0, len(f.Items)),
+		},
+	}
+
+	for _, i := range f.Items {
+		item := rssItem{
+			Title:       i.Title,
+			Link:        i.Link,
+			GUID:        rssGUID{IsPermaLink: i.ID == i.Link, Value: i.ID},
+			PubDate:     i.Published.UTC().Format(time.RFC1123Z),
+			Creator:     i.Author,
+			Categories:  i.Categories,
+			Description: i.Summary,
+		}
+		if i.Content != "" {
+			item.Content = &cdata{Value: i.Content}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, &item)
+	}
+
+	return marshalXML(doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"feed"`
+	NS      string       `xml:"xmlns,attr"`
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Links   []atomLink   `xml:"link"`
+	Updated string       `xml:"updated"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Atom writes the feed as Atom 1.0.
+func Atom(f *Feed) ([]byte, error) {
+	doc := atomFeed{
+		NS:    "http://www.w3.org/2005/Atom",
+		Title: f.Title,
+		ID:    f.FeedURL,
+		Links: []atomLink{
+			{Href: f.Link},
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Entries: make([]*atomEntry, 0, len(f.Items)),
+	}
+
+	for _, i := range f.Items {
+		entry := atomEntry{
+			Title:     i.Title,
+			ID:        i.ID,
+			Link:      atomLink{Href: i.Link},
+			Published: i.Published.UTC().Format(time.RFC3339),
+			Updated:   i.Updated.UTC().Format(time.RFC3339),
+		}
+		if i.Author != "" {
+			entry.Author = &atomPerson{Name: i.Author}
+		}
+		for _, c := range i.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+		if i.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: i.Summary}
+		}
+		if i.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: i.Content}
+		}
+		doc.Entries = append(doc.Entries, &entry)
+	}
+
+	return marshalXML(doc)
+}
+
+func marshalXML(doc interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+type jsonFeed struct {
+	Version     string      `json:"version"`
+	Title       string      `json:"title"`
+	HomePageURL string      `json:"home_page_url"`
+	FeedURL     string      `json:"feed_url"`
+	Description string      `json:"description,omitempty"`
+	Items       []*jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string        `json:"id"`
+	URL           string        `json:"url"`
+	Title         string        `json:"title"`
+	ContentHTML   string        `json:"content_html,omitempty"`
+	Summary       string        `json:"summary,omitempty"`
+	Image         *string       `json:"image,omitempty"`
+	DatePublished time.Time     `json:"date_published"`
+	DateModified  time.Time     `json:"date_modified"`
+	Authors       []*jsonAuthor `json:"authors,omitempty"`
+	Tags          []string      `json:"tags,omitempty"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+// JSON writes the feed as JSON Feed 1.1.
+func JSON(f *Feed) ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       make([]*jsonItem, 0, len(f.Items)),
+	}
+
+	for _, i := range f.Items {
+		item := jsonItem{
+			ID:            i.ID,
+			URL:           i.Link,
+			Title:         i.Title,
+			ContentHTML:   i.Content,
+			Summary:       i.Summary,
+			Image:         i.Image,
+			DatePublished: i.Published.UTC(),
+			DateModified:  i.Updated.UTC(),
+			Tags:          i.Categories,
+		}
+		if i.Author != "" {
+			item.Authors = []*jsonAuthor{{Name: i.Author}}
+		}
+		doc.Items = append(doc.Items, &item)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
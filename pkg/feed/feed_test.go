@@ -0,0 +1,108 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC)
+	return &Feed{
+		Title:       "Blog",
+		Description: "Latest posts",
+		Link:        "https://example.com",
+		FeedURL:     "https://example.com/v1/feeds/posts.rss",
+		Updated:     published.Add(time.Hour),
+		Items: []*Item{
+			{
+				ID:         "https://example.com/posts/1",
+				Title:      "Hello <world>",
+				Link:       "https://example.com/posts/1",
+				Author:     "John Doe",
+				Categories: []string{"go", "web"},
+				Summary:    "Hello world",
+				Content:    "<p>Hello <b>world</b></p>",
+				Published:  published,
+				Updated:    published.Add(time.Hour),
+			},
+		},
+	}
+}
+
+func TestRSS(t *testing.T) {
+	data, err := RSS(testFeed())
+	require.NoError(t, err)
+
+	var doc struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title      string   `xml:"title"`
+				GUID       string   `xml:"guid"`
+				PubDate    string   `xml:"pubDate"`
+				Categories []string `xml:"category"`
+				Content    string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	require.Equal(t, "Blog", doc.Channel.Title)
+	require.Len(t, doc.Channel.Items, 1)
+
+	item := doc.Channel.Items[0]
+	require.Equal(t, "Hello <world>", item.Title)
+	require.Equal(t, "https://example.com/posts/1", item.GUID)
+	require.Equal(t, "Tue, 01 Nov 2022 10:00:00 +0000", item.PubDate)
+	require.Equal(t, []string{"go", "web"}, item.Categories)
+	require.Equal(t, "<p>Hello <b>world</b></p>", item.Content)
+}
+
+func TestAtom(t *testing.T) {
+	f := testFeed()
+	f.Items[0].Content = ""
+
+	data, err := Atom(f)
+	require.NoError(t, err)
+
+	var doc struct {
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID      string  `xml:"id"`
+			Author  string  `xml:"author>name"`
+			Summary string  `xml:"summary"`
+			Content *string `xml:"content"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	require.Equal(t, "2022-11-01T11:00:00Z", doc.Updated)
+	require.Len(t, doc.Entries, 1)
+	require.Equal(t, "John Doe", doc.Entries[0].Author)
+	require.Equal(t, "Hello world", doc.Entries[0].Summary)
+	require.Nil(t, doc.Entries[0].Content)
+}
+
+func TestJSON(t *testing.T) {
+	data, err := JSON(testFeed())
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Equal(t, "https://jsonfeed.org/version/1.1", doc["version"])
+
+	items := doc["items"].([]interface{})
+	require.Len(t, items, 1)
+	item := items[0].(map[string]interface{})
+	require.Equal(t, "<p>Hello <b>world</b></p>", item["content_html"])
+	require.Equal(t, "2022-11-01T10:00:00Z", item["date_published"])
+}
+
+func TestExcerpt(t *testing.T) {
+	require.Equal(t, "Hello world & friends", Excerpt("<p>Hello <b>world</b></p>\n<p>&amp; friends</p>", 100))
+	require.Equal(t, "Hello…", Excerpt("<p>Hello wonderful world</p>", 10))
+	require.Equal(t, "Helloworld…", Excerpt("Helloworldwide", 10))
+	require.Equal(t, "Привет…", Excerpt("<p>Привет мир</p>", 8))
+}
@@ -0,0 +1,136 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BannedWords holds content containing one of the Hold words or phrases
+// and rejects content containing one of the Reject ones. Words are
+// matched whole, regardless of case and punctuation.
+type BannedWords struct {
+	Hold   []string
+	Reject []string
+}
+
+func (b *BannedWords) Check(c *Content) (*Result, error) {
+	text := " " + strings.Join(c.Words(), " ") + " "
+
+	var res Result
+	for _, list := range []struct {
+		action Action
+		words  []string
+	}{
+		{Reject, b.Reject},
+		{Hold, b.Hold},
+	} {
+		for _, word := range list.words {
+			phrase := strings.Join(words(word), " ")
+			if phrase != "" && strings.Contains(text, " "+phrase+" ") {
+				res.merge(&Result{
+					Action:  list.action,
+					Reasons: []string{fmt.Sprintf("banned word %q", word)},
+				})
+			}
+		}
+	}
+
+	if len(res.Reasons) == 0 {
+		return nil, nil
+	}
+	return &res, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://|\bwww\.`)
+
+// CountLinks counts the links in a text, bare or in Markdown.
+func CountLinks(text string) int {
+	return len(linkPattern.FindAllStringIndex(text, -1))
+}
+
+// LinkLimit holds content with more than Max links.
+type LinkLimit struct {
+	Max int
+}
+
+func (l *LinkLimit) Check(c *Content) (*Result, error) {
+	n := CountLinks(c.Text)
+	if n <= l.Max {
+		return nil, nil
+	}
+
+	return &Result{
+		Action:  Hold,
+		Reasons: []string{fmt.Sprintf("%d links, at most %d allowed", n, l.Max)},
+	}, nil
+}
+
+// Duplicates rejects content the same user already posted and holds
+// content posted by more than MaxOthers other users, which is how spam
+// campaigns look. Texts shorter than MinWords are never duplicates.
+type Duplicates struct {
+	// Count counts the earlier content with the same hash, by the user
+	// and by others, leaving out c itself.
+	Count     func(c *Content) (own, others int64, err error)
+	MinWords  int
+	MaxOthers int64
+}
+
+func (d *Duplicates) Check(c *Content) (*Result, error) {
+	if len(c.Words()) < d.MinWords {
+		return nil, nil
+	}
+
+	own, others, err := d.Count(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Result
+	if own > 0 {
+		res.merge(&Result{
+			Action:  Reject,
+			Reasons: []string{"duplicate of an earlier " + c.Kind},
+		})
+	}
+	if others > d.MaxOthers {
+		res.merge(&Result{
+			Action:  Hold,
+			Reasons: []string{fmt.Sprintf("posted by %d other users", others)},
+		})
+	}
+
+	if len(res.Reasons) == 0 {
+		return nil, nil
+	}
+	return &res, nil
+}
+
+// NewAccount holds links from accounts younger than MinAge, the usual
+// shape of link spam.
+type NewAccount struct {
+	MinAge time.Duration
+	// Now defaults to time.Now.
+	Now func() time.Time
+}
+
+func (n *NewAccount) Check(c *Content) (*Result, error) {
+	now := time.Now
+	if n.Now != nil {
+		now = n.Now
+	}
+
+	if c.UserCreatedAt.IsZero() || now().Sub(c.UserCreatedAt) >= n.MinAge {
+		return nil, nil
+	}
+	if CountLinks(c.Text) == 0 {
+		return nil, nil
+	}
+
+	return &Result{
+		Action:  Hold,
+		Reasons: []string{"links from an account younger than " + n.MinAge.String()},
+	}, nil
+}
@@ -0,0 +1,151 @@
+// Package filter decides whether user content goes live, is held for
+// moderation or is rejected, by running it through a pipeline of checks.
+package filter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Action is what should happen to checked content.
+type Action string
+
+const (
+	Allow  Action = "allow"
+	Hold   Action = "hold"
+	Reject Action = "reject"
+)
+
+func (a Action) severity() int {
+	switch a {
+	case Hold:
+		return 1
+	case Reject:
+		return 2
+	}
+	return 0
+}
+
+// The kinds of content that are checked.
+const (
+	KindComment = "comment"
+	KindPost    = "post"
+)
+
+// Content is the text a user is about to publish.
+type Content struct {
+	Kind string
+	// ID is the id of the edited content, zero for new content.
+	ID            int64
+	Text          string
+	UserID        int64
+	UserCreatedAt time.Time
+}
+
+// Words returns the lowercased words of the text.
+func (c *Content) Words() []string {
+	return words(c.Text)
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Hash identifies the text regardless of case, spacing and punctuation.
+func (c *Content) Hash() string {
+	sum := sha256.Sum256([]byte(strings.Join(c.Words(), " ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Result is the outcome of a check, with the reasons for anything other
+// than Allow.
+type Result struct {
+	Action  Action
+	Reasons []string
+}
+
+// merge takes the stricter action of r and o and the reasons of both.
+func (r *Result) merge(o *Result) {
+	if o.Action.severity() > r.Action.severity() {
+		r.Action = o.Action
+	}
+	r.Reasons = append(r.Reasons, o.Reasons...)
+}
+
+// Checker is a step of the pipeline. A nil result allows the content.
+type Checker interface {
+	Check(c *Content) (*Result, error)
+}
+
+// Scorer rates how likely content is spam or abuse, from 0 to 1, with
+// the reasons behind the score. Scorers plug external classifiers into
+// the pipeline through ScoreChecker.
+type Scorer interface {
+	Score(c *Content) (float64, []string, error)
+}
+
+// ScoreChecker holds content scored at least HoldAt and rejects content
+// scored at least RejectAt. A zero threshold is never reached.
+type ScoreChecker struct {
+	Scorer   Scorer
+	HoldAt   float64
+	RejectAt float64
+}
+
+func (s *ScoreChecker) Check(c *Content) (*Result, error) {
+	score, reasons, err := s.Scorer.Score(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var action Action
+	switch {
+	case s.RejectAt > 0 && score >= s.RejectAt:
+		action = Reject
+	case s.HoldAt > 0 && score >= s.HoldAt:
+		action = Hold
+	default:
+		return nil, nil
+	}
+
+	res := Result{Action: action}
+	if len(reasons) == 0 {
+		res.Reasons = append(res.Reasons, fmt.Sprintf("score %.2f", score))
+	}
+	for _, reason := range reasons {
+		res.Reasons = append(res.Reasons, fmt.Sprintf("score %.2f: %s", score, reason))
+	}
+	return &res, nil
+}
+
+// Pipeline runs content through its checkers. The strictest action wins
+// and the reasons of every checker are kept.
+type Pipeline struct {
+	checkers []Checker
+}
+
+func New(checkers ...Checker) *Pipeline {
+	return &Pipeline{
+		checkers: checkers,
+	}
+}
+
+func (p *Pipeline) Run(c *Content) (*Result, error) {
+	res := Result{Action: Allow}
+	for _, checker := range p.checkers {
+		r, err := checker.Check(c)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			res.merge(r)
+		}
+	}
+	return &res, nil
+}
@@ -0,0 +1,123 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fixedScorer struct {
+	score float64
+	err   error
+}
+
+func (s fixedScorer) Score(c *Content) (float64, []string, error) {
+	return s.score, []string{"looks like spam"}, s.err
+}
+
+func TestHash(t *testing.T) {
+	a := Content{Text: "Great post, thanks!"}
+	b := Content{Text: "great   POST thanks"}
+	require.Equal(t, a.Hash(), b.Hash())
+	require.NotEqual(t, a.Hash(), (&Content{Text: "great post"}).Hash())
+}
+
+func TestBannedWords(t *testing.T) {
+	b := BannedWords{Hold: []string{"casino"}, Reject: []string{"buy followers"}}
+
+	res, err := b.Check(&Content{Text: "Best Casino in town"})
+	require.NoError(t, err)
+	require.Equal(t, Hold, res.Action)
+	require.Equal(t, []string{`banned word "casino"`}, res.Reasons)
+
+	res, err = b.Check(&Content{Text: "casinos, buy followers!"})
+	require.NoError(t, err)
+	require.Equal(t, Reject, res.Action)
+
+	res, err = b.Check(&Content{Text: "nothing to see"})
+	require.NoError(t, err)
+	require.Nil(t, res)
+}
+
+func TestLinkLimit(t *testing.T) {
+	require.Equal(t, 3, CountLinks("[a](https://a.com) http://b.com www.c.com"))
+
+	l := LinkLimit{Max: 1}
+	res, err := l.Check(&Content{Text: "see https://a.com"})
+	require.NoError(t, err)
+	require.Nil(t, res)
+
+	res, err = l.Check(&Content{Text: "see https://a.com and https://b.com"})
+	require.NoError(t, err)
+	require.Equal(t, Hold, res.Action)
+}
+
+func TestDuplicates(t *testing.T) {
+	var own, others int64
+	d := Duplicates{
+		Count: func(c *Content) (int64, int64, error) {
+			return own, others, nil
+		},
+		MinWords:  3,
+		MaxOthers: 1,
+	}
+	c := Content{Kind: KindComment, Text: "check out my site"}
+
+	res, err := d.Check(&c)
+	require.NoError(t, err)
+	require.Nil(t, res)
+
+	others = 2
+	res, err = d.Check(&c)
+	require.NoError(t, err)
+	require.Equal(t, Hold, res.Action)
+
+	own = 1
+	res, err = d.Check(&c)
+	require.NoError(t, err)
+	require.Equal(t, Reject, res.Action)
+	require.Len(t, res.Reasons, 2)
+
+	res, err = d.Check(&Content{Text: "thanks"})
+	require.NoError(t, err)
+	require.Nil(t, res)
+}
+
+func TestNewAccount(t *testing.T) {
+	now := time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC)
+	n := NewAccount{MinAge: 24 * time.Hour, Now: func() time.Time { return now }}
+
+	res, err := n.Check(&Content{Text: "https://a.com", UserCreatedAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Equal(t, Hold, res.Action)
+
+	res, err = n.Check(&Content{Text: "hello", UserCreatedAt: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Nil(t, res)
+
+	res, err = n.Check(&Content{Text: "https://a.com", UserCreatedAt: now.Add(-48 * time.Hour)})
+	require.NoError(t, err)
+	require.Nil(t, res)
+}
+
+func TestPipeline(t *testing.T) {
+	p := New(
+		&LinkLimit{Max: 0},
+		&ScoreChecker{Scorer: fixedScorer{score: 0.95}, HoldAt: 0.5, RejectAt: 0.9},
+	)
+
+	res, err := p.Run(&Content{Text: "https://a.com"})
+	require.NoError(t, err)
+	require.Equal(t, Reject, res.Action)
+	require.Equal(t, []string{"1 links, at most 0 allowed", "score 0.95: looks like spam"}, res.Reasons)
+
+	res, err = New(&ScoreChecker{Scorer: fixedScorer{score: 0.2}, HoldAt: 0.5}).Run(&Content{Text: "hi"})
+	require.NoError(t, err)
+	require.Equal(t, Allow, res.Action)
+	require.Empty(t, res.Reasons)
+
+	_, err = New(&ScoreChecker{Scorer: fixedScorer{err: errors.New("down")}}).Run(&Content{})
+	require.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package markdown
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// WordsPerMinute is the reading speed used to estimate reading time.
+const WordsPerMinute = 200
+
+type Heading struct {
+	Level int
+	ID    string
+	Text  string
+}
+
+type Result struct {
+	HTML            string
+	TableOfContents []*Heading
+	ReadingTime     int
+}
+
+var (
+	md = goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			extension.Footnote,
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+	)
+
+	policy = newPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+
+	// heading anchors for the table of contents and footnote references
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\pL\pN:_-]+$`)).
+		OnElements("h1", "h2", "h3", "h4", "h5", "h6", "li", "sup")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^footnote-(ref|backref)$`)).OnElements("a")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^footnotes$`)).OnElements("div")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|backlink|endnotes)$`)).OnElements("a", "div")
+
+	return p
+}
+
+// Render converts Markdown to sanitized HTML. Raw HTML in the source is
+// never passed through. The result also holds the headings of the document
+// and its estimated reading time in minutes.
+func Render(source string) (*Result, error) {
+	src := []byte(source)
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		HTML:            policy.Sanitize(buf.String()),
+		TableOfContents: headings(doc, src),
+		ReadingTime:     ReadingTime(source),
+	}, nil
+}
+
+// ReadingTime estimates how many minutes it takes to read text.
+func ReadingTime(text string) int {
+	words := len(strings.Fields(text))
+	if words == 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(words) / WordsPerMinute))
+}
+
+func headings(doc ast.Node, source []byte) []*Heading {
+	result := make([]*Heading, 0)
+
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		var id string
+		if v, ok := heading.AttributeString("id"); ok {
+			if b, ok := v.([]byte); ok {
+				id = string(b)
+			}
+		}
+
+		result = append(result, &Heading{
+			Level: heading.Level,
+			ID:    id,
+			Text:  string(heading.Text(source)),
+		})
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return result
+}
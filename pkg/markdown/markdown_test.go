@@ -0,0 +1,42 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	source := "# Getting started\n\n" +
+		"Install it first[^1].\n\n" +
+		"## Usage\n\n" +
+		"```go\nfmt.Println(\"hi\")\n```\n\n" +
+		"| a | b |\n|---|---|\n| 1 | 2 |\n\n" +
+		"<script>alert(1)</script>\n\n" +
+		"[click](javascript:alert(1))\n\n" +
+		"[^1]: See the docs.\n"
+
+	result, err := Render(source)
+	require.NoError(t, err)
+
+	require.Contains(t, result.HTML, `<h1 id="getting-started">Getting started</h1>`)
+	require.Contains(t, result.HTML, `<code class="language-go">`)
+	require.Contains(t, result.HTML, `<table>`)
+	require.Contains(t, result.HTML, `class="footnotes"`)
+	require.Contains(t, result.HTML, `<li id="fn:1">`)
+	require.NotContains(t, result.HTML, "<script>")
+	require.NotContains(t, result.HTML, "javascript:")
+
+	require.Len(t, result.TableOfContents, 2)
+	require.Equal(t, &Heading{Level: 1, ID: "getting-started", Text: "Getting started"}, result.TableOfContents[0])
+	require.Equal(t, &Heading{Level: 2, ID: "usage", Text: "Usage"}, result.TableOfContents[1])
+
+	require.Equal(t, 1, result.ReadingTime)
+}
+
+func TestReadingTime(t *testing.T) {
+	require.Equal(t, 0, ReadingTime(""))
+	require.Equal(t, 1, ReadingTime("one two three"))
+	require.Equal(t, 3, ReadingTime(strings.Repeat("word ", 2*WordsPerMinute+1)))
+}
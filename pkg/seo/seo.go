@@ -0,0 +1,82 @@
+// Package seo renders the HTML head tags that describe a page to search
+// engines and to the link previews of social networks and chat apps.
+package seo
+
+import (
+	"html"
+	"strings"
+	"time"
+)
+
+// Meta describes a page. Empty fields are left out.
+type Meta struct {
+	Title        string
+	Description  string
+	CanonicalURL string
+	Image        string
+	SiteName     string
+	Author       string
+	Tags         []string
+	Published    time.Time
+	Modified     time.Time
+	// OEmbedURL is the oEmbed endpoint of the page, for discovery.
+	OEmbedURL string
+}
+
+// Head returns the title, meta and link tags of a page, Open Graph and
+// Twitter card tags included, one per line.
+func Head(m *Meta) string {
+	var b strings.Builder
+
+	b.WriteString("<title>" + html.EscapeString(m.Title) + "</title>\n")
+	meta(&b, "name", "description", m.Description)
+	link(&b, "canonical", "", m.CanonicalURL)
+
+	meta(&b, "property", "og:type", "article")
+	meta(&b, "property", "og:title", m.Title)
+	meta(&b, "property", "og:description", m.Description)
+	meta(&b, "property", "og:url", m.CanonicalURL)
+	meta(&b, "property", "og:image", m.Image)
+	meta(&b, "property", "og:site_name", m.SiteName)
+	if !m.Published.IsZero() {
+		meta(&b, "property", "article:published_time", m.Published.UTC().Format(time.RFC3339))
+	}
+	if !m.Modified.IsZero() {
+		meta(&b, "property", "article:modified_time", m.Modified.UTC().Format(time.RFC3339))
+	}
+	meta(&b, "property", "article:author", m.Author)
+	for _, tag := range m.Tags {
+		meta(&b, "property", "article:tag", tag)
+	}
+
+	card := "summary"
+	if m.Image != "" {
+		card = "summary_large_image"
+	}
+	meta(&b, "name", "twitter:card", card)
+	meta(&b, "name", "twitter:title", m.Title)
+	meta(&b, "name", "twitter:description", m.Description)
+	meta(&b, "name", "twitter:image", m.Image)
+
+	link(&b, "alternate", "application/json+oembed", m.OEmbedURL)
+
+	return b.String()
+}
+
+func meta(b *strings.Builder, attr, name, content string) {
+	if content == "" {
+		return
+	}
+	b.WriteString(`<meta ` + attr + `="` + name + `" content="` + html.EscapeString(content) + `">` + "\n")
+}
+
+func link(b *strings.Builder, rel, typ, href string) {
+	if href == "" {
+		return
+	}
+	b.WriteString(`<link rel="` + rel + `"`)
+	if typ != "" {
+		b.WriteString(` type="` + typ + `"`)
+	}
+	b.WriteString(` href="` + html.EscapeString(href) + `">` + "\n")
+}
@@ -0,0 +1,41 @@
+package seo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHead(t *testing.T) {
+	head := Head(&Meta{
+		Title:        `Tom & "Jerry"`,
+		Description:  "A <short> story",
+		CanonicalURL: "https://example.com/posts/1",
+		Image:        "https://example.com/medias/1.png",
+		SiteName:     "Blog",
+		Tags:         []string{"cats", "mice"},
+		Published:    time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC),
+		OEmbedURL:    "https://example.com/v1/oembed?url=https%3A%2F%2Fexample.com%2Fposts%2F1",
+	})
+
+	lines := strings.Split(strings.TrimSpace(head), "\n")
+	require.Equal(t, "<title>Tom &amp; &#34;Jerry&#34;</title>", lines[0])
+	require.Contains(t, lines, `<meta name="description" content="A &lt;short&gt; story">`)
+	require.Contains(t, lines, `<link rel="canonical" href="https://example.com/posts/1">`)
+	require.Contains(t, lines, `<meta property="og:title" content="Tom &amp; &#34;Jerry&#34;">`)
+	require.Contains(t, lines, `<meta property="article:published_time" content="2022-11-01T10:00:00Z">`)
+	require.Contains(t, lines, `<meta property="article:tag" content="mice">`)
+	require.Contains(t, lines, `<meta name="twitter:card" content="summary_large_image">`)
+	require.Contains(t, lines, `<link rel="alternate" type="application/json+oembed" href="https://example.com/v1/oembed?url=https%3A%2F%2Fexample.com%2Fposts%2F1">`)
+	require.NotContains(t, head, "article:modified_time")
+	require.NotContains(t, head, "article:author")
+}
+
+func TestHeadWithoutImage(t *testing.T) {
+	head := Head(&Meta{Title: "Hello"})
+	require.Contains(t, head, `<meta name="twitter:card" content="summary">`)
+	require.NotContains(t, head, "og:image")
+	require.NotContains(t, head, "canonical")
+}
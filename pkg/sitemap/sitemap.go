@@ -0,0 +1,66 @@
+// Package sitemap writes sitemaps and sitemap indexes as described at
+// https://www.sitemaps.org/protocol.html.
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs is the most URLs a single sitemap may list.
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL is a page in a sitemap, or a sitemap in an index.
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	NS      string   `xml:"xmlns,attr"`
+	URLs    []*entry `xml:"url"`
+}
+
+type index struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	NS       string   `xml:"xmlns,attr"`
+	Sitemaps []*entry `xml:"sitemap"`
+}
+
+// URLSet writes a sitemap listing urls. Callers keep it under MaxURLs.
+func URLSet(urls []*URL) ([]byte, error) {
+	return marshal(urlSet{NS: namespace, URLs: entries(urls)})
+}
+
+// Index writes a sitemap index listing the sitemaps.
+func Index(sitemaps []*URL) ([]byte, error) {
+	return marshal(index{NS: namespace, Sitemaps: entries(sitemaps)})
+}
+
+func entries(urls []*URL) []*entry {
+	res := make([]*entry, 0, len(urls))
+	for _, u := range urls {
+		e := entry{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			e.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+		res = append(res, &e)
+	}
+	return res
+}
+
+func marshal(doc interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
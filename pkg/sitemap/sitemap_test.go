@@ -0,0 +1,40 @@
+package sitemap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLSet(t *testing.T) {
+	data, err := URLSet([]*URL{
+		{Loc: "https://example.com/posts/1?a=1&b=2", LastMod: time.Date(2022, 11, 1, 10, 0, 0, 0, time.FixedZone("", 5*60*60))},
+		{Loc: "https://example.com/posts/2"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/posts/1?a=1&amp;b=2</loc>
+    <lastmod>2022-11-01T05:00:00Z</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/posts/2</loc>
+  </url>
+</urlset>`, string(data))
+}
+
+func TestIndex(t *testing.T) {
+	data, err := Index([]*URL{
+		{Loc: "https://example.com/sitemaps/posts-1.xml", LastMod: time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap>
+    <loc>https://example.com/sitemaps/posts-1.xml</loc>
+    <lastmod>2022-11-01T00:00:00Z</lastmod>
+  </sitemap>
+</sitemapindex>`, string(data))
+}
@@ -0,0 +1,25 @@
+package utils
+
+import "strings"
+
+var botMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "fetch", "preview",
+	"curl", "wget", "python-requests", "go-http-client", "headless",
+}
+
+// IsBot reports whether a user agent looks like a crawler or a script
+// rather than a browser. An empty user agent counts as a bot.
+func IsBot(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return true
+	}
+
+	for _, marker := range botMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+
+	return false
+}
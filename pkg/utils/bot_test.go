@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBot(t *testing.T) {
+	require.True(t, IsBot(""))
+	require.True(t, IsBot("Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"))
+	require.True(t, IsBot("curl/7.85.0"))
+	require.True(t, IsBot("Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/108.0 Safari/537.36"))
+	require.False(t, IsBot("Mozilla/5.0 (X11; Linux x86_64; rv:107.0) Gecko/20100101 Firefox/107.0"))
+}
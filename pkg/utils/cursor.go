@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+type cursorToken struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// EncodeCursor builds an opaque pagination token from the sort key values
+// of a row. sort is stored alongside so that a token can't be replayed
+// against a listing with a different order.
+func EncodeCursor(sort string, values []string) string {
+	data, _ := json.Marshal(cursorToken{
+		Sort:   sort,
+		Values: values,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns the sort key values of a token made by EncodeCursor
+// for the same sort.
+func DecodeCursor(token, sort string) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor cursorToken
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if cursor.Sort != sort || len(cursor.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor.Values, nil
+}
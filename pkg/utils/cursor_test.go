@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	values := []string{"2022-12-01 10:00:00.123456+00", "42"}
+
+	token := EncodeCursor("created_at:desc", values)
+	require.NotEmpty(t, token)
+
+	decoded, err := DecodeCursor(token, "created_at:desc")
+	require.NoError(t, err)
+	require.Equal(t, values, decoded)
+
+	_, err = DecodeCursor(token, "views_count:desc")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = DecodeCursor("not a cursor", "created_at:desc")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header
+// in order of preference, without region and duplicates. Wildcards and
+// languages with q=0 are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				v, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					v = 0
+				}
+				q = v
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		if i := strings.IndexAny(tag, "-_"); i > 0 {
+			tag = tag[:i]
+		}
+		langs = append(langs, weighted{lang: tag, q: q})
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	res := make([]string, 0, len(langs))
+	seen := make(map[string]bool)
+	for _, l := range langs {
+		if !seen[l.lang] {
+			seen[l.lang] = true
+			res = append(res, l.lang)
+		}
+	}
+	return res
+}
@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	require.Equal(t, []string{}, ParseAcceptLanguage(""))
+	require.Equal(t, []string{"ru", "en", "uz"}, ParseAcceptLanguage("uz;q=0.5, ru-RU, en-US;q=0.8, en;q=0.7, *;q=0.1"))
+	require.Equal(t, []string{"en"}, ParseAcceptLanguage("EN_gb, fr;q=0"))
+}
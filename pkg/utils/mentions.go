@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// a mention can't follow a word character, which leaves out emails
+	mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9_.]{1,30})`)
+	// code blocks and spans are left out of the mentions
+	codePattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+)
+
+// ParseMentions returns the usernames mentioned as @username in a text,
+// without duplicates, in the order they first appear.
+func ParseMentions(text string) []string {
+	text = codePattern.ReplaceAllString(text, " ")
+
+	var (
+		result = make([]string, 0)
+		seen   = make(map[string]bool)
+	)
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		// a dot ending the sentence isn't part of the username
+		username := strings.TrimRight(m[1], ".")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		result = append(result, username)
+	}
+
+	return result
+}
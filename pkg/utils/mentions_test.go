@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMentions(t *testing.T) {
+	require.Equal(t, []string{}, ParseMentions("write to me@example.com"))
+	require.Equal(t, []string{"alice", "bob.smith"}, ParseMentions("@alice, ask @bob.smith. Thanks @alice!"))
+	require.Equal(t, []string{"carol"}, ParseMentions("run `@decorator` and\n```\n@dave\n```\n(@carol)"))
+}
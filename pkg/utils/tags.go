@@ -0,0 +1,26 @@
+package utils
+
+import "strings"
+
+// NormalizeTags trims, lowercases and deduplicates tags, keeping nil as nil
+// so that updates can tell "no change" from "no tags".
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	var (
+		result = make([]string, 0, len(tags))
+		seen   = make(map[string]bool, len(tags))
+	)
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+
+	return result
+}
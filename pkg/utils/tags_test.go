@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	require.Nil(t, NormalizeTags(nil))
+	require.Equal(t, []string{}, NormalizeTags([]string{" ", ""}))
+	require.Equal(t, []string{"go", "postgres"}, NormalizeTags([]string{" Go", "postgres", "GO "}))
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ToTsQuery converts user search text into a to_tsquery expression.
+// Quoted text becomes a phrase query, a word ending with * becomes a prefix
+// query and everything else is AND-ed together. Characters that have a
+// meaning in tsquery syntax are dropped, so the result is always valid input.
+func ToTsQuery(search string) string {
+	var terms []string
+
+	for i, part := range strings.Split(search, `"`) {
+		// every odd part was written between quotes
+		if i%2 == 1 {
+			if t := tsPhrase(part); t != "" {
+				terms = append(terms, t)
+			}
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			if t := tsPhrase(word); t != "" {
+				terms = append(terms, t)
+			}
+		}
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+// tsPhrase joins the lexemes of text with the followed-by operator.
+func tsPhrase(text string) string {
+	var lexemes []string
+
+	for _, word := range strings.Fields(text) {
+		prefix := strings.HasSuffix(word, "*")
+		parts := strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+
+		for i, p := range parts {
+			p = strings.ToLower(p)
+			if prefix && i == len(parts)-1 {
+				p += ":*"
+			}
+			lexemes = append(lexemes, p)
+		}
+	}
+
+	if len(lexemes) > 1 {
+		return "(" + strings.Join(lexemes, " <-> ") + ")"
+	}
+
+	return strings.Join(lexemes, "")
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToTsQuery(t *testing.T) {
+	cases := map[string]string{
+		"":                     "",
+		"golang":               "golang",
+		"Go  Postgres":         "go & postgres",
+		"post*":                "post:*",
+		`"full text" search`:   "(full <-> text) & search",
+		`"full tex*"`:          "(full <-> tex:*)",
+		"a&b | !c":             "(a <-> b) & c",
+		"it's':*":              "(it <-> s:*)",
+		`"unterminated phrase`: "(unterminated <-> phrase)",
+		"привет мир":           "привет & мир",
+		`"" * &`:               "",
+		"hello-world":          "(hello <-> world)",
+	}
+
+	for search, expected := range cases {
+		require.Equal(t, expected, ToTsQuery(search), search)
+	}
+}
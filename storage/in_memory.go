@@ -7,9 +7,32 @@ import (
 	"github.com/go-redis/redis/v9"
 )
 
+// PostViewsKey is the hash buffering post views, keyed by post id, until
+// they are flushed to Postgres.
+const PostViewsKey = "post_views"
+
+// PostDailyViewsKey and PostDailyViewersKey, followed by a day formatted as
+// 2006-01-02, hold the views of that day for the stats rollup. The first is
+// a hash keyed by post id, the second is followed by "_" and the post id and
+// holds a HyperLogLog of its viewers.
+const (
+	PostDailyViewsKey   = "post_daily_views_"
+	PostDailyViewersKey = "post_daily_viewers_"
+)
+
 type InMemoryStorageI interface {
 	Set(key, value string, exp time.Duration) error
 	Get(key string) (string, error)
+	Del(keys ...string) error
+	// SetNX sets the key only if it does not exist and reports whether it did.
+	SetNX(key, value string, exp time.Duration) (bool, error)
+	HIncrBy(key, field string, incr int64) error
+	HGetAll(key string) (map[string]string, error)
+	// HTake returns all fields of a hash and deletes it atomically.
+	HTake(key string) (map[string]string, error)
+	PFAdd(key, value string) error
+	PFCount(key string) (int64, error)
+	Expire(key string, exp time.Duration) error
 }
 
 type storageRedis struct {
@@ -36,4 +59,47 @@ func (rd *storageRedis) Get(key string) (string, error) {
 		return "", err
 	}
 	return val, nil
-}
\ No newline at end of file
+}
+
+func (rd *storageRedis) Del(keys ...string) error {
+	return rd.client.Del(context.Background(), keys...).Err()
+}
+
+func (rd *storageRedis) SetNX(key, value string, exp time.Duration) (bool, error) {
+	return rd.client.SetNX(context.Background(), key, value, exp).Result()
+}
+
+func (rd *storageRedis) HIncrBy(key, field string, incr int64) error {
+	return rd.client.HIncrBy(context.Background(), key, field, incr).Err()
+}
+
+func (rd *storageRedis) HTake(key string) (map[string]string, error) {
+	var values *redis.MapStringStringCmd
+
+	_, err := rd.client.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		values = pipe.HGetAll(context.Background(), key)
+		pipe.Del(context.Background(), key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values.Val(), nil
+}
+
+func (rd *storageRedis) HGetAll(key string) (map[string]string, error) {
+	return rd.client.HGetAll(context.Background(), key).Result()
+}
+
+func (rd *storageRedis) PFAdd(key, value string) error {
+	return rd.client.PFAdd(context.Background(), key, value).Err()
+}
+
+func (rd *storageRedis) PFCount(key string) (int64, error) {
+	return rd.client.PFCount(context.Background(), key).Result()
+}
+
+func (rd *storageRedis) Expire(key string, exp time.Duration) error {
+	return rd.client.Expire(context.Background(), key, exp).Err()
+}
@@ -2,12 +2,17 @@ package postgres
 
 import (
 	"database/sql"
-	"fmt"
+	"errors"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
 )
 
+// uniqueViolation is the Postgres error code of a unique constraint failure.
+const uniqueViolation = "23505"
+
 type categoryRepo struct {
 	db *sqlx.DB
 }
@@ -38,13 +43,40 @@ func (cr *categoryRepo) Create(category *repo.Category) (*repo.Category, error)
 	return category, nil
 }
 
+func (cr *categoryRepo) GetByTitle(title string) (*repo.Category, error) {
+	query := `
+		SELECT
+			id,
+			title,
+			created_at
+		FROM categories WHERE title = $1 AND deleted_at IS NULL
+	`
+
+	var result repo.Category
+
+	err := cr.db.QueryRow(
+		query,
+		title,
+	).Scan(
+		&result.ID,
+		&result.Title,
+		&result.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 func (cr *categoryRepo) Get(category_id int64) (*repo.Category, error) {
 	query := `
 		SELECT 
 			id,
 			title,
 			created_at
-		FROM categories WHERE id = $1
+		FROM categories WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var result repo.Category
@@ -69,7 +101,7 @@ func (cr *categoryRepo) Update(category *repo.Category) (*repo.Category, error)
 	query := `
 		UPDATE categories SET 
 			title = $1
-		WHERE id = $2 
+		WHERE id = $2 AND deleted_at IS NULL
 		RETURNING id, created_at
 	`
 
@@ -93,7 +125,31 @@ func (cr *categoryRepo) Update(category *repo.Category) (*repo.Category, error)
 
 func (cr *categoryRepo) Delete(category_id int64) error {
 	query := `
-		DELETE FROM categories WHERE id = $1
+		UPDATE categories SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	row, err := cr.db.Exec(
+		query,
+		category_id,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (cr *categoryRepo) Restore(category_id int64) error {
+	query := `
+		UPDATE categories SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL
 	`
 
 	row, err := cr.db.Exec(
@@ -101,8 +157,13 @@ func (cr *categoryRepo) Delete(category_id int64) error {
 		category_id,
 	)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return repo.ErrCategoryExists
+		}
 		return err
 	}
+
 	rowsAffected, err := row.RowsAffected()
 	if err != nil {
 		return err
@@ -115,57 +176,102 @@ func (cr *categoryRepo) Delete(category_id int64) error {
 	return nil
 }
 
+func (cr *categoryRepo) PurgeDeleted(before time.Time) (int64, error) {
+	query := `
+		DELETE FROM categories c
+		WHERE c.deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.category_id = c.id)
+	`
+
+	row, err := cr.db.Exec(query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return row.RowsAffected()
+}
+
+var categorySortFields = map[string]string{
+	"created_at": "created_at",
+	"title":      "title",
+	"deleted_at": "deleted_at",
+}
+
 func (ur *categoryRepo) GetAll(params *repo.GetAllCategoryParams) (*repo.GetAllCategoryResult, error) {
 	result := repo.GetAllCategoryResult{
 		Categories: make([]*repo.Category, 0),
 	}
 
-	offset := (params.Page - 1) * params.Limit
+	q := newListQuery()
+	defaultSort := []*repo.SortField{{Field: "created_at", Desc: true}}
 
-	limit := fmt.Sprintf(" LIMIT %d OFFSET %d", params.Limit, offset)
+	if params.Deleted {
+		q.Where("deleted_at IS NOT NULL")
+		defaultSort[0].Field = "deleted_at"
+	} else {
+		q.Where("deleted_at IS NULL")
+	}
 
-	filter := ""
 	if params.Search != "" {
-		filter = "WHERE title ILIKE '%s'" + "%" + params.Search + "%"
+		q.Where("title ILIKE " + q.Arg("%"+params.Search+"%"))
+	}
+
+	err := q.Sort(params.Sort, categorySortFields, defaultSort, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.Keyset(params.After, params.Before)
+	if err != nil {
+		return nil, err
 	}
 
 	query := `
 		SELECT 
 			id,
 			title,
-			created_at
+			created_at,
+			deleted_at` + q.CursorColumns() + `
 		FROM categories
-	` + filter + `
-		ORDER BY created_at DESC
-	` + limit
+	` + q.ListFilter() + q.Order() + q.Paginate(int64(params.Limit), int64(params.Page))
 
-	rows, err := ur.db.Query(query)
+	rows, err := ur.db.Query(query, q.Args()...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var cursors []*repo.Cursor
 	for rows.Next() {
 		var category repo.Category
-		err := rows.Scan(
+		cursor, cursorDest := q.CursorDest()
+		err := rows.Scan(append([]interface{}{
 			&category.ID,
 			&category.Title,
 			&category.CreatedAt,
-		)
+			&category.DeletedAt,
+		}, cursorDest...)...)
 		if err != nil {
 			return nil, err
 		}
 
 		result.Categories = append(result.Categories, &category)
+		cursors = append(cursors, cursor)
 	}
 
-	queryCount := "SELECT count(1) FROM categories " + filter
+	queryCount := "SELECT count(1) FROM categories" + q.Filter()
 
-	err = ur.db.QueryRow(queryCount).Scan(&result.Count)
+	err = ur.db.QueryRow(queryCount, q.FilterArgs()...).Scan(&result.Count)
 
 	if err != nil {
 		return nil, err
 	}
 
+	keep, next, prev := q.Cursors(cursors, int64(result.Count))
+	result.Categories = result.Categories[:keep]
+	if q.Backward() {
+		reverseRows(result.Categories)
+	}
+	result.NextCursor, result.PrevCursor = next, prev
+
 	return &result, nil
 }
@@ -1,6 +1,7 @@
 package postgres_test
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
@@ -35,6 +36,17 @@ func TestGetCategory(t *testing.T) {
 	deleteCategory(t, c.ID)
 }
 
+func TestGetCategoryByTitle(t *testing.T) {
+	c := createCategory(t)
+	category, err := dbManager.Category().GetByTitle(c.Title)
+	require.NoError(t, err)
+	require.Equal(t, c.ID, category.ID)
+	deleteCategory(t, c.ID)
+
+	_, err = dbManager.Category().GetByTitle(c.Title)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
 func TestUpdateCategory(t *testing.T) {
 	c := createCategory(t)
 	require.NotEmpty(t, c)
@@ -63,3 +75,19 @@ func TestGetAllCategory(t *testing.T) {
 	require.GreaterOrEqual(t, len(cs.Categories), 1)
 	require.NoError(t, err)
 }
+
+func TestRestoreCategory(t *testing.T) {
+	c := createCategory(t)
+	deleteCategory(t, c.ID)
+
+	same := createCategory(t)
+	require.ErrorIs(t, dbManager.Category().Restore(c.ID), repo.ErrCategoryExists)
+
+	deleteCategory(t, same.ID)
+	require.NoError(t, dbManager.Category().Restore(c.ID))
+
+	category, err := dbManager.Category().Get(c.ID)
+	require.NoError(t, err)
+	require.Equal(t, c.Title, category.Title)
+	deleteCategory(t, c.ID)
+}
@@ -1,10 +1,11 @@
 package postgres
 
 import (
-	"fmt"
+	"database/sql"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
 )
 
@@ -19,25 +20,61 @@ func NewComment(db *sqlx.DB) repo.CommentStorageI {
 }
 
 func (pr *commentRepo) Create(c *repo.Comment) (*repo.Comment, error) {
+	// the id is taken first so that it can end the path
 	query := `
 		INSERT INTO comments (
+			id,
 			post_id,
 			user_id,
-			description
-		) VALUES ($1, $2, $3)
+			parent_id,
+			description,
+			created_at,
+			depth,
+			path,
+			status
+		)
+		SELECT
+			n.id,
+			$1,
+			$2,
+			$3,
+			$4,
+			coalesce($5, CURRENT_TIMESTAMP),
+			coalesce(p.depth + 1, 0),
+			coalesce(p.path, '{}') || n.id,
+			$6
+		FROM (SELECT nextval(pg_get_serial_sequence('comments', 'id'))::integer AS id) n
+		LEFT JOIN comments p ON p.id = $3
 		RETURNING 
 		id, 
-		created_at
+		created_at,
+		depth,
+		path
 	`
 
+	if c.Status == "" {
+		c.Status = repo.CommentStatusApproved
+	}
+
+	// imported comments keep their dates
+	var createdAt *time.Time
+	if !c.CreatedAt.IsZero() {
+		createdAt = &c.CreatedAt
+	}
+
 	err := pr.db.QueryRow(
 		query,
 		c.PostID,
 		c.UserID,
+		c.ParentID,
 		c.Description,
+		createdAt,
+		c.Status,
 	).Scan(
 		&c.ID,
 		&c.CreatedAt,
+		&c.Depth,
+		pq.Array(&c.Path),
 	)
 
 	if err != nil {
@@ -56,10 +93,19 @@ func (pr *commentRepo) Get(comment_id int64) (*repo.Comment, error) {
 			id,
 			post_id,
 			user_id,
+			parent_id,
 			description,
 			created_at,
 			updated_at,
-		FROM comments WHERE id = $1
+			depth,
+			path,
+			` + commentReplyCountColumn + `,
+			status,
+			moderated_by,
+			moderated_at,
+			` + mentionsColumn(repo.MentionTargetComment, "c.id") + `,
+			edit_count
+		FROM comments c WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	err := pr.db.QueryRow(
@@ -69,9 +115,18 @@ func (pr *commentRepo) Get(comment_id int64) (*repo.Comment, error) {
 		&res.ID,
 		&res.PostID,
 		&res.UserID,
+		&res.ParentID,
 		&res.Description,
 		&res.CreatedAt,
 		&res.UpdatedAt,
+		&res.Depth,
+		pq.Array(&res.Path),
+		&res.ReplyCount,
+		&res.Status,
+		&res.ModeratedBy,
+		&res.ModeratedAt,
+		(*mentionList)(&res.Mentions),
+		&res.EditCount,
 	)
 
 	if err != nil {
@@ -85,18 +140,32 @@ func (cr *commentRepo) Update(c *repo.UpdateComment) (*repo.UpdateComment, error
 	var (
 		res repo.UpdateComment
 	)
+	// the replaced description is kept when it changes
 	query := `
+		WITH old AS (
+			SELECT id, description, coalesce(updated_at, created_at) AS written_at
+			FROM comments WHERE id = $3 AND deleted_at IS NULL
+			FOR UPDATE
+		), revision AS (
+			INSERT INTO comment_revisions(comment_id, description, written_at, edited_by)
+			SELECT id, description, written_at, nullif($5, 0) FROM old WHERE description <> $1
+			RETURNING id
+		)
 		UPDATE comments SET
 			description = $1,
-			updated_at = $2
-	    WHERE id = $3
+			updated_at = CASE WHEN description <> $1 THEN $2 ELSE updated_at END,
+			status = coalesce(nullif($4, ''), status),
+			edit_count = edit_count + (SELECT count(1) FROM revision)
+	    WHERE id = $3 AND deleted_at IS NULL
 		RETURNING 
 			id,
 			description,
 			post_id,
 			user_id,
 			created_at,
-			updated_at
+			updated_at,
+			status,
+			edit_count
 	`
 
 	err := cr.db.QueryRow(
@@ -104,6 +173,8 @@ func (cr *commentRepo) Update(c *repo.UpdateComment) (*repo.UpdateComment, error
 		c.Description,
 		time.Now(),
 		c.ID,
+		c.Status,
+		c.EditedBy,
 	).Scan(
 		&res.ID,
 		&res.Description,
@@ -111,6 +182,8 @@ func (cr *commentRepo) Update(c *repo.UpdateComment) (*repo.UpdateComment, error
 		&res.UserID,
 		&res.CreatedAt,
 		&res.UpdatedAt,
+		&res.Status,
+		&res.EditCount,
 	)
 
 	if err != nil {
@@ -122,37 +195,244 @@ func (cr *commentRepo) Update(c *repo.UpdateComment) (*repo.UpdateComment, error
 
 func (cr *commentRepo) Delete(comment_id int64) error {
 	query := `
-		DELETE FROM comments WHERE id = $1
+		UPDATE comments SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	_, err := cr.db.Exec(
+	row, err := cr.db.Exec(
 		query,
 		comment_id,
 	)
+	if err != nil {
+		return err
+	}
 
+	rowsAffected, err := row.RowsAffected()
 	if err != nil {
 		return err
 	}
 
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
 	return nil
 }
 
+func (cr *commentRepo) Restore(comment_id, userID int64) error {
+	query := `
+		UPDATE comments SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL AND ($2 = 0 OR user_id = $2)
+	`
+
+	row, err := cr.db.Exec(
+		query,
+		comment_id,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (cr *commentRepo) Moderate(m *repo.ModerateComments) (int64, error) {
+	query := `
+		UPDATE comments c SET
+			status = $1,
+			moderated_by = $2,
+			moderated_at = CURRENT_TIMESTAMP
+		WHERE c.id = ANY($3) AND c.deleted_at IS NULL AND ($4 = 0 OR EXISTS (
+			SELECT 1 FROM post_contributors pc WHERE pc.post_id = c.post_id AND pc.user_id = $4
+		)) AND NOT EXISTS (
+			SELECT 1 FROM reports r WHERE r.target_type = 'comment' AND r.target_id = c.id AND r.hidden
+		)
+	`
+
+	row, err := cr.db.Exec(
+		query,
+		m.Status,
+		m.ModeratorID,
+		pq.Array(m.IDs),
+		m.UserID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return row.RowsAffected()
+}
+
+func (cr *commentRepo) HasApproved(userID int64) (bool, error) {
+	var exists bool
+	err := cr.db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM comments WHERE user_id = $1 AND status = 'approved')",
+		userID,
+	).Scan(&exists)
+
+	return exists, err
+}
+
+func (cr *commentRepo) GetRevisions(comment_id int64) ([]*repo.CommentRevision, error) {
+	query := `
+		SELECT
+			id,
+			comment_id,
+			description,
+			written_at,
+			edited_by,
+			edited_at
+		FROM comment_revisions
+		WHERE comment_id = $1
+		ORDER BY written_at, id
+	`
+
+	rows, err := cr.db.Query(query, comment_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]*repo.CommentRevision, 0)
+	for rows.Next() {
+		var r repo.CommentRevision
+		err := rows.Scan(
+			&r.ID,
+			&r.CommentID,
+			&r.Description,
+			&r.WrittenAt,
+			&r.EditedBy,
+			&r.EditedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, &r)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (cr *commentRepo) PurgeDeleted(before time.Time) (int64, error) {
+	// a comment goes along with its whole thread, or stays as a tombstone
+	query := `
+		DELETE FROM comments c
+		WHERE c.deleted_at < $1 AND NOT EXISTS (
+			SELECT 1 FROM comments d
+			WHERE d.path @> ARRAY[c.id] AND (d.deleted_at IS NULL OR d.deleted_at >= $1)
+		)
+	`
+
+	row, err := cr.db.Exec(query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return row.RowsAffected()
+}
+
+// commentReplyCountColumn counts the approved replies of the comment c
+// still around.
+const commentReplyCountColumn = `(
+	SELECT count(1) FROM comments r WHERE r.parent_id = c.id AND r.deleted_at IS NULL AND r.status = 'approved'
+)`
+
+var commentSortFields = map[string]string{
+	"path":       "c.path",
+	"created_at": "c.created_at",
+	"updated_at": "coalesce(c.updated_at, c.created_at)",
+	"deleted_at": "c.deleted_at",
+}
+
 func (pr *commentRepo) GetAll(params *repo.GetCommentsParams) (*repo.GetAllCommentsResult, error) {
 	result := repo.GetAllCommentsResult{
 		Comments: make([]*repo.Comment, 0),
 	}
 
-	offset := (params.Page - 1) * params.Limit
+	q := newListQuery()
+	defaultSort := []*repo.SortField{{Field: "created_at", Desc: true}}
+
+	status := params.Status
+	if status == "" {
+		status = repo.CommentStatusApproved
+	}
 
-	limit := fmt.Sprintf(" LIMIT %d OFFSET %d", params.Limit, offset)
+	switch {
+	case params.Deleted:
+		q.Where("c.deleted_at IS NOT NULL")
+		defaultSort[0].Field = "deleted_at"
+	case params.Threaded:
+		// replies that are listed keep their hidden parents as tombstones
+		arg := q.Arg(status)
+		q.Where(`(c.deleted_at IS NULL AND c.status = ` + arg + ` OR EXISTS (
+			SELECT 1 FROM comments d
+			WHERE d.path @> ARRAY[c.id] AND d.id <> c.id AND d.deleted_at IS NULL AND d.status = ` + arg + `
+		))`)
+		defaultSort[0] = &repo.SortField{Field: "path"}
+	default:
+		q.Where("c.deleted_at IS NULL AND c.status = " + q.Arg(status))
+	}
+	if !params.Deleted {
+		// comments of a deleted post go away with it, and those of a post
+		// the viewer can't see are hidden along with it
+		visible := ""
+		if !params.IsSuperadmin {
+			visible = " AND (p.visibility IN ('public', 'unlisted')"
+			if params.UnlockedPostID != 0 {
+				visible += " OR p.visibility = 'password' AND p.id = " + q.Arg(params.UnlockedPostID)
+			}
+			if params.ViewerID != 0 {
+				visible += " OR EXISTS (SELECT 1 FROM post_contributors pc WHERE pc.post_id = p.id AND pc.user_id = " + q.Arg(params.ViewerID) + ")"
+			}
+			visible += ")"
+		}
+		q.Where("EXISTS (SELECT 1 FROM posts p WHERE p.id = c.post_id AND p.deleted_at IS NULL" + visible + ")")
+	}
+
+	if params.ParentID != 0 {
+		if params.Threaded {
+			arg := q.Arg(params.ParentID)
+			q.Where("c.path @> ARRAY[" + arg + "::integer] AND c.id <> " + arg)
+		} else {
+			q.Where("c.parent_id = " + q.Arg(params.ParentID))
+		}
+	}
+
+	if params.MaxDepth != nil {
+		q.Where("c.depth <= " + q.Arg(*params.MaxDepth))
+	}
 
-	filter := " WHERE true "
 	if params.UserID != 0 {
-		filter += fmt.Sprintf(" AND c.user_id = %d", params.UserID)
+		q.Where("c.user_id = " + q.Arg(params.UserID))
+	}
+
+	if params.ModeratorID != 0 {
+		q.Where(`EXISTS (
+			SELECT 1 FROM post_contributors pc WHERE pc.post_id = c.post_id AND pc.user_id = ` + q.Arg(params.ModeratorID) + `
+		)`)
 	}
 
 	if params.PostID != 0 {
-		filter += fmt.Sprintf(" AND c.post_id = %d", params.PostID)
+		q.Where("c.post_id = " + q.Arg(params.PostID))
+	}
+
+	err := q.Sort(params.Sort, commentSortFields, defaultSort, "c.id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.Keyset(params.After, params.Before)
+	if err != nil {
+		return nil, err
 	}
 
 	query := `
@@ -160,51 +440,80 @@ func (pr *commentRepo) GetAll(params *repo.GetCommentsParams) (*repo.GetAllComme
 			c.id,
 			c.post_id,
 			c.user_id,
+			c.parent_id,
 			c.description,
 			c.created_at,
 			c.updated_at,
+			c.deleted_at,
+			c.depth,
+			c.path,
+			` + commentReplyCountColumn + `,
+			c.status,
+			c.moderated_by,
+			c.moderated_at,
+			` + mentionsColumn(repo.MentionTargetComment, "c.id") + `,
+			c.edit_count,
 			u.first_name,
 			u.last_name,
 			u.email,
-			u.profile_image_url
+			u.profile_image_url` + q.CursorColumns() + `
 		FROM comments c 
 		INNER JOIN users u 	ON c.user_id = u.id 
-	` + filter + `
-	ORDER BY c.created_at DESC` + limit
+	` + q.ListFilter() + q.Order() + q.Paginate(params.Limit, params.Page)
 
-	rows, err := pr.db.Query(query)
+	rows, err := pr.db.Query(query, q.Args()...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var cursors []*repo.Cursor
 	for rows.Next() {
 		var comment repo.Comment
-		err := rows.Scan(
+		cursor, cursorDest := q.CursorDest()
+		err := rows.Scan(append([]interface{}{
 			&comment.ID,
 			&comment.PostID,
 			&comment.UserID,
+			&comment.ParentID,
 			&comment.Description,
 			&comment.CreatedAt,
 			&comment.UpdatedAt,
+			&comment.DeletedAt,
+			&comment.Depth,
+			pq.Array(&comment.Path),
+			&comment.ReplyCount,
+			&comment.Status,
+			&comment.ModeratedBy,
+			&comment.ModeratedAt,
+			(*mentionList)(&comment.Mentions),
+			&comment.EditCount,
 			&comment.User.FirstName,
 			&comment.User.LastName,
 			&comment.User.Email,
 			&comment.User.ProfileImageUrl,
-		)
+		}, cursorDest...)...)
 		if err != nil {
 			return nil, err
 		}
 
 		result.Comments = append(result.Comments, &comment)
+		cursors = append(cursors, cursor)
 	}
 
-	queryCount := "SELECT count(1) FROM comments c INNER JOIN users u ON u.id = c.user_id" + filter
+	queryCount := "SELECT count(1) FROM comments c INNER JOIN users u ON u.id = c.user_id" + q.Filter()
 
-	err = pr.db.QueryRow(queryCount).Scan(&result.Count)
+	err = pr.db.QueryRow(queryCount, q.FilterArgs()...).Scan(&result.Count)
 	if err != nil {
 		return nil, err
 	}
 
+	keep, next, prev := q.Cursors(cursors, result.Count)
+	result.Comments = result.Comments[:keep]
+	if q.Backward() {
+		reverseRows(result.Comments)
+	}
+	result.NextCursor, result.PrevCursor = next, prev
+
 	return &result, nil
 }
@@ -2,6 +2,7 @@ package postgres_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/bxcodec/faker/v4"
 	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
@@ -46,6 +47,49 @@ func TestGetAllComment(t *testing.T) {
 	deleteComment(t, c.ID)
 }
 
+func TestGetAllCommentsPostVisibility(t *testing.T) {
+	user := createUser(t)
+	category := createCategory(t)
+	password := "hashed"
+
+	post, err := dbManager.Post().Create(&repo.Post{
+		Title:       faker.Sentence(),
+		Description: faker.Sentence(),
+		Visibility:  repo.PostVisibilityPassword,
+		Password:    &password,
+		Language:    "uz",
+		UserID:      user.ID,
+		CategoryID:  category.ID,
+	})
+	require.NoError(t, err)
+
+	c, err := dbManager.Comment().Create(&repo.Comment{
+		PostID:      post.ID,
+		UserID:      user.ID,
+		Description: faker.Sentence(),
+		Status:      repo.CommentStatusApproved,
+	})
+	require.NoError(t, err)
+
+	count := func(params *repo.GetCommentsParams) int {
+		params.Limit, params.PostID = 10, post.ID
+		result, err := dbManager.Comment().GetAll(params)
+		require.NoError(t, err)
+		return len(result.Comments)
+	}
+
+	require.Zero(t, count(&repo.GetCommentsParams{}))
+	require.Zero(t, count(&repo.GetCommentsParams{ViewerID: user.ID + 1}))
+	require.Equal(t, 1, count(&repo.GetCommentsParams{UnlockedPostID: post.ID}))
+	require.Equal(t, 1, count(&repo.GetCommentsParams{ViewerID: user.ID}))
+	require.Equal(t, 1, count(&repo.GetCommentsParams{IsSuperadmin: true}))
+
+	deleteComment(t, c.ID)
+	deletePost(t, post.ID)
+	deleteUser(t, user.ID)
+	deleteCategory(t, category.ID)
+}
+
 func TestUpdateComment(t *testing.T) {
 	c := createComment(t)
 	post := createPost(t)
@@ -68,3 +112,166 @@ func TestDeleteComment(t *testing.T) {
 	require.NotEmpty(t, c)
 	deleteComment(t, c.ID)
 }
+
+func TestCommentThreads(t *testing.T) {
+	post := createPost(t)
+
+	reply := func(parent *repo.Comment) *repo.Comment {
+		c := repo.Comment{
+			PostID:      post.ID,
+			UserID:      post.UserID,
+			Description: faker.Sentence(),
+		}
+		if parent != nil {
+			c.ParentID = &parent.ID
+		}
+		created, err := dbManager.Comment().Create(&c)
+		require.NoError(t, err)
+		return created
+	}
+
+	root := reply(nil)
+	child := reply(root)
+	grandchild := reply(child)
+	require.Equal(t, int32(2), grandchild.Depth)
+	require.Equal(t, []int64{root.ID, child.ID, grandchild.ID}, grandchild.Path)
+
+	got, err := dbManager.Comment().Get(child.ID)
+	require.NoError(t, err)
+	require.Equal(t, root.ID, *got.ParentID)
+	require.Equal(t, int64(1), got.ReplyCount)
+
+	deleteComment(t, child.ID)
+
+	result, err := dbManager.Comment().GetAll(&repo.GetCommentsParams{
+		Limit:    10,
+		Page:     1,
+		PostID:   post.ID,
+		Threaded: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Comments, 3)
+	require.Equal(t, child.ID, result.Comments[1].ID)
+	require.NotNil(t, result.Comments[1].DeletedAt)
+	require.Equal(t, grandchild.ID, result.Comments[2].ID)
+
+	maxDepth := int32(1)
+	result, err = dbManager.Comment().GetAll(&repo.GetCommentsParams{
+		Limit:    10,
+		Page:     1,
+		ParentID: root.ID,
+		Threaded: true,
+		MaxDepth: &maxDepth,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Comments, 1)
+	require.Equal(t, child.ID, result.Comments[0].ID)
+
+	// the deleted child stays as long as the grandchild is around
+	_, err = dbManager.Comment().PurgeDeleted(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, dbManager.Comment().Restore(child.ID, 0))
+
+	deletePost(t, post.ID)
+}
+
+func TestCommentModeration(t *testing.T) {
+	post := createPost(t)
+	user := createUser(t)
+
+	comment, err := dbManager.Comment().Create(&repo.Comment{
+		PostID:      post.ID,
+		UserID:      user.ID,
+		Description: faker.Sentence(),
+		Status:      repo.CommentStatusPending,
+	})
+	require.NoError(t, err)
+
+	approved, err := dbManager.Comment().HasApproved(user.ID)
+	require.NoError(t, err)
+	require.False(t, approved)
+
+	result, err := dbManager.Comment().GetAll(&repo.GetCommentsParams{
+		Limit:       10,
+		Page:        1,
+		Status:      repo.CommentStatusPending,
+		ModeratorID: post.UserID,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Comments, 1)
+	require.Equal(t, comment.ID, result.Comments[0].ID)
+
+	// only contributors of the post can moderate its comments
+	n, err := dbManager.Comment().Moderate(&repo.ModerateComments{
+		IDs:         []int64{comment.ID},
+		Status:      repo.CommentStatusApproved,
+		ModeratorID: user.ID,
+		UserID:      user.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = dbManager.Comment().Moderate(&repo.ModerateComments{
+		IDs:         []int64{comment.ID},
+		Status:      repo.CommentStatusApproved,
+		ModeratorID: post.UserID,
+		UserID:      post.UserID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	got, err := dbManager.Comment().Get(comment.ID)
+	require.NoError(t, err)
+	require.Equal(t, repo.CommentStatusApproved, got.Status)
+	require.Equal(t, post.UserID, *got.ModeratedBy)
+
+	approved, err = dbManager.Comment().HasApproved(user.ID)
+	require.NoError(t, err)
+	require.True(t, approved)
+
+	deletePost(t, post.ID)
+	deleteUser(t, user.ID)
+}
+
+func TestCommentRevisions(t *testing.T) {
+	post := createPost(t)
+	user := createUser(t)
+	original := faker.Sentence()
+
+	c, err := dbManager.Comment().Create(&repo.Comment{
+		PostID:      post.ID,
+		UserID:      user.ID,
+		Description: original,
+	})
+	require.NoError(t, err)
+
+	edited, err := dbManager.Comment().Update(&repo.UpdateComment{
+		ID:          c.ID,
+		Description: "edited",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), edited.EditCount)
+	editedAt := edited.UpdatedAt
+
+	// saving the same description isn't an edit
+	edited, err = dbManager.Comment().Update(&repo.UpdateComment{
+		ID:          c.ID,
+		Description: "edited",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), edited.EditCount)
+	require.True(t, editedAt.Equal(*edited.UpdatedAt))
+
+	revisions, err := dbManager.Comment().GetRevisions(c.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, original, revisions[0].Description)
+
+	got, err := dbManager.Comment().Get(c.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), got.EditCount)
+
+	deleteComment(t, c.ID)
+	deletePost(t, post.ID)
+	deleteUser(t, user.ID)
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+type curationRepo struct {
+	db *sqlx.DB
+}
+
+func NewCuration(db *sqlx.DB) repo.CurationStorageI {
+	return &curationRepo{
+		db: db,
+	}
+}
+
+func (cr *curationRepo) Pin(p *repo.Pin) (*repo.Pin, error) {
+	query := `
+		INSERT INTO post_pins(post_id, category_id, position, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (post_id, coalesce(category_id, 0)) DO UPDATE SET
+			position = EXCLUDED.position,
+			expires_at = EXCLUDED.expires_at
+		RETURNING created_at
+	`
+
+	err := cr.db.QueryRow(
+		query,
+		p.PostID,
+		p.CategoryID,
+		p.Position,
+		p.ExpiresAt,
+	).Scan(&p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (cr *curationRepo) Unpin(post_id, categoryID int64) error {
+	query := "DELETE FROM post_pins WHERE post_id = $1 AND coalesce(category_id, 0) = $2"
+
+	row, err := cr.db.Exec(query, post_id, categoryID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (cr *curationRepo) GetPins() ([]*repo.Pin, error) {
+	query := `
+		SELECT
+			pp.post_id,
+			pp.category_id,
+			pp.position,
+			pp.expires_at,
+			pp.created_at
+		FROM post_pins pp
+		INNER JOIN posts p ON p.id = pp.post_id AND p.deleted_at IS NULL
+		WHERE pp.expires_at IS NULL OR pp.expires_at > CURRENT_TIMESTAMP
+		ORDER BY pp.category_id NULLS FIRST, pp.position, pp.created_at
+	`
+
+	rows, err := cr.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pins := make([]*repo.Pin, 0)
+	for rows.Next() {
+		var p repo.Pin
+		err := rows.Scan(
+			&p.PostID,
+			&p.CategoryID,
+			&p.Position,
+			&p.ExpiresAt,
+			&p.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		pins = append(pins, &p)
+	}
+
+	return pins, rows.Err()
+}
+
+func (cr *curationRepo) SetFeatured(postIDs []int64) error {
+	tx, err := cr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM featured_posts")
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO featured_posts(post_id, position)
+		SELECT v.id, v.position
+		FROM unnest($1::bigint[]) WITH ORDINALITY AS v(id, position)
+		INNER JOIN posts p ON p.id = v.id AND p.deleted_at IS NULL
+	`
+
+	row, err := tx.Exec(query, pq.Array(postIDs))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return repo.ErrInvalidFeaturedPosts
+		}
+		return err
+	}
+
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected != int64(len(postIDs)) {
+		return repo.ErrInvalidFeaturedPosts
+	}
+
+	return tx.Commit()
+}
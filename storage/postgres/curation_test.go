@@ -0,0 +1,69 @@
+package postgres_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinPost(t *testing.T) {
+	post := createPost(t)
+	expired := time.Now().Add(-time.Hour)
+
+	_, err := dbManager.Curation().Pin(&repo.Pin{PostID: post.ID})
+	require.NoError(t, err)
+
+	posts, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit:       1,
+		Page:        1,
+		Sort:        []*repo.SortField{{Field: "created_at"}},
+		PinnedFirst: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, post.ID, posts.Posts[0].ID)
+
+	_, err = dbManager.Curation().Pin(&repo.Pin{PostID: post.ID, CategoryID: &post.CategoryID, ExpiresAt: &expired})
+	require.NoError(t, err)
+
+	pins, err := dbManager.Curation().GetPins()
+	require.NoError(t, err)
+	var found int
+	for _, p := range pins {
+		if p.PostID == post.ID {
+			found++
+			require.Nil(t, p.CategoryID)
+		}
+	}
+	require.Equal(t, 1, found)
+
+	require.NoError(t, dbManager.Curation().Unpin(post.ID, 0))
+	require.NoError(t, dbManager.Curation().Unpin(post.ID, post.CategoryID))
+	require.ErrorIs(t, dbManager.Curation().Unpin(post.ID, 0), sql.ErrNoRows)
+
+	deletePost(t, post.ID)
+}
+
+func TestFeaturedPosts(t *testing.T) {
+	first := createPost(t)
+	second := createPost(t)
+
+	require.ErrorIs(t, dbManager.Curation().SetFeatured([]int64{first.ID, first.ID}), repo.ErrInvalidFeaturedPosts)
+	require.NoError(t, dbManager.Curation().SetFeatured([]int64{first.ID, second.ID}))
+
+	posts, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit:    10,
+		Page:     1,
+		Featured: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, posts.Posts, 2)
+	require.Equal(t, first.ID, posts.Posts[0].ID)
+	require.Equal(t, second.ID, posts.Posts[1].ID)
+
+	require.NoError(t, dbManager.Curation().SetFeatured(nil))
+	deletePost(t, first.ID)
+	deletePost(t, second.ID)
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+type filterRepo struct {
+	db *sqlx.DB
+}
+
+func NewFilter(db *sqlx.DB) repo.FilterStorageI {
+	return &filterRepo{
+		db: db,
+	}
+}
+
+func (fr *filterRepo) Record(o *repo.FilterOutcome) (*repo.FilterOutcome, error) {
+	query := `
+		INSERT INTO filter_outcomes(
+			target_type,
+			target_id,
+			user_id,
+			action,
+			reasons,
+			content_hash
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	if o.Reasons == nil {
+		o.Reasons = []string{}
+	}
+
+	err := fr.db.QueryRow(
+		query,
+		o.TargetType,
+		o.TargetID,
+		o.UserID,
+		o.Action,
+		pq.Array(o.Reasons),
+		o.ContentHash,
+	).Scan(
+		&o.ID,
+		&o.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+func (fr *filterRepo) CountDuplicates(hash string, userID int64, targetType string, targetID int64, since time.Time) (int64, int64, error) {
+	query := `
+		SELECT
+			count(1) FILTER (WHERE user_id = $2),
+			count(DISTINCT user_id) FILTER (WHERE user_id <> $2)
+		FROM filter_outcomes
+		WHERE content_hash = $1 AND created_at >= $5
+			AND (target_type <> $3 OR target_id IS DISTINCT FROM $4)
+	`
+
+	var own, others int64
+	err := fr.db.QueryRow(
+		query,
+		hash,
+		userID,
+		targetType,
+		targetID,
+		since,
+	).Scan(&own, &others)
+
+	return own, others, err
+}
+
+func (fr *filterRepo) GetAll(params *repo.GetFilterOutcomesParams) (*repo.GetAllFilterOutcomesResult, error) {
+	result := repo.GetAllFilterOutcomesResult{
+		Outcomes: make([]*repo.FilterOutcome, 0),
+	}
+
+	q := newListQuery()
+
+	if params.TargetType != "" {
+		q.Where("f.target_type = " + q.Arg(params.TargetType))
+	}
+
+	if params.TargetID != 0 {
+		q.Where("f.target_id = " + q.Arg(params.TargetID))
+	}
+
+	if params.UserID != 0 {
+		q.Where("f.user_id = " + q.Arg(params.UserID))
+	}
+
+	if params.Action != "" {
+		q.Where("f.action = " + q.Arg(params.Action))
+	}
+
+	q.OrderBy("f.id", true)
+
+	query := `
+		SELECT
+			f.id,
+			f.target_type,
+			f.target_id,
+			f.user_id,
+			f.action,
+			f.reasons,
+			f.content_hash,
+			f.created_at
+		FROM filter_outcomes f
+	` + q.ListFilter() + q.Order() + q.Paginate(params.Limit, params.Page)
+
+	rows, err := fr.db.Query(query, q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o repo.FilterOutcome
+		err := rows.Scan(
+			&o.ID,
+			&o.TargetType,
+			&o.TargetID,
+			&o.UserID,
+			&o.Action,
+			pq.Array(&o.Reasons),
+			&o.ContentHash,
+			&o.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Outcomes = append(result.Outcomes, &o)
+	}
+
+	err = fr.db.QueryRow("SELECT count(1) FROM filter_outcomes f"+q.Filter(), q.FilterArgs()...).Scan(&result.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,58 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterOutcomes(t *testing.T) {
+	user := createUser(t)
+	other := createUser(t)
+	since := time.Now().Add(-time.Minute)
+	hash := "test-" + time.Now().Format(time.RFC3339Nano)
+
+	commentID := int64(1)
+	outcome, err := dbManager.Filter().Record(&repo.FilterOutcome{
+		TargetType:  "comment",
+		TargetID:    &commentID,
+		UserID:      user.ID,
+		Action:      "hold",
+		Reasons:     []string{"2 links, at most 1 allowed"},
+		ContentHash: hash,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, outcome.ID)
+
+	_, err = dbManager.Filter().Record(&repo.FilterOutcome{
+		TargetType:  "comment",
+		UserID:      other.ID,
+		Action:      "reject",
+		ContentHash: hash,
+	})
+	require.NoError(t, err)
+
+	own, others, err := dbManager.Filter().CountDuplicates(hash, user.ID, "comment", 0, since)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), own)
+	require.Equal(t, int64(1), others)
+
+	// editing the comment doesn't duplicate it
+	own, _, err = dbManager.Filter().CountDuplicates(hash, user.ID, "comment", commentID, since)
+	require.NoError(t, err)
+	require.Zero(t, own)
+
+	result, err := dbManager.Filter().GetAll(&repo.GetFilterOutcomesParams{
+		Limit:  10,
+		Page:   1,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Count)
+	require.Equal(t, outcome.Reasons, result.Outcomes[0].Reasons)
+
+	deleteUser(t, user.ID)
+	deleteUser(t, other.ID)
+}
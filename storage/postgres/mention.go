@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+type mentionRepo struct {
+	db *sqlx.DB
+}
+
+func NewMention(db *sqlx.DB) repo.MentionStorageI {
+	return &mentionRepo{
+		db: db,
+	}
+}
+
+// mentionsColumn selects the users mentioned in the content of the kind
+// with the id idColumn as JSON, in the order they were mentioned.
+func mentionsColumn(targetType, idColumn string) string {
+	return `coalesce((
+	SELECT json_agg(json_build_object(
+		'user_id', u.id,
+		'username', u.username
+	) ORDER BY m.created_at, u.id)
+	FROM mentions m
+	INNER JOIN users u ON u.id = m.user_id
+	WHERE m.target_type = '` + targetType + `' AND m.target_id = ` + idColumn + `
+), '[]')`
+}
+
+// mentionList reads the JSON of mentionsColumn.
+type mentionList []*repo.Mention
+
+func (m *mentionList) Scan(src interface{}) error {
+	data, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported mentions type %T", src)
+	}
+	return json.Unmarshal(data, m)
+}
+
+// mentionTargets tells whether the content of a kind can be seen, and its
+// post, for the content aliased t.
+var mentionTargets = map[string]struct {
+	table   string
+	visible string
+	postID  string
+}{
+	repo.MentionTargetPost: {
+		table:   "posts",
+		visible: "t.deleted_at IS NULL AND t.visibility = 'public'",
+		postID:  "t.id",
+	},
+	repo.MentionTargetComment: {
+		table: "comments",
+		visible: `t.deleted_at IS NULL AND t.status = 'approved' AND EXISTS (
+			SELECT 1 FROM posts p WHERE p.id = t.post_id AND p.deleted_at IS NULL AND p.visibility = 'public'
+		)`,
+		postID: "t.post_id",
+	},
+}
+
+func (mr *mentionRepo) Set(targetType string, targetID, mentionedBy int64, usernames []string) ([]*repo.Mention, error) {
+	tx, err := mr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM mentions m
+		WHERE m.target_type = $1 AND m.target_id = $2 AND NOT EXISTS (
+			SELECT 1 FROM users u WHERE u.id = m.user_id AND u.username = ANY($3)
+		)
+	`, targetType, targetID, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO mentions(target_type, target_id, user_id, mentioned_by)
+		SELECT $1, $2, u.id, $3 FROM users u WHERE u.username = ANY($4)
+		ON CONFLICT (target_type, target_id, user_id) DO NOTHING
+	`, targetType, targetID, mentionedBy, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+
+	var mentions mentionList
+	err = tx.QueryRow("SELECT "+mentionsColumn(targetType, "$1"), targetID).Scan(&mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	return mentions, tx.Commit()
+}
+
+func (mr *mentionRepo) ClaimNotifications(targetType string, targetIDs []int64) ([]*repo.Mention, error) {
+	target, ok := mentionTargets[targetType]
+	if !ok {
+		return nil, fmt.Errorf("unknown mention target %q", targetType)
+	}
+
+	query := `
+		UPDATE mentions m SET
+			notified_at = CURRENT_TIMESTAMP
+		FROM ` + target.table + ` t, users u
+		WHERE m.target_type = $1 AND m.target_id = ANY($2) AND m.notified_at IS NULL
+			AND m.mentioned_by IS DISTINCT FROM m.user_id
+			AND t.id = m.target_id AND ` + target.visible + `
+			AND u.id = m.user_id
+		RETURNING
+			m.target_type,
+			m.target_id,
+			` + target.postID + `,
+			m.user_id,
+			u.username,
+			m.mentioned_by,
+			m.created_at
+	`
+
+	rows, err := mr.db.Query(query, targetType, pq.Array(targetIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mentions := make([]*repo.Mention, 0)
+	for rows.Next() {
+		var m repo.Mention
+		err := rows.Scan(
+			&m.TargetType,
+			&m.TargetID,
+			&m.PostID,
+			&m.UserID,
+			&m.Username,
+			&m.MentionedBy,
+			&m.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		mentions = append(mentions, &m)
+	}
+
+	return mentions, rows.Err()
+}
@@ -0,0 +1,55 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMentions(t *testing.T) {
+	post := createPost(t)
+	author := createUser(t)
+
+	username := "mentioned_" + time.Now().Format("150405.000000")
+	user := createUser(t)
+	user.UserName = &username
+	_, err := dbManager.User().Update(user)
+	require.NoError(t, err)
+
+	comment, err := dbManager.Comment().Create(&repo.Comment{
+		PostID:      post.ID,
+		UserID:      author.ID,
+		Description: "@" + username + " and @nobody_here",
+	})
+	require.NoError(t, err)
+
+	mentions, err := dbManager.Mention().Set(repo.MentionTargetComment, comment.ID, author.ID, []string{username, "nobody_here"})
+	require.NoError(t, err)
+	require.Len(t, mentions, 1)
+	require.Equal(t, user.ID, mentions[0].UserID)
+
+	got, err := dbManager.Comment().Get(comment.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Mentions, 1)
+
+	// mentions are only notified once
+	claimed, err := dbManager.Mention().ClaimNotifications(repo.MentionTargetComment, []int64{comment.ID})
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, post.ID, claimed[0].PostID)
+
+	claimed, err = dbManager.Mention().ClaimNotifications(repo.MentionTargetComment, []int64{comment.ID})
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+
+	mentions, err = dbManager.Mention().Set(repo.MentionTargetComment, comment.ID, author.ID, []string{})
+	require.NoError(t, err)
+	require.Empty(t, mentions)
+
+	deleteComment(t, comment.ID)
+	deletePost(t, post.ID)
+	deleteUser(t, user.ID)
+	deleteUser(t, author.ID)
+}
@@ -1,10 +1,17 @@
 package postgres
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/utils"
 	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
 )
 
@@ -18,25 +25,124 @@ func NewPost(db *sqlx.DB) repo.PostStorageI {
 	}
 }
 
+// postTagsColumn selects the tag names of the post p.
+const postTagsColumn = `coalesce((
+	SELECT array_agg(t.name ORDER BY t.name)
+	FROM post_tags pt
+	INNER JOIN tags t ON t.id = pt.tag_id
+	WHERE pt.post_id = p.id
+), '{}')`
+
+// liveCategory tells whether the category of the post p is out of the
+// trash. Posts of a deleted category are hidden until it is restored.
+const liveCategory = `EXISTS (SELECT 1 FROM categories cat WHERE cat.id = p.category_id AND cat.deleted_at IS NULL)`
+
+// postTranslationsColumn selects the languages the post p is translated to.
+const postTranslationsColumn = `ARRAY(
+	SELECT pt.language FROM post_translations pt WHERE pt.post_id = p.id ORDER BY pt.language
+)`
+
+// postContributorsColumn selects the contributors of the post p as JSON,
+// the author first.
+const postContributorsColumn = `coalesce((
+	SELECT json_agg(json_build_object(
+		'user_id', u.id,
+		'role', pc.role,
+		'first_name', u.first_name,
+		'last_name', u.last_name,
+		'username', u.username,
+		'profile_image_url', u.profile_image_url
+	) ORDER BY pc.role <> 'author', pc.created_at)
+	FROM post_contributors pc
+	INNER JOIN users u ON u.id = pc.user_id
+	WHERE pc.post_id = p.id
+), '[]')`
+
+// postContributors reads the JSON of postContributorsColumn.
+type postContributors []*repo.PostContributor
+
+func (c *postContributors) Scan(src interface{}) error {
+	data, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported contributors type %T", src)
+	}
+	return json.Unmarshal(data, c)
+}
+
+// postHeadings stores the table of contents of a post as JSON.
+type postHeadings []*repo.PostHeading
+
+func (h postHeadings) Value() (driver.Value, error) {
+	if h == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(h)
+}
+
+func (h *postHeadings) Scan(src interface{}) error {
+	data, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported table of contents type %T", src)
+	}
+	return json.Unmarshal(data, h)
+}
+
 func (pr *postRepo) Create(p *repo.Post) (*repo.Post, error) {
+	if p.Visibility == "" {
+		p.Visibility = repo.PostVisibilityPublic
+	}
+	if p.CommentStatus == "" {
+		p.CommentStatus = repo.PostCommentsOpen
+	}
+
 	query := `
 		INSERT INTO posts(
 			title,
 			description,
+			description_html,
+			table_of_contents,
+			reading_time,
 			image_url,
+			visibility,
+			password,
 			user_id,
-			category_id
-		) VALUES ($1, $2, $3, $4, $5)
+			category_id,
+			created_at,
+			updated_at,
+			language,
+			comment_status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, coalesce($11, CURRENT_TIMESTAMP), $12, $13, $14)
 		RETURNING id, created_at
-	d`
+	`
 
-	err := pr.db.QueryRow(
+	// imported posts keep their dates
+	var createdAt *time.Time
+	if !p.CreatedAt.IsZero() {
+		createdAt = &p.CreatedAt
+	}
+
+	tx, err := pr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
 		query,
 		p.Title,
 		p.Description,
+		p.DescriptionHtml,
+		postHeadings(p.TableOfContents),
+		p.ReadingTime,
 		p.ImageUrl,
+		p.Visibility,
+		p.Password,
 		p.UserID,
 		p.CategoryID,
+		createdAt,
+		p.UpdatedAt,
+		p.Language,
+		p.CommentStatus,
 	).Scan(
 		&p.ID,
 		&p.CreatedAt,
@@ -46,47 +152,116 @@ func (pr *postRepo) Create(p *repo.Post) (*repo.Post, error) {
 		return nil, err
 	}
 
-	return p, nil
-}
+	if p.Tags == nil {
+		p.Tags = []string{}
+	}
+	p.Translations = []string{}
 
-func (pr *postRepo) Get(post_id int64) (*repo.Post, error) {
-	var (
-		res repo.Post
+	err = setPostTags(tx, p.ID, p.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO post_contributors(post_id, user_id, role) VALUES ($1, $2, $3)",
+		p.ID,
+		p.UserID,
+		repo.ContributorRoleAuthor,
 	)
-	queryViews := "UPDATE posts SET views_count = views_count + 1 WHERE id = $1"
-	_, err := pr.db.Exec(queryViews, post_id)
 	if err != nil {
 		return nil, err
 	}
 
+	err = tx.QueryRow(
+		"SELECT "+postContributorsColumn+" FROM posts p WHERE p.id = $1",
+		p.ID,
+	).Scan((*postContributors)(&p.Contributors))
+	if err != nil {
+		return nil, err
+	}
+
+	return p, tx.Commit()
+}
+
+// setPostTags replaces the tags of a post, creating the missing ones.
+func setPostTags(tx *sql.Tx, postID int64, tags []string) error {
+	_, err := tx.Exec("DELETE FROM post_tags WHERE post_id = $1", postID)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	_, err = tx.Exec("INSERT INTO tags(name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING", pq.Array(tags))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO post_tags(post_id, tag_id) SELECT $1, id FROM tags WHERE name = ANY($2::text[])",
+		postID,
+		pq.Array(tags),
+	)
+
+	return err
+}
+
+func (pr *postRepo) Get(post_id int64) (*repo.Post, error) {
+	var (
+		res repo.Post
+	)
 	query := `
 		SELECT
 			p.id,
 			p.title,
 			p.description,
+			p.description_html,
+			p.table_of_contents,
+			p.reading_time,
 			p.image_url,
+			p.visibility,
+			p.password,
+			` + postTagsColumn + `,
+			` + postContributorsColumn + `,
 			p.user_id,
 			p.category_id,
 			p.created_at,
 			p.updated_at,
-			p.views_count
+			p.views_count,
+			p.language,
+			` + postTranslationsColumn + `,
+			p.comment_status,
+			` + mentionsColumn(repo.MentionTargetPost, "p.id") + `
 		FROM posts p 
-		WHERE p.id = $1 
+		WHERE p.id = $1 AND p.deleted_at IS NULL AND ` + liveCategory + `
 	`
 
-	err = pr.db.QueryRow(
+	err := pr.db.QueryRow(
 		query,
 		post_id,
 	).Scan(
 		&res.ID,
 		&res.Title,
 		&res.Description,
+		&res.DescriptionHtml,
+		(*postHeadings)(&res.TableOfContents),
+		&res.ReadingTime,
 		&res.ImageUrl,
+		&res.Visibility,
+		&res.Password,
+		pq.Array(&res.Tags),
+		(*postContributors)(&res.Contributors),
 		&res.UserID,
 		&res.CategoryID,
 		&res.CreatedAt,
 		&res.UpdatedAt,
 		&res.ViewsCount,
+		&res.Language,
+		pq.Array(&res.Translations),
+		&res.CommentStatus,
+		(*mentionList)(&res.Mentions),
 	)
 
 	if err != nil {
@@ -100,148 +275,737 @@ func (pr *postRepo) Update(p *repo.Post) (*repo.Post, error) {
 	var (
 		res repo.Post
 	)
+	// an empty visibility keeps the current one, and a post hidden by a
+	// report keeps it until the report is dismissed. The password is only
+	// dropped when the post is made public or unlisted, so one held private
+	// by the content filter can be password protected again
 	query := `
-		UPDATE posts SET
+		UPDATE posts p SET
 			title = $1,
 			description = $2,
-			image_url = $3,
-			user_id = $4,
-			category_id = $5,
-			views_count = $6,
-			updated_at = $7
-	    WHERE id = $8
+			description_html = $3,
+			table_of_contents = $4,
+			reading_time = $5,
+			image_url = $6,
+			visibility = CASE WHEN h.by_report THEN visibility ELSE coalesce(nullif($7, ''), visibility) END,
+			password = CASE
+				WHEN h.by_report THEN password
+				WHEN $7 IN ('public', 'unlisted') THEN NULL
+				WHEN $7 IN ('password', 'private') THEN coalesce($8, password)
+				ELSE password
+			END,
+			category_id = $9,
+			updated_at = $10,
+			language = coalesce(nullif($12, ''), language),
+			comment_status = coalesce(nullif($13, ''), comment_status)
+		FROM (
+			SELECT EXISTS (
+				SELECT 1 FROM reports r WHERE r.target_type = 'post' AND r.target_id = $11 AND r.hidden
+			) AS by_report
+		) h
+	    WHERE id = $11 AND deleted_at IS NULL
 		RETURNING 
 			id,
 			title,
 			description,
+			description_html,
+			table_of_contents,
+			reading_time,
 			image_url,
+			visibility,
+			password,
+			` + postTagsColumn + `,
+			` + postContributorsColumn + `,
 			user_id,
 			category_id,
 			created_at,
 			updated_at,
-			views_count
+			views_count,
+			language,
+			` + postTranslationsColumn + `,
+			comment_status
 	`
 
-	err := pr.db.QueryRow(
+	tx, err := pr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
 		query,
 		p.Title,
 		p.Description,
+		p.DescriptionHtml,
+		postHeadings(p.TableOfContents),
+		p.ReadingTime,
 		p.ImageUrl,
-		p.UserID,
+		p.Visibility,
+		p.Password,
 		p.CategoryID,
-		p.ViewsCount,
 		time.Now(),
 		p.ID,
+		p.Language,
+		p.CommentStatus,
 	).Scan(
 		&res.ID,
 		&res.Title,
 		&res.Description,
+		&res.DescriptionHtml,
+		(*postHeadings)(&res.TableOfContents),
+		&res.ReadingTime,
 		&res.ImageUrl,
+		&res.Visibility,
+		&res.Password,
+		pq.Array(&res.Tags),
+		(*postContributors)(&res.Contributors),
 		&res.UserID,
 		&res.CategoryID,
 		&res.CreatedAt,
 		&res.UpdatedAt,
 		&res.ViewsCount,
+		&res.Language,
+		pq.Array(&res.Translations),
+		&res.CommentStatus,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &res, nil
+	// nil tags keep the current ones
+	if p.Tags != nil {
+		err = setPostTags(tx, res.ID, p.Tags)
+		if err != nil {
+			return nil, err
+		}
+		res.Tags = p.Tags
+	}
+
+	return &res, tx.Commit()
 }
 
 func (pr *postRepo) Delete(post_id int64) error {
 	query := `
-		DELETE FROM posts WHERE id = $1
+		UPDATE posts SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	row, err := pr.db.Exec(
+		query,
+		post_id,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (pr *postRepo) SetContributor(post_id, userID int64, role string) error {
+	query := `
+		INSERT INTO post_contributors(post_id, user_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (post_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		WHERE post_contributors.role <> 'author'
+	`
+
+	_, err := pr.db.Exec(query, post_id, userID, role)
+
+	return err
+}
+
+func (pr *postRepo) RemoveContributor(post_id, userID int64) error {
+	query := `
+		DELETE FROM post_contributors WHERE post_id = $1 AND user_id = $2 AND role <> 'author'
+	`
+
+	row, err := pr.db.Exec(query, post_id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (pr *postRepo) GetSEO(post_id int64) (*repo.PostSEO, error) {
+	res := repo.PostSEO{
+		PostID: post_id,
+	}
+
+	query := `
+		SELECT
+			meta_title,
+			meta_description,
+			canonical_url,
+			image_url,
+			updated_at
+		FROM post_seo WHERE post_id = $1
+	`
+
+	err := pr.db.QueryRow(query, post_id).Scan(
+		&res.MetaTitle,
+		&res.MetaDescription,
+		&res.CanonicalUrl,
+		&res.ImageUrl,
+		&res.UpdatedAt,
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (pr *postRepo) SetSEO(s *repo.PostSEO) (*repo.PostSEO, error) {
+	query := `
+		INSERT INTO post_seo(post_id, meta_title, meta_description, canonical_url, image_url)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (post_id) DO UPDATE SET
+			meta_title = EXCLUDED.meta_title,
+			meta_description = EXCLUDED.meta_description,
+			canonical_url = EXCLUDED.canonical_url,
+			image_url = EXCLUDED.image_url,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+
+	err := pr.db.QueryRow(
+		query,
+		s.PostID,
+		s.MetaTitle,
+		s.MetaDescription,
+		s.CanonicalUrl,
+		s.ImageUrl,
+	).Scan(&s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (pr *postRepo) SetTranslation(t *repo.PostTranslation) (*repo.PostTranslation, error) {
+	query := `
+		INSERT INTO post_translations(
+			post_id,
+			language,
+			title,
+			slug,
+			description,
+			description_html,
+			table_of_contents,
+			reading_time
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (post_id, language) DO UPDATE SET
+			title = EXCLUDED.title,
+			slug = EXCLUDED.slug,
+			description = EXCLUDED.description,
+			description_html = EXCLUDED.description_html,
+			table_of_contents = EXCLUDED.table_of_contents,
+			reading_time = EXCLUDED.reading_time,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING created_at, updated_at
+	`
+
+	err := pr.db.QueryRow(
+		query,
+		t.PostID,
+		t.Language,
+		t.Title,
+		t.Slug,
+		t.Description,
+		t.DescriptionHtml,
+		postHeadings(t.TableOfContents),
+		t.ReadingTime,
+	).Scan(
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return nil, repo.ErrSlugExists
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (pr *postRepo) DeleteTranslation(post_id int64, language string) error {
+	row, err := pr.db.Exec("DELETE FROM post_translations WHERE post_id = $1 AND language = $2", post_id, language)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (pr *postRepo) GetTranslations(postIDs []int64, languages []string) ([]*repo.PostTranslation, error) {
+	query := `
+		SELECT
+			post_id,
+			language,
+			title,
+			slug,
+			description,
+			description_html,
+			table_of_contents,
+			reading_time,
+			created_at,
+			updated_at
+		FROM post_translations
+		WHERE post_id = ANY($1) AND (cardinality($2::text[]) = 0 OR language = ANY($2))
+		ORDER BY post_id, language
+	`
+
+	if languages == nil {
+		languages = []string{}
+	}
+
+	rows, err := pr.db.Query(query, pq.Array(postIDs), pq.Array(languages))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make([]*repo.PostTranslation, 0)
+	for rows.Next() {
+		var t repo.PostTranslation
+		err := rows.Scan(
+			&t.PostID,
+			&t.Language,
+			&t.Title,
+			&t.Slug,
+			&t.Description,
+			&t.DescriptionHtml,
+			(*postHeadings)(&t.TableOfContents),
+			&t.ReadingTime,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		translations = append(translations, &t)
+	}
+
+	return translations, rows.Err()
+}
+
+func (pr *postRepo) GetTranslationBySlug(language, slug string) (*repo.PostTranslation, error) {
+	query := `
+		SELECT
+			post_id,
+			language,
+			title,
+			slug,
+			description,
+			description_html,
+			table_of_contents,
+			reading_time,
+			created_at,
+			updated_at
+		FROM post_translations
+		WHERE language = $1 AND slug = $2
+	`
+
+	var t repo.PostTranslation
+	err := pr.db.QueryRow(query, language, slug).Scan(
+		&t.PostID,
+		&t.Language,
+		&t.Title,
+		&t.Slug,
+		&t.Description,
+		&t.DescriptionHtml,
+		(*postHeadings)(&t.TableOfContents),
+		&t.ReadingTime,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// GetRelated scores every candidate with
+//
+//	3 for the same category
+//	2 for every shared tag
+//	5 * the trigram similarity of the titles
+//	ln(1 + users who liked both posts)
+//
+// Candidates have to match on at least one of these.
+func (pr *postRepo) GetRelated(post_id, limit int64) ([]*repo.RelatedPost, error) {
+	query := `
+		WITH target AS (
+			SELECT id, title, category_id FROM posts WHERE id = $1 AND deleted_at IS NULL
+		), co_likes AS (
+			SELECT l2.post_id, count(DISTINCT l2.user_id) AS users
+			FROM likes l1
+			INNER JOIN likes l2 ON l2.user_id = l1.user_id AND l2.status AND l2.post_id <> l1.post_id
+			WHERE l1.post_id = $1 AND l1.status
+			GROUP BY l2.post_id
+		), shared_tags AS (
+			SELECT b.post_id, count(1) AS tags
+			FROM post_tags a
+			INNER JOIN post_tags b ON b.tag_id = a.tag_id AND b.post_id <> a.post_id
+			WHERE a.post_id = $1
+			GROUP BY b.post_id
+		)
+		SELECT
+			p.id,
+			p.title,
+			p.image_url,
+			p.user_id,
+			p.category_id,
+			p.created_at,
+			` + postTagsColumn + `,
+			(CASE WHEN p.category_id = t.category_id THEN 3 ELSE 0 END)
+				+ 2 * coalesce(st.tags, 0)
+				+ 5 * similarity(p.title, t.title)
+				+ ln(1 + coalesce(cl.users, 0)) AS score
+		FROM posts p
+		CROSS JOIN target t
+		LEFT JOIN shared_tags st ON st.post_id = p.id
+		LEFT JOIN co_likes cl ON cl.post_id = p.id
+		WHERE p.id <> t.id AND p.deleted_at IS NULL AND p.visibility = 'public' AND ` + liveCategory + `
+			AND (p.category_id = t.category_id OR st.post_id IS NOT NULL OR cl.post_id IS NOT NULL OR p.title % t.title)
+		ORDER BY score DESC, p.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := pr.db.Query(query, post_id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*repo.RelatedPost, 0)
+	for rows.Next() {
+		var post repo.RelatedPost
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.ImageUrl,
+			&post.UserID,
+			&post.CategoryID,
+			&post.CreatedAt,
+			pq.Array(&post.Tags),
+			&post.Score,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &post)
+	}
+
+	return result, rows.Err()
+}
+
+func (pr *postRepo) GetPublicIDs(postIDs []int64) ([]int64, error) {
+	ids := make([]int64, 0, len(postIDs))
+	err := pr.db.Select(&ids, `
+		SELECT id FROM posts
+		WHERE id = ANY($1) AND deleted_at IS NULL AND visibility = 'public'
+	`, pq.Array(postIDs))
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AddViews adds buffered view counts, keyed by post id, in one statement.
+func (pr *postRepo) AddViews(views map[int64]int64) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(views))
+	counts := make([]int64, 0, len(views))
+	for id, count := range views {
+		ids = append(ids, id)
+		counts = append(counts, count)
+	}
+
+	query := `
+		UPDATE posts p SET views_count = p.views_count + v.count
+		FROM unnest($1::bigint[], $2::bigint[]) AS v(id, count)
+		WHERE p.id = v.id
 	`
 
-	_, err := pr.db.Exec(
+	_, err := pr.db.Exec(query, pq.Array(ids), pq.Array(counts))
+
+	return err
+}
+
+func (pr *postRepo) Restore(post_id, userID int64) error {
+	query := `
+		UPDATE posts SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL AND ($2 = 0 OR user_id = $2)
+	`
+
+	row, err := pr.db.Exec(
 		query,
 		post_id,
+		userID,
 	)
+	if err != nil {
+		return err
+	}
 
+	rowsAffected, err := row.RowsAffected()
 	if err != nil {
 		return err
 	}
 
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
 	return nil
 }
 
+func (pr *postRepo) PurgeDeleted(before time.Time) (int64, error) {
+	tx, err := pr.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	deleted := "SELECT id FROM posts WHERE deleted_at < $1"
+
+	_, err = tx.Exec("DELETE FROM likes WHERE post_id IN ("+deleted+")", before)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec("DELETE FROM comments WHERE post_id IN ("+deleted+")", before)
+	if err != nil {
+		return 0, err
+	}
+
+	row, err := tx.Exec("DELETE FROM posts WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := row.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return count, tx.Commit()
+}
+
+var postSortFields = map[string]string{
+	"created_at":     "p.created_at",
+	"updated_at":     "coalesce(p.updated_at, p.created_at)",
+	"title":          "p.title",
+	"views_count":    "p.views_count",
+	"likes_count":    "(SELECT count(1) FROM likes l WHERE l.post_id = p.id AND l.status)",
+	"comments_count": "(SELECT count(1) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL AND c.status = 'approved')",
+	"deleted_at":     "p.deleted_at",
+	"trending":       "coalesce((SELECT r.trending FROM post_rankings r WHERE r.post_id = p.id), 0)",
+	"top_week":       "coalesce((SELECT r.top_week FROM post_rankings r WHERE r.post_id = p.id), 0)",
+	"top_month":      "coalesce((SELECT r.top_month FROM post_rankings r WHERE r.post_id = p.id), 0)",
+	"top_all":        "coalesce((SELECT r.top_all FROM post_rankings r WHERE r.post_id = p.id), 0)",
+}
+
 func (pr *postRepo) GetAll(params *repo.GetPostsParams) (*repo.GetAllPostResult, error) {
 	result := repo.GetAllPostResult{
 		Posts: make([]*repo.Post, 0),
 	}
 
-	offset := (params.Page - 1) * params.Limit
-
-	limit := fmt.Sprintf(" LIMIT %d OFFSET %d", params.Limit, offset)
+	q := newListQuery()
+	search := ", 0, NULL"
+	defaultSort := []*repo.SortField{{Field: "created_at", Desc: true}}
+
+	if tsQuery := utils.ToTsQuery(params.Search); tsQuery != "" {
+		arg := q.Arg(tsQuery)
+		q.Where("p.search_vector @@ to_tsquery('simple', " + arg + ")")
+		search = `,
+			ts_rank_cd(p.search_vector, to_tsquery('simple', ` + arg + `)),
+			ts_headline('simple', ` + escapeHTML("p.description") + `, to_tsquery('simple', ` + arg + `), '` + headlineOptions + `')`
+		if len(params.Sort) == 0 && params.SortByDate == "" {
+			q.OrderBy("ts_rank_cd(p.search_vector, to_tsquery('simple', "+arg+"))", true)
+		}
+	}
 
-	filter := " WHERE true"
-	if params.Search != "" {
-		filter += " AND title ILIKE '%s'" + "%" + params.Search + "%"
+	switch {
+	case params.Deleted:
+		// the trash is filtered by its owner instead
+		q.Where("p.deleted_at IS NOT NULL")
+		defaultSort[0].Field = "deleted_at"
+	case params.AllVisibilities:
+	case params.IsSuperadmin:
+		q.Where("p.visibility <> 'unlisted'")
+	case params.ViewerID != 0:
+		q.Where(`(p.visibility IN ('public', 'password') OR p.visibility = 'private' AND EXISTS (
+			SELECT 1 FROM post_contributors pc WHERE pc.post_id = p.id AND pc.user_id = ` + q.Arg(params.ViewerID) + `
+		))`)
+	default:
+		q.Where("p.visibility IN ('public', 'password')")
+	}
+	if !params.Deleted {
+		q.Where("p.deleted_at IS NULL")
+	}
+	// exports keep the posts of deleted categories so nothing is lost
+	if !params.Deleted && !params.AllVisibilities {
+		q.Where(liveCategory)
 	}
 
 	if params.UserID != 0 {
-		filter += fmt.Sprintf(" AND user_id = %d", params.UserID)
+		q.Where("p.user_id = " + q.Arg(params.UserID))
 	}
 
 	if params.CategoryID != 0 {
-		filter += fmt.Sprintf(" AND category_id = %d", params.CategoryID)
+		q.Where("p.category_id = " + q.Arg(params.CategoryID))
 	}
 
-	orderBy := " ORDER BY created_at DESC"
 	if params.SortByDate != "" {
-		orderBy = fmt.Sprintf(" ORDER BY created_at %s", params.SortByDate)
+		defaultSort[0].Desc = strings.EqualFold(params.SortByDate, "desc")
+	}
+
+	if params.Featured {
+		q.Where("EXISTS (SELECT 1 FROM featured_posts f WHERE f.post_id = p.id)")
+		q.OrderBy("(SELECT f.position FROM featured_posts f WHERE f.post_id = p.id)", false)
+	}
+
+	if params.PinnedFirst {
+		scope := "pp.category_id IS NULL"
+		if params.CategoryID != 0 {
+			scope = "pp.category_id = p.category_id"
+		}
+		// posts that aren't pinned come after every pin position
+		q.OrderBy(`coalesce((
+			SELECT min(pp.position) FROM post_pins pp
+			WHERE pp.post_id = p.id AND `+scope+` AND (pp.expires_at IS NULL OR pp.expires_at > CURRENT_TIMESTAMP)
+		), 2147483647)`, false)
+	}
+
+	err := q.Sort(params.Sort, postSortFields, defaultSort, "p.id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.Keyset(params.After, params.Before)
+	if err != nil {
+		return nil, err
 	}
 
 	query := `
 		SELECT
-			id,
-			title,
-			description,
-			image_url,
-			user_id,
-			category_id,
-			created_at,
-			updated_at,
-			views_count
-		FROM posts
-	` + filter + orderBy + limit
-
-	rows, err := pr.db.Query(query)
+			p.id,
+			p.title,
+			p.description,
+			p.description_html,
+			p.table_of_contents,
+			p.reading_time,
+			p.image_url,
+			p.visibility,
+			p.password,
+			` + postTagsColumn + `,
+			` + postContributorsColumn + `,
+			p.user_id,
+			p.category_id,
+			p.created_at,
+			p.updated_at,
+			p.views_count,
+			p.deleted_at,
+			p.language,
+			` + postTranslationsColumn + `,
+			p.comment_status,
+			` + mentionsColumn(repo.MentionTargetPost, "p.id") + search + q.CursorColumns() + `
+		FROM posts p
+	` + q.ListFilter() + q.Order() + q.Paginate(params.Limit, params.Page)
+
+	rows, err := pr.db.Query(query, q.Args()...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var cursors []*repo.Cursor
 	for rows.Next() {
 		var post repo.Post
-		err := rows.Scan(
+		cursor, cursorDest := q.CursorDest()
+		err := rows.Scan(append([]interface{}{
 			&post.ID,
 			&post.Title,
 			&post.Description,
+			&post.DescriptionHtml,
+			(*postHeadings)(&post.TableOfContents),
+			&post.ReadingTime,
 			&post.ImageUrl,
+			&post.Visibility,
+			&post.Password,
+			pq.Array(&post.Tags),
+			(*postContributors)(&post.Contributors),
 			&post.UserID,
 			&post.CategoryID,
 			&post.CreatedAt,
 			&post.UpdatedAt,
 			&post.ViewsCount,
-		)
+			&post.DeletedAt,
+			&post.Language,
+			pq.Array(&post.Translations),
+			&post.CommentStatus,
+			(*mentionList)(&post.Mentions),
+			&post.SearchRank,
+			&post.Headline,
+		}, cursorDest...)...)
 		if err != nil {
 			return nil, err
 		}
 
 		result.Posts = append(result.Posts, &post)
+		cursors = append(cursors, cursor)
 	}
 
-	queryCount := "SELECT count(1) FROM posts " + filter
+	queryCount := "SELECT count(1) FROM posts p" + q.Filter()
 
-	err = pr.db.QueryRow(queryCount).Scan(&result.Count)
+	err = pr.db.QueryRow(queryCount, q.FilterArgs()...).Scan(&result.Count)
 
 	if err != nil {
 		return nil, err
 	}
 
+	keep, next, prev := q.Cursors(cursors, result.Count)
+	result.Posts = result.Posts[:keep]
+	if q.Backward() {
+		reverseRows(result.Posts)
+	}
+	result.NextCursor, result.PrevCursor = next, prev
+
 	return &result, nil
 }
@@ -1,6 +1,7 @@
 package postgres_test
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/bxcodec/faker/v4"
@@ -14,13 +15,15 @@ func createPost(t *testing.T) *repo.Post {
 	post, err := dbManager.Post().Create(&repo.Post{
 		Title:       "Facebook",
 		Description: "Facebook is stopped working on Meta Project",
+		Language:    "uz",
 		UserID:      user.ID,
 		CategoryID:  catefory.ID,
 	})
 	require.NoError(t, err)
 	require.NotEmpty(t, post)
 	deleteUser(t, user.ID)
-	deleteCategory(t, catefory.ID)
+	// posts of a deleted category are hidden, so it goes once the test is done
+	t.Cleanup(func() { deleteCategory(t, catefory.ID) })
 	return post
 }
 
@@ -53,6 +56,49 @@ func TestUpdatePost(t *testing.T) {
 	require.NotEmpty(t, p)
 }
 
+func TestUpdatePostPassword(t *testing.T) {
+	user := createUser(t)
+	category := createCategory(t)
+	password := "hashed"
+
+	post, err := dbManager.Post().Create(&repo.Post{
+		Title:       faker.Sentence(),
+		Description: faker.Sentence(),
+		Visibility:  repo.PostVisibilityPassword,
+		Password:    &password,
+		UserID:      user.ID,
+		CategoryID:  category.ID,
+	})
+	require.NoError(t, err)
+
+	update := func(visibility string) *repo.Post {
+		p, err := dbManager.Post().Update(&repo.Post{
+			ID:          post.ID,
+			Title:       post.Title,
+			Description: post.Description,
+			Visibility:  visibility,
+			UserID:      user.ID,
+			CategoryID:  category.ID,
+		})
+		require.NoError(t, err)
+		return p
+	}
+
+	// updated without a visibility, it stays protected
+	p := update("")
+	require.Equal(t, repo.PostVisibilityPassword, p.Visibility)
+	require.Equal(t, password, *p.Password)
+
+	// made private, as when the content filter holds it, the password stays
+	require.Equal(t, password, *update(repo.PostVisibilityPrivate).Password)
+	require.Equal(t, password, *update(repo.PostVisibilityPassword).Password)
+	require.Nil(t, update(repo.PostVisibilityPublic).Password)
+
+	deletePost(t, post.ID)
+	deleteUser(t, user.ID)
+	deleteCategory(t, category.ID)
+}
+
 func TestDeletePost(t *testing.T) {
 	post := createPost(t)
 	err := dbManager.Post().Delete(post.ID)
@@ -81,3 +127,356 @@ func TestGetAllPosts(t *testing.T) {
 	require.NoError(t, err)
 	deletePost(t, post.ID)
 }
+
+func TestGetAllPostsSort(t *testing.T) {
+	post := createPost(t)
+	require.NotEmpty(t, post)
+	posts, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit: 10,
+		Page:  1,
+		Sort: []*repo.SortField{
+			{Field: "likes_count", Desc: true},
+			{Field: "views_count", Desc: true},
+			{Field: "created_at"},
+		},
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(posts.Posts), 1)
+
+	_, err = dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit: 10,
+		Page:  1,
+		Sort:  []*repo.SortField{{Field: "id; DROP TABLE posts"}},
+	})
+	require.ErrorIs(t, err, repo.ErrInvalidSortField)
+	deletePost(t, post.ID)
+}
+
+func TestGetAllPostsCursor(t *testing.T) {
+	first := createPost(t)
+	second := createPost(t)
+
+	page, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit: 1,
+		Page:  1,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Posts, 1)
+	require.NotNil(t, page.NextCursor)
+
+	next, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit: 1,
+		After: page.NextCursor,
+	})
+	require.NoError(t, err)
+	require.Len(t, next.Posts, 1)
+	require.Less(t, next.Posts[0].ID, page.Posts[0].ID)
+	require.NotNil(t, next.PrevCursor)
+
+	prev, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit:  1,
+		Before: next.PrevCursor,
+	})
+	require.NoError(t, err)
+	require.Len(t, prev.Posts, 1)
+	require.Equal(t, page.Posts[0].ID, prev.Posts[0].ID)
+
+	deletePost(t, first.ID)
+	deletePost(t, second.ID)
+}
+
+func TestGetAllPostsVisibility(t *testing.T) {
+	user := createUser(t)
+	category := createCategory(t)
+	password := "hashed"
+
+	var ids []int64
+	for _, visibility := range []string{
+		repo.PostVisibilityPublic,
+		repo.PostVisibilityUnlisted,
+		repo.PostVisibilityPrivate,
+		repo.PostVisibilityPassword,
+	} {
+		post, err := dbManager.Post().Create(&repo.Post{
+			Title:       faker.Sentence(),
+			Description: faker.Sentence(),
+			Visibility:  visibility,
+			Password:    &password,
+			UserID:      user.ID,
+			CategoryID:  category.ID,
+		})
+		require.NoError(t, err)
+		ids = append(ids, post.ID)
+	}
+
+	visible := func(params *repo.GetPostsParams) map[string]bool {
+		params.Limit, params.UserID = 10, user.ID
+		posts, err := dbManager.Post().GetAll(params)
+		require.NoError(t, err)
+
+		result := make(map[string]bool)
+		for _, p := range posts.Posts {
+			result[p.Visibility] = true
+		}
+		return result
+	}
+
+	require.Equal(t, map[string]bool{"public": true, "password": true}, visible(&repo.GetPostsParams{}))
+	require.Equal(t, map[string]bool{"public": true, "password": true, "private": true}, visible(&repo.GetPostsParams{ViewerID: user.ID}))
+	require.Equal(t, map[string]bool{"public": true, "password": true, "private": true}, visible(&repo.GetPostsParams{IsSuperadmin: true}))
+	// exports keep the unlisted posts too
+	require.Equal(t, map[string]bool{"public": true, "unlisted": true, "password": true, "private": true}, visible(&repo.GetPostsParams{AllVisibilities: true}))
+
+	for _, id := range ids {
+		deletePost(t, id)
+	}
+	deleteUser(t, user.ID)
+	deleteCategory(t, category.ID)
+}
+
+func TestRestorePost(t *testing.T) {
+	post := createPost(t)
+	deletePost(t, post.ID)
+
+	_, err := dbManager.Post().Get(post.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	trash, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit:   10,
+		Page:    1,
+		Deleted: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, post.ID, trash.Posts[0].ID)
+	require.NotNil(t, trash.Posts[0].DeletedAt)
+
+	require.ErrorIs(t, dbManager.Post().Restore(post.ID, post.UserID+1), sql.ErrNoRows)
+	require.NoError(t, dbManager.Post().Restore(post.ID, post.UserID))
+
+	p, err := dbManager.Post().Get(post.ID)
+	require.NoError(t, err)
+	require.Nil(t, p.DeletedAt)
+
+	deletePost(t, post.ID)
+}
+
+func TestGetAllPostsDeletedCategory(t *testing.T) {
+	user := createUser(t)
+	category := createCategory(t)
+
+	post, err := dbManager.Post().Create(&repo.Post{
+		Title:       faker.Sentence(),
+		Description: faker.Sentence(),
+		UserID:      user.ID,
+		CategoryID:  category.ID,
+	})
+	require.NoError(t, err)
+
+	count := func(params *repo.GetPostsParams) int {
+		params.Limit, params.UserID = 10, user.ID
+		posts, err := dbManager.Post().GetAll(params)
+		require.NoError(t, err)
+		return len(posts.Posts)
+	}
+
+	deleteCategory(t, category.ID)
+	require.Zero(t, count(&repo.GetPostsParams{}))
+	require.Equal(t, 1, count(&repo.GetPostsParams{AllVisibilities: true}))
+
+	_, err = dbManager.Post().Get(post.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, dbManager.Category().Restore(category.ID))
+	require.Equal(t, 1, count(&repo.GetPostsParams{}))
+
+	deletePost(t, post.ID)
+	deleteUser(t, user.ID)
+	deleteCategory(t, category.ID)
+}
+
+func TestAddViews(t *testing.T) {
+	post := createPost(t)
+
+	err := dbManager.Post().AddViews(map[int64]int64{post.ID: 3})
+	require.NoError(t, err)
+
+	p, err := dbManager.Post().Get(post.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), p.ViewsCount)
+
+	deletePost(t, post.ID)
+}
+
+func TestGetAllPostsRanking(t *testing.T) {
+	post := createPost(t)
+	require.NoError(t, dbManager.Post().AddViews(map[int64]int64{post.ID: 1000000}))
+	require.NoError(t, dbManager.Stats().RefreshRankings())
+
+	for _, field := range []string{"trending", "top_week", "top_month", "top_all"} {
+		posts, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+			Limit: 10,
+			Page:  1,
+			Sort:  []*repo.SortField{{Field: field, Desc: true}},
+		})
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, len(posts.Posts), 1)
+	}
+
+	posts, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit: 1,
+		Page:  1,
+		Sort:  []*repo.SortField{{Field: "top_all", Desc: true}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, post.ID, posts.Posts[0].ID)
+
+	deletePost(t, post.ID)
+}
+
+func TestGetRelatedPosts(t *testing.T) {
+	user := createUser(t)
+	category := createCategory(t)
+
+	var ids []int64
+	for _, title := range []string{"Getting started with Go", "Getting started with Go modules"} {
+		post, err := dbManager.Post().Create(&repo.Post{
+			Title:       title,
+			Description: faker.Sentence(),
+			Tags:        []string{"go", "tutorial"},
+			UserID:      user.ID,
+			CategoryID:  category.ID,
+		})
+		require.NoError(t, err)
+		ids = append(ids, post.ID)
+	}
+
+	p, err := dbManager.Post().Get(ids[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"go", "tutorial"}, p.Tags)
+
+	related, err := dbManager.Post().GetRelated(ids[0], 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, related)
+	require.Equal(t, ids[1], related[0].ID)
+	require.Greater(t, related[0].Score, 7.0)
+
+	for _, id := range ids {
+		deletePost(t, id)
+	}
+	deleteUser(t, user.ID)
+	deleteCategory(t, category.ID)
+}
+
+func TestGetPublicPostIDs(t *testing.T) {
+	post := createPost(t)
+	deleted := createPost(t)
+	deletePost(t, deleted.ID)
+
+	ids, err := dbManager.Post().GetPublicIDs([]int64{post.ID, deleted.ID})
+	require.NoError(t, err)
+	require.Equal(t, []int64{post.ID}, ids)
+
+	deletePost(t, post.ID)
+}
+
+func TestPostContributors(t *testing.T) {
+	post := createPost(t)
+	require.Len(t, post.Contributors, 1)
+	require.Equal(t, repo.ContributorRoleAuthor, post.Contributors[0].Role)
+
+	user := createUser(t)
+	require.NoError(t, dbManager.Post().SetContributor(post.ID, user.ID, repo.ContributorRoleEditor))
+	require.NoError(t, dbManager.Post().SetContributor(post.ID, user.ID, repo.ContributorRoleCoAuthor))
+
+	p, err := dbManager.Post().Get(post.ID)
+	require.NoError(t, err)
+	require.Len(t, p.Contributors, 2)
+	require.Equal(t, post.UserID, p.Contributors[0].UserID)
+	require.Equal(t, repo.ContributorRoleCoAuthor, p.Contributors[1].Role)
+
+	require.ErrorIs(t, dbManager.Post().RemoveContributor(post.ID, post.UserID), sql.ErrNoRows)
+	require.NoError(t, dbManager.Post().RemoveContributor(post.ID, user.ID))
+
+	deletePost(t, post.ID)
+	deleteUser(t, user.ID)
+}
+
+func TestPostSEO(t *testing.T) {
+	post := createPost(t)
+
+	seo, err := dbManager.Post().GetSEO(post.ID)
+	require.NoError(t, err)
+	require.Nil(t, seo.MetaTitle)
+	require.Nil(t, seo.UpdatedAt)
+
+	title := "Custom title"
+	seo, err = dbManager.Post().SetSEO(&repo.PostSEO{PostID: post.ID, MetaTitle: &title})
+	require.NoError(t, err)
+	require.NotNil(t, seo.UpdatedAt)
+
+	seo, err = dbManager.Post().GetSEO(post.ID)
+	require.NoError(t, err)
+	require.Equal(t, title, *seo.MetaTitle)
+	require.Nil(t, seo.MetaDescription)
+
+	_, err = dbManager.Post().SetSEO(&repo.PostSEO{PostID: post.ID})
+	require.NoError(t, err)
+
+	seo, err = dbManager.Post().GetSEO(post.ID)
+	require.NoError(t, err)
+	require.Nil(t, seo.MetaTitle)
+
+	deletePost(t, post.ID)
+}
+
+func TestPostTranslations(t *testing.T) {
+	post := createPost(t)
+	require.Equal(t, "uz", post.Language)
+	require.Empty(t, post.Translations)
+
+	slug := faker.Word() + "-" + faker.Word() + "-" + faker.Word()
+	tr, err := dbManager.Post().SetTranslation(&repo.PostTranslation{
+		PostID:      post.ID,
+		Language:    "en",
+		Title:       "Hello",
+		Slug:        slug,
+		Description: "Hello world",
+	})
+	require.NoError(t, err)
+	require.Nil(t, tr.UpdatedAt)
+
+	other := createPost(t)
+	_, err = dbManager.Post().SetTranslation(&repo.PostTranslation{
+		PostID:   other.ID,
+		Language: "en",
+		Title:    "Hello",
+		Slug:     slug,
+	})
+	require.ErrorIs(t, err, repo.ErrSlugExists)
+
+	got, err := dbManager.Post().Get(post.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"en"}, got.Translations)
+
+	translations, err := dbManager.Post().GetTranslations([]int64{post.ID, other.ID}, []string{"ru", "en"})
+	require.NoError(t, err)
+	require.Len(t, translations, 1)
+	require.Equal(t, "Hello world", translations[0].Description)
+
+	translations, err = dbManager.Post().GetTranslations([]int64{post.ID}, []string{"ru"})
+	require.NoError(t, err)
+	require.Empty(t, translations)
+
+	bySlug, err := dbManager.Post().GetTranslationBySlug("en", slug)
+	require.NoError(t, err)
+	require.Equal(t, post.ID, bySlug.PostID)
+
+	_, err = dbManager.Post().GetTranslationBySlug("ru", slug)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, dbManager.Post().DeleteTranslation(post.ID, "en"))
+	require.ErrorIs(t, dbManager.Post().DeleteTranslation(post.ID, "en"), sql.ErrNoRows)
+
+	deletePost(t, post.ID)
+	deletePost(t, other.ID)
+}
@@ -0,0 +1,249 @@
+package postgres
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// listQuery collects the filters, ordering and pagination of a GetAll query.
+// User input only ever reaches the database as arguments, never as SQL text.
+type listQuery struct {
+	conditions []string
+	keys       []sortKey
+	args       []interface{}
+	filterArgs int
+
+	keyset   string
+	after    *repo.Cursor
+	before   *repo.Cursor
+	limit    int64
+	page     int64
+	hasLimit bool
+}
+
+type sortKey struct {
+	expr string
+	desc bool
+}
+
+func newListQuery() *listQuery {
+	return &listQuery{}
+}
+
+// Arg registers an argument and returns its placeholder.
+func (q *listQuery) Arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// Where adds a condition. Placeholders used in it must come from Arg.
+func (q *listQuery) Where(condition string) {
+	q.conditions = append(q.conditions, condition)
+	q.filterArgs = len(q.args)
+}
+
+// Sort adds an ORDER BY key for every requested field. fields maps the
+// public field names to SQL expressions; anything not in it is rejected.
+// defaults are used when nothing was requested, and tieBreaker is always
+// appended so that the order is stable between pages.
+func (q *listQuery) Sort(sort []*repo.SortField, fields map[string]string, defaults []*repo.SortField, tieBreaker string) error {
+	if len(sort) == 0 {
+		sort = defaults
+	}
+
+	for _, s := range sort {
+		expr, ok := fields[s.Field]
+		if !ok {
+			return repo.ErrInvalidSortField
+		}
+		q.OrderBy(expr, s.Desc)
+	}
+
+	q.OrderBy(tieBreaker, true)
+
+	return nil
+}
+
+// OrderBy adds an ORDER BY key without checking it against a whitelist,
+// so expr must never contain user input.
+func (q *listQuery) OrderBy(expr string, desc bool) {
+	q.keys = append(q.keys, sortKey{expr: expr, desc: desc})
+}
+
+// Keyset switches the query to keyset pagination, starting right after or
+// right before the row a cursor points at. It must be called after every
+// Where and Sort call, since the cursor holds one value per sort key.
+func (q *listQuery) Keyset(after, before *repo.Cursor) error {
+	cursor, backward := after, false
+	if before != nil {
+		cursor, backward = before, true
+	}
+	if cursor == nil {
+		return nil
+	}
+	if after != nil && before != nil || len(cursor.Values) != len(q.keys) {
+		return repo.ErrInvalidCursor
+	}
+
+	q.after, q.before = after, before
+
+	// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... with the comparison
+	// flipped for descending keys and for paging backwards
+	var (
+		alternatives []string
+		equal        []string
+	)
+	for i, key := range q.keys {
+		value := q.Arg(cursor.Values[i])
+
+		op := " > "
+		if key.desc != backward {
+			op = " < "
+		}
+
+		alternatives = append(alternatives, "("+strings.Join(append(equal, key.expr+op+value), " AND ")+")")
+		equal = append(equal, key.expr+" = "+value)
+	}
+
+	q.keyset = "(" + strings.Join(alternatives, " OR ") + ")"
+
+	return nil
+}
+
+// Filter returns the WHERE clause without the keyset condition, which is
+// what the count query of a listing needs.
+func (q *listQuery) Filter() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// ListFilter returns the WHERE clause of the listing itself.
+func (q *listQuery) ListFilter() string {
+	if q.keyset == "" {
+		return q.Filter()
+	}
+	return " WHERE " + strings.Join(append(q.conditions, q.keyset), " AND ")
+}
+
+// Order returns the ORDER BY clause. When paging backwards every key is
+// reversed, and the rows have to be put back in order with reverseRows.
+func (q *listQuery) Order() string {
+	if len(q.keys) == 0 {
+		return ""
+	}
+
+	var keys []string
+	for _, key := range q.keys {
+		if key.desc != q.Backward() {
+			keys = append(keys, key.expr+" DESC")
+		} else {
+			keys = append(keys, key.expr)
+		}
+	}
+
+	return " ORDER BY " + strings.Join(keys, ", ")
+}
+
+// Paginate returns the LIMIT and OFFSET clause for the given page. In keyset
+// mode the page is ignored and one extra row is fetched to see whether
+// there is anything left after this page.
+func (q *listQuery) Paginate(limit, page int64) string {
+	if page < 1 {
+		page = 1
+	}
+	q.limit, q.page, q.hasLimit = limit, page, true
+
+	if q.keyset != "" {
+		return " LIMIT " + q.Arg(limit+1)
+	}
+	return " LIMIT " + q.Arg(limit) + " OFFSET " + q.Arg((page-1)*limit)
+}
+
+// CursorColumns returns the select list items holding the sort key values
+// of each row. Scan them with CursorDest.
+func (q *listQuery) CursorColumns() string {
+	var columns string
+	for _, key := range q.keys {
+		columns += ",\n\t\t\t(" + key.expr + ")::text"
+	}
+	return columns
+}
+
+// CursorDest returns a cursor for a row and the scan destinations filling it.
+func (q *listQuery) CursorDest() (*repo.Cursor, []interface{}) {
+	cursor := &repo.Cursor{
+		Values: make([]string, len(q.keys)),
+	}
+
+	dest := make([]interface{}, len(q.keys))
+	for i := range cursor.Values {
+		dest[i] = &cursor.Values[i]
+	}
+
+	return cursor, dest
+}
+
+// Backward reports whether the rows were fetched in reverse order.
+func (q *listQuery) Backward() bool {
+	return q.before != nil
+}
+
+// Cursors takes the cursors of the fetched rows, in the order they were
+// fetched, and returns how many rows belong to the page along with the
+// cursors of the pages around it.
+func (q *listQuery) Cursors(rows []*repo.Cursor, count int64) (keep int, next, prev *repo.Cursor) {
+	keep = len(rows)
+	if q.hasLimit && int64(keep) > q.limit {
+		keep = int(q.limit)
+	}
+	if keep == 0 {
+		return 0, nil, nil
+	}
+
+	first, last := rows[0], rows[keep-1]
+	more := keep < len(rows)
+
+	switch {
+	case q.before != nil:
+		// rows come last to first
+		if more {
+			prev = last
+		}
+		next = first
+	case q.after != nil:
+		if more {
+			next = last
+		}
+		prev = first
+	default:
+		if (q.page-1)*q.limit+int64(keep) < count {
+			next = last
+		}
+		if q.page > 1 {
+			prev = first
+		}
+	}
+
+	return keep, next, prev
+}
+
+// Args returns the arguments of the whole query.
+func (q *listQuery) Args() []interface{} {
+	return q.args
+}
+
+// FilterArgs returns the arguments referenced by Filter.
+func (q *listQuery) FilterArgs() []interface{} {
+	return q.args[:q.filterArgs]
+}
+
+// reverseRows reverses rows fetched by a backward keyset query.
+func reverseRows[T any](rows []T) {
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+}
@@ -0,0 +1,339 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+type reportRepo struct {
+	db *sqlx.DB
+}
+
+func NewReport(db *sqlx.DB) repo.ReportStorageI {
+	return &reportRepo{
+		db: db,
+	}
+}
+
+// reportTarget hides and shows again the content of a kind. state selects
+// what hide changes so that show can give it back, unless it was changed
+// since. Profiles have nothing to change, they are hidden by the report
+// alone.
+type reportTarget struct {
+	state string
+	hide  string
+	show  string
+}
+
+var reportTargets = map[string]*reportTarget{
+	repo.ReportTargetPost: {
+		state: "SELECT visibility FROM posts WHERE id = $1",
+		hide:  "UPDATE posts SET visibility = 'private' WHERE id = $1",
+		show:  "UPDATE posts SET visibility = $2 WHERE id = $1 AND visibility = 'private'",
+	},
+	repo.ReportTargetComment: {
+		state: "SELECT status FROM comments WHERE id = $1",
+		hide:  "UPDATE comments SET status = 'pending' WHERE id = $1",
+		show:  "UPDATE comments SET status = $2 WHERE id = $1 AND status = 'pending'",
+	},
+	repo.ReportTargetUser: {},
+}
+
+const reportColumns = `
+	r.id,
+	r.target_type,
+	r.target_id,
+	r.status,
+	r.flags_count,
+	r.hidden,
+	r.resolution,
+	r.resolved_by,
+	r.resolved_at,
+	r.created_at,
+	r.updated_at
+`
+
+func scanReport(row interface{ Scan(...interface{}) error }) (*repo.Report, error) {
+	var r repo.Report
+	err := row.Scan(
+		&r.ID,
+		&r.TargetType,
+		&r.TargetID,
+		&r.Status,
+		&r.FlagsCount,
+		&r.Hidden,
+		&r.Resolution,
+		&r.ResolvedBy,
+		&r.ResolvedAt,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (rr *reportRepo) Flag(targetType string, targetID int64, flag *repo.ReportFlag, hideAfter int64) (*repo.Report, error) {
+	target, ok := reportTargets[targetType]
+	if !ok {
+		return nil, repo.ErrUnknownReportTarget
+	}
+
+	tx, err := rr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var reportID int64
+	err = tx.QueryRow(`
+		INSERT INTO reports(target_type, target_id) VALUES ($1, $2)
+		ON CONFLICT (target_type, target_id) WHERE status = 'open' DO UPDATE SET
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id
+	`, targetType, targetID).Scan(&reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO report_flags(report_id, user_id, reason, details) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (report_id, user_id) DO UPDATE SET
+			reason = EXCLUDED.reason,
+			details = EXCLUDED.details,
+			created_at = CURRENT_TIMESTAMP
+	`, reportID, flag.UserID, flag.Reason, flag.Details)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := scanReport(tx.QueryRow(`
+		UPDATE reports r SET
+			flags_count = (SELECT count(1) FROM report_flags f WHERE f.report_id = r.id)
+		WHERE r.id = $1
+		RETURNING `+reportColumns, reportID))
+	if err != nil {
+		return nil, err
+	}
+
+	if !report.Hidden && report.FlagsCount >= hideAfter {
+		err = hideReportTarget(tx, target, report)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, tx.Commit()
+}
+
+// hideReportTarget hides the content of an open report, keeping the state
+// to give back when it is shown again.
+func hideReportTarget(tx *sql.Tx, target *reportTarget, report *repo.Report) error {
+	var state *string
+	if target.state != "" {
+		err := tx.QueryRow(target.state, report.TargetID).Scan(&state)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		_, err = tx.Exec(target.hide, report.TargetID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec("UPDATE reports SET hidden = TRUE, previous_state = $2 WHERE id = $1", report.ID, state)
+	if err != nil {
+		return err
+	}
+
+	report.Hidden = true
+	return nil
+}
+
+// showReportTarget gives the content hidden by a report its state back.
+func showReportTarget(tx *sql.Tx, target *reportTarget, report *repo.Report) error {
+	var state *string
+	err := tx.QueryRow("SELECT previous_state FROM reports WHERE id = $1", report.ID).Scan(&state)
+	if err != nil {
+		return err
+	}
+
+	if target.show != "" && state != nil {
+		_, err = tx.Exec(target.show, report.TargetID, *state)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE reports SET hidden = FALSE, previous_state = NULL WHERE id = $1", report.ID)
+	if err != nil {
+		return err
+	}
+
+	report.Hidden = false
+	return nil
+}
+
+func (rr *reportRepo) Get(report_id int64) (*repo.Report, error) {
+	report, err := scanReport(rr.db.QueryRow("SELECT "+reportColumns+" FROM reports r WHERE r.id = $1", report_id))
+	if err != nil {
+		return nil, err
+	}
+
+	report.Flags, err = rr.getFlags(report.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (rr *reportRepo) getFlags(report_id int64) ([]*repo.ReportFlag, error) {
+	rows, err := rr.db.Query(`
+		SELECT user_id, reason, details, created_at FROM report_flags
+		WHERE report_id = $1 ORDER BY created_at
+	`, report_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := make([]*repo.ReportFlag, 0)
+	for rows.Next() {
+		var f repo.ReportFlag
+		err := rows.Scan(
+			&f.UserID,
+			&f.Reason,
+			&f.Details,
+			&f.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, &f)
+	}
+
+	return flags, rows.Err()
+}
+
+func (rr *reportRepo) GetAll(params *repo.GetReportsParams) (*repo.GetAllReportsResult, error) {
+	result := repo.GetAllReportsResult{
+		Reports: make([]*repo.Report, 0),
+	}
+
+	q := newListQuery()
+
+	if params.Status != "" {
+		q.Where("r.status = " + q.Arg(params.Status))
+	}
+
+	if params.TargetType != "" {
+		q.Where("r.target_type = " + q.Arg(params.TargetType))
+	}
+
+	if params.TargetID != 0 {
+		q.Where("r.target_id = " + q.Arg(params.TargetID))
+	}
+
+	// the most flagged content comes first
+	q.OrderBy("r.flags_count", true)
+	q.OrderBy("r.id", false)
+
+	query := "SELECT " + reportColumns + " FROM reports r" + q.ListFilter() + q.Order() + q.Paginate(params.Limit, params.Page)
+
+	rows, err := rr.db.Query(query, q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Reports = append(result.Reports, report)
+	}
+
+	err = rr.db.QueryRow("SELECT count(1) FROM reports r"+q.Filter(), q.FilterArgs()...).Scan(&result.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (rr *reportRepo) Close(report_id int64, status string, moderatorID int64, resolution *string) (*repo.Report, error) {
+	tx, err := rr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	report, err := scanReport(tx.QueryRow(
+		"SELECT "+reportColumns+" FROM reports r WHERE r.id = $1 AND r.status = 'open' FOR UPDATE",
+		report_id,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	target := reportTargets[report.TargetType]
+	switch {
+	case status == repo.ReportStatusResolved && !report.Hidden:
+		err = hideReportTarget(tx, target, report)
+	case status == repo.ReportStatusDismissed && report.Hidden:
+		err = showReportTarget(tx, target, report)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		UPDATE reports SET
+			status = $2,
+			resolution = $3,
+			resolved_by = $4,
+			resolved_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING status, resolution, resolved_by, resolved_at, updated_at
+	`, report.ID, status, resolution, moderatorID).Scan(
+		&report.Status,
+		&report.Resolution,
+		&report.ResolvedBy,
+		&report.ResolvedAt,
+		&report.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	report.Flags, err = rr.getFlags(report.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (rr *reportRepo) IsHidden(targetType string, targetID int64) (bool, error) {
+	var hidden bool
+	err := rr.db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM reports WHERE target_type = $1 AND target_id = $2 AND hidden)",
+		targetType,
+		targetID,
+	).Scan(&hidden)
+
+	return hidden, err
+}
@@ -0,0 +1,120 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReports(t *testing.T) {
+	post := createPost(t)
+	first := createUser(t)
+	second := createUser(t)
+
+	report, err := dbManager.Report().Flag(repo.ReportTargetPost, post.ID, &repo.ReportFlag{
+		UserID: first.ID,
+		Reason: "spam",
+	}, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), report.FlagsCount)
+	require.False(t, report.Hidden)
+
+	// flagging again replaces the flag instead of counting twice
+	report, err = dbManager.Report().Flag(repo.ReportTargetPost, post.ID, &repo.ReportFlag{
+		UserID: first.ID,
+		Reason: "hate",
+	}, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), report.FlagsCount)
+
+	merged, err := dbManager.Report().Flag(repo.ReportTargetPost, post.ID, &repo.ReportFlag{
+		UserID: second.ID,
+		Reason: "spam",
+	}, 2)
+	require.NoError(t, err)
+	require.Equal(t, report.ID, merged.ID)
+	require.Equal(t, int64(2), merged.FlagsCount)
+	require.True(t, merged.Hidden)
+
+	hidden, err := dbManager.Post().Get(post.ID)
+	require.NoError(t, err)
+	require.Equal(t, repo.PostVisibilityPrivate, hidden.Visibility)
+
+	// the author can't publish the post again while the report hides it
+	hidden.Visibility = repo.PostVisibilityPublic
+	updated, err := dbManager.Post().Update(hidden)
+	require.NoError(t, err)
+	require.Equal(t, repo.PostVisibilityPrivate, updated.Visibility)
+
+	result, err := dbManager.Report().GetAll(&repo.GetReportsParams{
+		Limit:      10,
+		Page:       1,
+		Status:     repo.ReportStatusOpen,
+		TargetType: repo.ReportTargetPost,
+		TargetID:   post.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Reports, 1)
+
+	closed, err := dbManager.Report().Close(report.ID, repo.ReportStatusDismissed, first.ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, repo.ReportStatusDismissed, closed.Status)
+	require.False(t, closed.Hidden)
+	require.Len(t, closed.Flags, 2)
+
+	shown, err := dbManager.Post().Get(post.ID)
+	require.NoError(t, err)
+	require.Equal(t, post.Visibility, shown.Visibility)
+
+	_, err = dbManager.Report().Close(report.ID, repo.ReportStatusResolved, first.ID, nil)
+	require.Error(t, err)
+
+	_, err = dbManager.Report().Flag("tag", 1, &repo.ReportFlag{UserID: first.ID, Reason: "spam"}, 2)
+	require.ErrorIs(t, err, repo.ErrUnknownReportTarget)
+
+	deletePost(t, post.ID)
+	deleteUser(t, first.ID)
+	deleteUser(t, second.ID)
+}
+
+func TestReportHiddenCommentModeration(t *testing.T) {
+	c := createComment(t)
+	first := createUser(t)
+	second := createUser(t)
+
+	var report *repo.Report
+	for _, user := range []*repo.User{first, second} {
+		var err error
+		report, err = dbManager.Report().Flag(repo.ReportTargetComment, c.ID, &repo.ReportFlag{
+			UserID: user.ID,
+			Reason: "spam",
+		}, 2)
+		require.NoError(t, err)
+	}
+	require.True(t, report.Hidden)
+
+	// approving the comment doesn't publish it while the report hides it
+	changed, err := dbManager.Comment().Moderate(&repo.ModerateComments{
+		IDs:         []int64{c.ID},
+		Status:      repo.CommentStatusApproved,
+		ModeratorID: first.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, changed)
+
+	hidden, err := dbManager.Comment().Get(c.ID)
+	require.NoError(t, err)
+	require.Equal(t, repo.CommentStatusPending, hidden.Status)
+
+	_, err = dbManager.Report().Close(report.ID, repo.ReportStatusDismissed, first.ID, nil)
+	require.NoError(t, err)
+
+	shown, err := dbManager.Comment().Get(c.ID)
+	require.NoError(t, err)
+	require.Equal(t, repo.CommentStatusApproved, shown.Status)
+
+	deleteComment(t, c.ID)
+	deleteUser(t, first.ID)
+	deleteUser(t, second.ID)
+}
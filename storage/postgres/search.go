@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/utils"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+const (
+	headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10"
+
+	userSearchDocument = `to_tsvector('simple', first_name || ' ' || last_name || ' ' || coalesce(username, ''))`
+)
+
+// escapeHTML escapes the text of a column in SQL, so the headlines made of
+// user content only carry the <mark> tags added by ts_headline.
+func escapeHTML(column string) string {
+	return `replace(replace(replace(replace(replace(` + column +
+		`, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+}
+
+type searchRepo struct {
+	db *sqlx.DB
+}
+
+func NewSearch(db *sqlx.DB) repo.SearchStorageI {
+	return &searchRepo{
+		db: db,
+	}
+}
+
+func (sr *searchRepo) Search(params *repo.SearchParams) (*repo.SearchResult, error) {
+	result := repo.SearchResult{
+		Posts:      make([]*repo.SearchPost, 0),
+		Users:      make([]*repo.SearchUser, 0),
+		Categories: make([]*repo.SearchCategory, 0),
+	}
+
+	tsQuery := utils.ToTsQuery(params.Query)
+	if tsQuery == "" {
+		return &result, nil
+	}
+
+	var err error
+	for _, t := range params.Types {
+		switch t {
+		case repo.SearchTypePosts:
+			err = sr.searchPosts(tsQuery, params.Limit, &result)
+		case repo.SearchTypeUsers:
+			err = sr.searchUsers(tsQuery, params.Limit, &result)
+		case repo.SearchTypeCategories:
+			err = sr.searchCategories(tsQuery, params.Limit, &result)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &result, nil
+}
+
+func (sr *searchRepo) searchPosts(tsQuery string, limit int64, result *repo.SearchResult) error {
+	query := `
+		SELECT
+			p.id,
+			p.title,
+			ts_headline('simple', ` + escapeHTML("p.title") + `, q.query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+			ts_headline('simple', ` + escapeHTML("p.description") + `, q.query, '` + headlineOptions + `'),
+			p.image_url,
+			p.user_id,
+			p.category_id,
+			p.created_at,
+			ts_rank_cd(p.search_vector, q.query) AS rank
+		FROM posts p, to_tsquery('simple', $1) q(query)
+		WHERE p.search_vector @@ q.query AND p.visibility = 'public' AND p.deleted_at IS NULL AND ` + liveCategory + `
+		ORDER BY rank DESC, p.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := sr.db.Query(query, tsQuery, limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var post repo.SearchPost
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.TitleHighlight,
+			&post.DescriptionHighlight,
+			&post.ImageUrl,
+			&post.UserID,
+			&post.CategoryID,
+			&post.CreatedAt,
+			&post.Rank,
+		)
+		if err != nil {
+			return err
+		}
+
+		result.Posts = append(result.Posts, &post)
+	}
+
+	queryCount := `
+		SELECT count(1) FROM posts p
+		WHERE p.search_vector @@ to_tsquery('simple', $1) AND p.visibility = 'public' AND p.deleted_at IS NULL AND ` + liveCategory + `
+	`
+
+	return sr.db.QueryRow(queryCount, tsQuery).Scan(&result.PostsCount)
+}
+
+func (sr *searchRepo) searchUsers(tsQuery string, limit int64, result *repo.SearchResult) error {
+	query := `
+		SELECT
+			u.id,
+			u.first_name,
+			u.last_name,
+			u.username,
+			u.profile_image_url,
+			ts_headline('simple', ` + escapeHTML("u.first_name || ' ' || u.last_name || ' ' || coalesce(u.username, '')") + `, q.query,
+				'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+			ts_rank_cd(` + userSearchDocument + `, q.query) AS rank
+		FROM users u, to_tsquery('simple', $1) q(query)
+		WHERE ` + userSearchDocument + ` @@ q.query
+		ORDER BY rank DESC, u.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := sr.db.Query(query, tsQuery, limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user repo.SearchUser
+		err := rows.Scan(
+			&user.ID,
+			&user.FirstName,
+			&user.LastName,
+			&user.UserName,
+			&user.ProfileImageUrl,
+			&user.Highlight,
+			&user.Rank,
+		)
+		if err != nil {
+			return err
+		}
+
+		result.Users = append(result.Users, &user)
+	}
+
+	queryCount := "SELECT count(1) FROM users WHERE " + userSearchDocument + " @@ to_tsquery('simple', $1)"
+
+	return sr.db.QueryRow(queryCount, tsQuery).Scan(&result.UsersCount)
+}
+
+func (sr *searchRepo) searchCategories(tsQuery string, limit int64, result *repo.SearchResult) error {
+	query := `
+		SELECT
+			c.id,
+			c.title,
+			ts_headline('simple', ` + escapeHTML("c.title") + `, q.query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+			ts_rank_cd(to_tsvector('simple', c.title), q.query) AS rank
+		FROM categories c, to_tsquery('simple', $1) q(query)
+		WHERE to_tsvector('simple', c.title) @@ q.query AND c.deleted_at IS NULL
+		ORDER BY rank DESC, c.title
+		LIMIT $2
+	`
+
+	rows, err := sr.db.Query(query, tsQuery, limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category repo.SearchCategory
+		err := rows.Scan(
+			&category.ID,
+			&category.Title,
+			&category.Highlight,
+			&category.Rank,
+		)
+		if err != nil {
+			return err
+		}
+
+		result.Categories = append(result.Categories, &category)
+	}
+
+	queryCount := "SELECT count(1) FROM categories WHERE to_tsvector('simple', title) @@ to_tsquery('simple', $1) AND deleted_at IS NULL"
+
+	return sr.db.QueryRow(queryCount, tsQuery).Scan(&result.CategoriesCount)
+}
@@ -0,0 +1,97 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	post := createPost(t)
+	require.NotEmpty(t, post)
+
+	result, err := dbManager.Search().Search(&repo.SearchParams{
+		Query: `"stopped working" meta*`,
+		Types: []string{repo.SearchTypePosts, repo.SearchTypeUsers, repo.SearchTypeCategories},
+		Limit: 10,
+	})
+	deletePost(t, post.ID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(result.Posts), 1)
+	require.Contains(t, result.Posts[0].DescriptionHighlight, "<mark>")
+}
+
+func TestSearchHighlightEscapesHTML(t *testing.T) {
+	user := createUser(t)
+	category := createCategory(t)
+
+	post, err := dbManager.Post().Create(&repo.Post{
+		Title:       "Escaping <b>headlines</b>",
+		Description: `<script>alert("xss")</script> headlines are escaped`,
+		Language:    "uz",
+		UserID:      user.ID,
+		CategoryID:  category.ID,
+	})
+	require.NoError(t, err)
+
+	result, err := dbManager.Search().Search(&repo.SearchParams{
+		Query: "headlines",
+		Types: []string{repo.SearchTypePosts},
+		Limit: 10,
+	})
+	require.NoError(t, err)
+
+	var found *repo.SearchPost
+	for _, p := range result.Posts {
+		if p.ID == post.ID {
+			found = p
+		}
+	}
+	require.NotNil(t, found)
+	require.NotContains(t, found.DescriptionHighlight, "<script>")
+	require.Contains(t, found.DescriptionHighlight, "&lt;script&gt;")
+	require.Contains(t, found.DescriptionHighlight, "<mark>headlines</mark>")
+	require.NotContains(t, found.TitleHighlight, "<b>")
+
+	posts, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit:  10,
+		UserID: user.ID,
+		Search: "headlines",
+	})
+	require.NoError(t, err)
+	require.Len(t, posts.Posts, 1)
+	require.NotContains(t, *posts.Posts[0].Headline, "<script>")
+
+	deletePost(t, post.ID)
+	deleteUser(t, user.ID)
+	deleteCategory(t, category.ID)
+}
+
+func TestSearchUsersByEmail(t *testing.T) {
+	user := createUser(t)
+
+	result, err := dbManager.Search().Search(&repo.SearchParams{
+		Query: user.Email,
+		Types: []string{repo.SearchTypeUsers},
+		Limit: 10,
+	})
+	deleteUser(t, user.ID)
+	require.NoError(t, err)
+	require.Empty(t, result.Users)
+}
+
+func TestGetAllPostsSearch(t *testing.T) {
+	post := createPost(t)
+	require.NotEmpty(t, post)
+
+	posts, err := dbManager.Post().GetAll(&repo.GetPostsParams{
+		Limit:  10,
+		Page:   1,
+		Search: "faceb*",
+	})
+	deletePost(t, post.ID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(posts.Posts), 1)
+	require.NotNil(t, posts.Posts[0].Headline)
+}
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+type seriesRepo struct {
+	db *sqlx.DB
+}
+
+func NewSeries(db *sqlx.DB) repo.SeriesStorageI {
+	return &seriesRepo{
+		db: db,
+	}
+}
+
+func (sr *seriesRepo) Create(s *repo.Series) (*repo.Series, error) {
+	query := `
+		INSERT INTO series(title, description, user_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	err := sr.db.QueryRow(
+		query,
+		s.Title,
+		s.Description,
+		s.UserID,
+	).Scan(
+		&s.ID,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Posts = make([]*repo.SeriesPost, 0)
+
+	return s, nil
+}
+
+func (sr *seriesRepo) Get(series_id int64) (*repo.Series, error) {
+	return sr.get("s.id = $1", series_id)
+}
+
+func (sr *seriesRepo) GetByPost(post_id int64) (*repo.Series, error) {
+	return sr.get("s.id = (SELECT sp.series_id FROM series_posts sp WHERE sp.post_id = $1)", post_id)
+}
+
+func (sr *seriesRepo) get(condition string, arg int64) (*repo.Series, error) {
+	var res repo.Series
+
+	query := `
+		SELECT
+			s.id,
+			s.title,
+			s.description,
+			s.user_id,
+			s.created_at,
+			s.updated_at
+		FROM series s
+		WHERE ` + condition
+
+	err := sr.db.QueryRow(query, arg).Scan(
+		&res.ID,
+		&res.Title,
+		&res.Description,
+		&res.UserID,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	queryPosts := `
+		SELECT
+			p.id,
+			p.title,
+			p.visibility,
+			sp.position
+		FROM series_posts sp
+		INNER JOIN posts p ON p.id = sp.post_id
+		WHERE sp.series_id = $1 AND p.deleted_at IS NULL
+		ORDER BY sp.position
+	`
+
+	rows, err := sr.db.Query(queryPosts, res.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res.Posts = make([]*repo.SeriesPost, 0)
+	for rows.Next() {
+		var post repo.SeriesPost
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Visibility,
+			&post.Position,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Posts = append(res.Posts, &post)
+	}
+
+	return &res, rows.Err()
+}
+
+func (sr *seriesRepo) Update(s *repo.Series) (*repo.Series, error) {
+	query := `
+		UPDATE series SET
+			title = $1,
+			description = $2,
+			updated_at = $3
+		WHERE id = $4
+		RETURNING user_id, created_at, updated_at
+	`
+
+	err := sr.db.QueryRow(
+		query,
+		s.Title,
+		s.Description,
+		time.Now(),
+		s.ID,
+	).Scan(
+		&s.UserID,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (sr *seriesRepo) Delete(series_id int64) error {
+	row, err := sr.db.Exec("DELETE FROM series WHERE id = $1", series_id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (sr *seriesRepo) SetPosts(series_id int64, postIDs []int64) error {
+	tx, err := sr.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM series_posts WHERE series_id = $1", series_id)
+	if err != nil {
+		return err
+	}
+
+	// only live posts of the author of the series are added
+	query := `
+		INSERT INTO series_posts(series_id, post_id, position)
+		SELECT s.id, v.id, v.position
+		FROM series s
+		CROSS JOIN unnest($2::bigint[]) WITH ORDINALITY AS v(id, position)
+		INNER JOIN posts p ON p.id = v.id AND p.user_id = s.user_id AND p.deleted_at IS NULL
+		WHERE s.id = $1
+	`
+
+	row, err := tx.Exec(query, series_id, pq.Array(postIDs))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return repo.ErrInvalidSeriesPosts
+		}
+		return err
+	}
+
+	rowsAffected, err := row.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected != int64(len(postIDs)) {
+		return repo.ErrInvalidSeriesPosts
+	}
+
+	return tx.Commit()
+}
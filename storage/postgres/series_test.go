@@ -0,0 +1,64 @@
+package postgres_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/bxcodec/faker/v4"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeries(t *testing.T) {
+	user := createUser(t)
+	category := createCategory(t)
+
+	series, err := dbManager.Series().Create(&repo.Series{
+		Title:  faker.Sentence(),
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		post, err := dbManager.Post().Create(&repo.Post{
+			Title:       faker.Sentence(),
+			Description: faker.Sentence(),
+			UserID:      user.ID,
+			CategoryID:  category.ID,
+		})
+		require.NoError(t, err)
+		ids = append(ids, post.ID)
+	}
+
+	// reversed order
+	require.NoError(t, dbManager.Series().SetPosts(series.ID, []int64{ids[2], ids[1], ids[0]}))
+
+	s, err := dbManager.Series().GetByPost(ids[1])
+	require.NoError(t, err)
+	require.Equal(t, series.ID, s.ID)
+	require.Len(t, s.Posts, 3)
+	require.Equal(t, ids[2], s.Posts[0].ID)
+	require.Equal(t, int32(3), s.Posts[2].Position)
+
+	other := createUser(t)
+	post, err := dbManager.Post().Create(&repo.Post{
+		Title:       faker.Sentence(),
+		Description: faker.Sentence(),
+		UserID:      other.ID,
+		CategoryID:  category.ID,
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, dbManager.Series().SetPosts(series.ID, []int64{post.ID}), repo.ErrInvalidSeriesPosts)
+
+	require.NoError(t, dbManager.Series().Delete(series.ID))
+	_, err = dbManager.Series().GetByPost(ids[0])
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	for _, id := range append(ids, post.ID) {
+		deletePost(t, id)
+	}
+	deleteUser(t, user.ID)
+	deleteUser(t, other.ID)
+	deleteCategory(t, category.ID)
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+type sitemapRepo struct {
+	db *sqlx.DB
+}
+
+func NewSitemap(db *sqlx.DB) repo.SitemapStorageI {
+	return &sitemapRepo{
+		db: db,
+	}
+}
+
+// sitemapSources select the id and lastmod of the entries of each kind.
+var sitemapSources = map[string]string{
+	repo.SitemapPosts: `
+		SELECT p.id, coalesce(p.updated_at, p.created_at) AS lastmod
+		FROM posts p
+		WHERE p.deleted_at IS NULL AND p.visibility = 'public' AND ` + liveCategory + `
+	`,
+	// a category or an author page changes with its posts
+	repo.SitemapCategories: `
+		SELECT c.id, greatest(c.created_at, max(coalesce(p.updated_at, p.created_at))) AS lastmod
+		FROM categories c
+		LEFT JOIN posts p ON p.category_id = c.id AND p.deleted_at IS NULL AND p.visibility = 'public'
+		WHERE c.deleted_at IS NULL
+		GROUP BY c.id
+	`,
+	repo.SitemapUsers: `
+		SELECT p.user_id AS id, max(coalesce(p.updated_at, p.created_at)) AS lastmod
+		FROM posts p
+		WHERE p.deleted_at IS NULL AND p.visibility = 'public' AND ` + liveCategory + `
+		GROUP BY p.user_id
+	`,
+}
+
+func (sr *sitemapRepo) GetChunks(kind string, size int64) ([]*repo.SitemapChunk, error) {
+	source, ok := sitemapSources[kind]
+	if !ok {
+		return nil, repo.ErrUnknownSitemap
+	}
+
+	query := `
+		SELECT (id - 1) / $1 + 1 AS chunk, max(lastmod)
+		FROM (` + source + `) s
+		GROUP BY chunk
+		ORDER BY chunk
+	`
+
+	rows, err := sr.db.Query(query, size)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chunks := make([]*repo.SitemapChunk, 0)
+	for rows.Next() {
+		var c repo.SitemapChunk
+		if err := rows.Scan(&c.Number, &c.LastMod); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, &c)
+	}
+
+	return chunks, rows.Err()
+}
+
+func (sr *sitemapRepo) GetEntries(kind string, chunk, size int64) ([]*repo.SitemapEntry, error) {
+	source, ok := sitemapSources[kind]
+	if !ok {
+		return nil, repo.ErrUnknownSitemap
+	}
+
+	query := `
+		SELECT id, lastmod
+		FROM (` + source + `) s
+		WHERE id > $1 AND id <= $2
+		ORDER BY id
+	`
+
+	rows, err := sr.db.Query(query, (chunk-1)*size, chunk*size)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*repo.SitemapEntry, 0)
+	for rows.Next() {
+		var e repo.SitemapEntry
+		if err := rows.Scan(&e.ID, &e.LastMod); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,37 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSitemap(t *testing.T) {
+	post := createPost(t)
+	chunk := (post.ID-1)/10 + 1
+
+	chunks, err := dbManager.Sitemap().GetChunks(repo.SitemapPosts, 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, chunks)
+	require.Equal(t, chunk, chunks[len(chunks)-1].Number)
+
+	entries, err := dbManager.Sitemap().GetEntries(repo.SitemapPosts, chunk, 10)
+	require.NoError(t, err)
+	require.Equal(t, post.ID, entries[len(entries)-1].ID)
+
+	entries, err = dbManager.Sitemap().GetEntries(repo.SitemapUsers, (post.UserID-1)/10+1, 10)
+	require.NoError(t, err)
+	require.Equal(t, post.UserID, entries[len(entries)-1].ID)
+
+	_, err = dbManager.Sitemap().GetChunks("tags", 10)
+	require.ErrorIs(t, err, repo.ErrUnknownSitemap)
+
+	deletePost(t, post.ID)
+
+	entries, err = dbManager.Sitemap().GetEntries(repo.SitemapPosts, chunk, 10)
+	require.NoError(t, err)
+	for _, e := range entries {
+		require.NotEqual(t, post.ID, e.ID)
+	}
+}
@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+type statsRepo struct {
+	db *sqlx.DB
+}
+
+func NewStats(db *sqlx.DB) repo.StatsStorageI {
+	return &statsRepo{
+		db: db,
+	}
+}
+
+func (sr *statsRepo) Rollup(day time.Time, views []*repo.PostDayViews) error {
+	var (
+		ids           = make([]int64, 0, len(views))
+		counts        = make([]int64, 0, len(views))
+		uniqueViewers = make([]int64, 0, len(views))
+	)
+	for _, v := range views {
+		ids = append(ids, v.PostID)
+		counts = append(counts, v.Views)
+		uniqueViewers = append(uniqueViewers, v.UniqueViewers)
+	}
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	// $2 and $3 bound the day, likes and comments are counted by when they
+	// were created, comments only once approved and likes older than their
+	// created_at column never
+	query := `
+		INSERT INTO post_daily_stats (post_id, day, views, unique_viewers, likes, dislikes, comments)
+		SELECT
+			p.id,
+			$1::date,
+			coalesce(v.views, 0),
+			coalesce(v.unique_viewers, 0),
+			(SELECT count(1) FROM likes l WHERE l.post_id = p.id AND l.status AND l.created_at >= $2 AND l.created_at < $3),
+			(SELECT count(1) FROM likes l WHERE l.post_id = p.id AND NOT l.status AND l.created_at >= $2 AND l.created_at < $3),
+			(SELECT count(1) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL AND c.status = 'approved' AND c.created_at >= $2 AND c.created_at < $3)
+		FROM posts p
+		LEFT JOIN unnest($4::bigint[], $5::bigint[], $6::bigint[]) AS v(post_id, views, unique_viewers) ON v.post_id = p.id
+		WHERE v.post_id IS NOT NULL
+			OR EXISTS (SELECT 1 FROM likes l WHERE l.post_id = p.id AND l.created_at >= $2 AND l.created_at < $3)
+			OR EXISTS (SELECT 1 FROM comments c WHERE c.post_id = p.id AND c.created_at >= $2 AND c.created_at < $3)
+		ON CONFLICT (post_id, day) DO UPDATE SET
+			views = GREATEST(post_daily_stats.views, EXCLUDED.views),
+			unique_viewers = GREATEST(post_daily_stats.unique_viewers, EXCLUDED.unique_viewers),
+			likes = EXCLUDED.likes,
+			dislikes = EXCLUDED.dislikes,
+			comments = EXCLUDED.comments
+	`
+
+	_, err := sr.db.Exec(
+		query,
+		start.Format("2006-01-02"),
+		start,
+		end,
+		pq.Array(ids),
+		pq.Array(counts),
+		pq.Array(uniqueViewers),
+	)
+
+	return err
+}
+
+func (sr *statsRepo) RefreshRankings() error {
+	_, err := sr.db.Exec("REFRESH MATERIALIZED VIEW CONCURRENTLY post_rankings")
+	return err
+}
+
+func (sr *statsRepo) GetPostStats(params *repo.GetStatsParams) ([]*repo.DailyStats, error) {
+	query := `
+		SELECT
+			d.day::date,
+			coalesce(s.views, 0),
+			coalesce(s.unique_viewers, 0),
+			coalesce(s.likes, 0),
+			coalesce(s.dislikes, 0),
+			coalesce(s.comments, 0)
+		FROM generate_series($2::date, $3::date, interval '1 day') AS d(day)
+		LEFT JOIN post_daily_stats s ON s.day = d.day::date AND s.post_id = $1
+		ORDER BY d.day
+	`
+
+	return sr.days(query, params.PostID, params.From.Format("2006-01-02"), params.To.Format("2006-01-02"))
+}
+
+func (sr *statsRepo) GetUserStats(params *repo.GetStatsParams) (*repo.UserStats, error) {
+	var (
+		result repo.UserStats
+		err    error
+		from   = params.From.Format("2006-01-02")
+		to     = params.To.Format("2006-01-02")
+	)
+
+	query := `
+		SELECT
+			d.day::date,
+			coalesce(sum(s.views), 0),
+			coalesce(sum(s.unique_viewers), 0),
+			coalesce(sum(s.likes), 0),
+			coalesce(sum(s.dislikes), 0),
+			coalesce(sum(s.comments), 0)
+		FROM generate_series($2::date, $3::date, interval '1 day') AS d(day)
+		LEFT JOIN (
+			post_daily_stats s
+			INNER JOIN posts p ON p.id = s.post_id AND p.user_id = $1 AND p.deleted_at IS NULL
+		) ON s.day = d.day::date
+		GROUP BY d.day
+		ORDER BY d.day
+	`
+
+	result.Days, err = sr.days(query, params.UserID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	queryTop := `
+		SELECT
+			p.id,
+			p.title,
+			sum(s.views) AS views,
+			sum(s.likes),
+			sum(s.comments)
+		FROM post_daily_stats s
+		INNER JOIN posts p ON p.id = s.post_id
+		WHERE p.user_id = $1 AND p.deleted_at IS NULL AND s.day BETWEEN $2::date AND $3::date
+		GROUP BY p.id, p.title
+		ORDER BY views DESC, p.id DESC
+		LIMIT $4
+	`
+
+	rows, err := sr.db.Query(queryTop, params.UserID, from, to, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result.TopPosts = make([]*repo.TopPost, 0)
+	for rows.Next() {
+		var post repo.TopPost
+		err := rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Views,
+			&post.Likes,
+			&post.Comments,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		result.TopPosts = append(result.TopPosts, &post)
+	}
+
+	return &result, rows.Err()
+}
+
+func (sr *statsRepo) days(query string, args ...interface{}) ([]*repo.DailyStats, error) {
+	rows, err := sr.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*repo.DailyStats, 0)
+	for rows.Next() {
+		var day repo.DailyStats
+		err := rows.Scan(
+			&day.Day,
+			&day.Views,
+			&day.UniqueViewers,
+			&day.Likes,
+			&day.Dislikes,
+			&day.Comments,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &day)
+	}
+
+	return result, rows.Err()
+}
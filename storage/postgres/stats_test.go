@@ -0,0 +1,44 @@
+package postgres_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsRollup(t *testing.T) {
+	post := createPost(t)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	views := []*repo.PostDayViews{{PostID: post.ID, Views: 5, UniqueViewers: 3}}
+	require.NoError(t, dbManager.Stats().Rollup(today, views))
+
+	// views never go down, even when the counters are gone
+	views[0].Views, views[0].UniqueViewers = 0, 0
+	require.NoError(t, dbManager.Stats().Rollup(today, views))
+
+	days, err := dbManager.Stats().GetPostStats(&repo.GetStatsParams{
+		PostID: post.ID,
+		From:   today.AddDate(0, 0, -2),
+		To:     today,
+	})
+	require.NoError(t, err)
+	require.Len(t, days, 3)
+	require.Equal(t, int64(0), days[0].Views)
+	require.Equal(t, int64(5), days[2].Views)
+	require.Equal(t, int64(3), days[2].UniqueViewers)
+
+	stats, err := dbManager.Stats().GetUserStats(&repo.GetStatsParams{
+		UserID: post.UserID,
+		From:   today,
+		To:     today,
+		Limit:  5,
+	})
+	require.NoError(t, err)
+	require.Len(t, stats.Days, 1)
+	require.Equal(t, post.ID, stats.TopPosts[0].ID)
+
+	deletePost(t, post.ID)
+}
@@ -186,22 +186,37 @@ func (ur *userRepo) Delete(user_id int64) error {
 	return nil
 }
 
+var userSortFields = map[string]string{
+	"created_at": "created_at",
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"username":   "coalesce(username, '')",
+	"email":      "email",
+}
+
 func (ur *userRepo) GetAll(params *repo.GetAllUserParams) (*repo.GetAllUsersResult, error) {
 	result := repo.GetAllUsersResult{
 		Users: make([]*repo.User, 0),
 	}
 
-	offset := (params.Page - 1) * params.Limit
+	q := newListQuery()
 
-	limit := fmt.Sprintf(" LIMIT %d OFFSET %d", params.Limit, offset)
+	if params.Search != "" {
+		str := q.Arg("%" + params.Search + "%")
+		q.Where(fmt.Sprintf(
+			"(first_name ILIKE %[1]s OR last_name ILIKE %[1]s OR phone_number ILIKE %[1]s OR email ILIKE %[1]s OR username ILIKE %[1]s)",
+			str,
+		))
+	}
 
-	filter := ""
+	err := q.Sort(params.Sort, userSortFields, []*repo.SortField{{Field: "created_at", Desc: true}}, "id")
+	if err != nil {
+		return nil, err
+	}
 
-	if params.Search != "" {
-		str := "%" + params.Search + "%"
-		filter = fmt.Sprintf(`
-			WHERE first_name ILIKE '%s' OR last_name ILIKE '%s' OR phone_number ILIKE '%s' OR email ILIKE '%s' OR username ILIKE '%s'
-		`, str, str, str, str, str)
+	err = q.Keyset(params.After, params.Before)
+	if err != nil {
+		return nil, err
 	}
 
 	query := `
@@ -216,21 +231,21 @@ func (ur *userRepo) GetAll(params *repo.GetAllUserParams) (*repo.GetAllUsersResu
 			username,
 			profile_image_url,
 			type,
-			created_at
+			created_at` + q.CursorColumns() + `
 		FROM users
-	` + filter + `
-		ORDER BY created_at DESC
-	` + limit
+	` + q.ListFilter() + q.Order() + q.Paginate(int64(params.Limit), int64(params.Page))
 
-	rows, err := ur.db.Query(query)
+	rows, err := ur.db.Query(query, q.Args()...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var cursors []*repo.Cursor
 	for rows.Next() {
 		var user repo.User
-		err := rows.Scan(
+		cursor, cursorDest := q.CursorDest()
+		err := rows.Scan(append([]interface{}{
 			&user.ID,
 			&user.FirstName,
 			&user.LastName,
@@ -242,22 +257,30 @@ func (ur *userRepo) GetAll(params *repo.GetAllUserParams) (*repo.GetAllUsersResu
 			&user.ProfileImageUrl,
 			&user.Type,
 			&user.CreatedAt,
-		)
+		}, cursorDest...)...)
 		if err != nil {
 			return nil, err
 		}
 
 		result.Users = append(result.Users, &user)
+		cursors = append(cursors, cursor)
 	}
 
-	queryCount := "SELECT count(1) FROM users " + filter
+	queryCount := "SELECT count(1) FROM users" + q.Filter()
 
-	err = ur.db.QueryRow(queryCount).Scan(&result.Count)
+	err = ur.db.QueryRow(queryCount, q.FilterArgs()...).Scan(&result.Count)
 
 	if err != nil {
 		return nil, err
 	}
 
+	keep, next, prev := q.Cursors(cursors, int64(result.Count))
+	result.Users = result.Users[:keep]
+	if q.Backward() {
+		reverseRows(result.Users)
+	}
+	result.NextCursor, result.PrevCursor = next, prev
+
 	return &result, nil
 }
 
@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// maxImportErrors is how many item errors Get returns.
+const maxImportErrors = 100
+
+type wordPressRepo struct {
+	db *sqlx.DB
+}
+
+func NewWordPress(db *sqlx.DB) repo.WordPressStorageI {
+	return &wordPressRepo{
+		db: db,
+	}
+}
+
+const wordPressImportColumns = `
+	id,
+	user_id,
+	file_name,
+	status,
+	total,
+	processed,
+	failed,
+	error,
+	created_at,
+	updated_at,
+	finished_at
+`
+
+func scanWordPressImport(row interface{ Scan(...interface{}) error }) (*repo.WordPressImport, error) {
+	var i repo.WordPressImport
+
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FileName,
+		&i.Status,
+		&i.Total,
+		&i.Processed,
+		&i.Failed,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	i.Errors = make([]*repo.WordPressImportError, 0)
+
+	return &i, nil
+}
+
+func (wr *wordPressRepo) Create(i *repo.WordPressImport) (*repo.WordPressImport, error) {
+	query := `
+		INSERT INTO wordpress_imports(user_id, file_name)
+		VALUES ($1, $2)
+		RETURNING ` + wordPressImportColumns
+
+	return scanWordPressImport(wr.db.QueryRow(query, i.UserID, i.FileName))
+}
+
+func (wr *wordPressRepo) Get(import_id int64) (*repo.WordPressImport, error) {
+	query := "SELECT " + wordPressImportColumns + " FROM wordpress_imports WHERE id = $1"
+
+	i, err := scanWordPressImport(wr.db.QueryRow(query, import_id))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := wr.db.Query(`
+		SELECT kind, wp_id, error, created_at
+		FROM wordpress_import_errors
+		WHERE import_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, import_id, maxImportErrors)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e repo.WordPressImportError
+		err := rows.Scan(
+			&e.Kind,
+			&e.WpID,
+			&e.Error,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		i.Errors = append(i.Errors, &e)
+	}
+
+	return i, rows.Err()
+}
+
+func (wr *wordPressRepo) Claim(staleBefore time.Time) (*repo.WordPressImport, error) {
+	tx, err := wr.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// the counters start over, items imported before are skipped quickly
+	query := `
+		UPDATE wordpress_imports SET
+			status = 'running',
+			processed = 0,
+			failed = 0,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM wordpress_imports
+			WHERE status = 'pending' OR status = 'running' AND updated_at < $1
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + wordPressImportColumns
+
+	i, err := scanWordPressImport(tx.QueryRow(query, staleBefore))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("DELETE FROM wordpress_import_errors WHERE import_id = $1", i.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return i, tx.Commit()
+}
+
+func (wr *wordPressRepo) Progress(import_id, total, processed, failed int64) error {
+	query := `
+		UPDATE wordpress_imports SET
+			total = $2,
+			processed = $3,
+			failed = $4,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err := wr.db.Exec(query, import_id, total, processed, failed)
+
+	return err
+}
+
+func (wr *wordPressRepo) AddError(import_id int64, kind, wpID, message string) error {
+	query := `
+		INSERT INTO wordpress_import_errors(import_id, kind, wp_id, error)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err := wr.db.Exec(query, import_id, kind, wpID, message)
+
+	return err
+}
+
+func (wr *wordPressRepo) Finish(import_id int64, err error) error {
+	status, message := repo.ImportStatusDone, (*string)(nil)
+	if err != nil {
+		msg := err.Error()
+		status, message = repo.ImportStatusFailed, &msg
+	}
+
+	query := `
+		UPDATE wordpress_imports SET
+			status = $2,
+			error = $3,
+			updated_at = CURRENT_TIMESTAMP,
+			finished_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err = wr.db.Exec(query, import_id, status, message)
+
+	return err
+}
+
+func (wr *wordPressRepo) GetItem(site, kind, wpID string) (int64, error) {
+	var id int64
+
+	err := wr.db.QueryRow(
+		"SELECT local_id FROM wordpress_items WHERE site = $1 AND kind = $2 AND wp_id = $3",
+		site,
+		kind,
+		wpID,
+	).Scan(&id)
+
+	return id, err
+}
+
+func (wr *wordPressRepo) SetItem(site, kind, wpID string, localID int64) error {
+	query := `
+		INSERT INTO wordpress_items(site, kind, wp_id, local_id)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (site, kind, wp_id) DO UPDATE SET local_id = EXCLUDED.local_id
+	`
+
+	_, err := wr.db.Exec(query, site, kind, wpID, localID)
+
+	return err
+}
+
+func (wr *wordPressRepo) FindPost(userID int64, title string, createdAt time.Time) (int64, error) {
+	var id int64
+
+	err := wr.db.QueryRow(
+		"SELECT id FROM posts WHERE user_id = $1 AND title = $2 AND created_at = $3 AND deleted_at IS NULL",
+		userID,
+		title,
+		createdAt,
+	).Scan(&id)
+
+	return id, err
+}
+
+func (wr *wordPressRepo) FindComment(postID, userID int64, createdAt time.Time) (int64, error) {
+	var id int64
+
+	err := wr.db.QueryRow(
+		"SELECT id FROM comments WHERE post_id = $1 AND user_id = $2 AND created_at = $3 AND deleted_at IS NULL",
+		postID,
+		userID,
+		createdAt,
+	).Scan(&id)
+
+	return id, err
+}
@@ -0,0 +1,134 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bxcodec/faker/v4"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+	"github.com/nurmuhammaddeveloper/blog_db/wordpress"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordPressImport(t *testing.T) {
+	user := createUser(t)
+
+	imp, err := dbManager.WordPress().Create(&repo.WordPressImport{
+		UserID:   user.ID,
+		FileName: "export.xml",
+	})
+	require.NoError(t, err)
+	require.Equal(t, repo.ImportStatusPending, imp.Status)
+
+	// imports left pending by other tests are claimed first
+	var claimed *repo.WordPressImport
+	for i := 0; i < 10 && (claimed == nil || claimed.ID != imp.ID); i++ {
+		claimed, err = dbManager.WordPress().Claim(time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+	}
+	require.Equal(t, imp.ID, claimed.ID)
+	require.Equal(t, repo.ImportStatusRunning, claimed.Status)
+
+	require.NoError(t, dbManager.WordPress().Progress(imp.ID, 10, 2, 1))
+	require.NoError(t, dbManager.WordPress().AddError(imp.ID, repo.WordPressItemPost, "11", "failed"))
+	require.NoError(t, dbManager.WordPress().Finish(imp.ID, errors.New("stopped")))
+
+	got, err := dbManager.WordPress().Get(imp.ID)
+	require.NoError(t, err)
+	require.Equal(t, repo.ImportStatusFailed, got.Status)
+	require.Equal(t, int64(10), got.Total)
+	require.Equal(t, int64(2), got.Processed)
+	require.Equal(t, "stopped", *got.Error)
+	require.Len(t, got.Errors, 1)
+	require.NotNil(t, got.FinishedAt)
+
+	site := "https://" + faker.DomainName()
+	_, err = dbManager.WordPress().GetItem(site, repo.WordPressItemUser, "jane")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, dbManager.WordPress().SetItem(site, repo.WordPressItemUser, "jane", user.ID))
+	id, err := dbManager.WordPress().GetItem(site, repo.WordPressItemUser, "jane")
+	require.NoError(t, err)
+	require.Equal(t, user.ID, id)
+}
+
+// mediaTransport answers every media download with the same bytes.
+type mediaTransport struct{}
+
+func (mediaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("png")),
+		Request:    req,
+	}, nil
+}
+
+func TestWordPressImporter(t *testing.T) {
+	user := createUser(t)
+
+	f, err := os.Open("../../wordpress/testdata/export.xml")
+	require.NoError(t, err)
+	defer f.Close()
+
+	export, err := wordpress.Parse(f)
+	require.NoError(t, err)
+	export.Site = "https://" + faker.DomainName() + "/" + faker.UUIDDigit()
+
+	opts := wordpress.Options{
+		MediaDir: t.TempDir(),
+		MediaURL: "/medias/",
+		Client:   &http.Client{Transport: mediaTransport{}},
+		Language: "en",
+	}
+
+	// running the same export twice imports everything once
+	var postID int64
+	for run := 0; run < 2; run++ {
+		imp, err := dbManager.WordPress().Create(&repo.WordPressImport{
+			UserID:   user.ID,
+			FileName: "export.xml",
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, wordpress.Import(context.Background(), dbManager, imp, export, &opts))
+
+		// the comment without an email fails without stopping the import
+		got, err := dbManager.WordPress().Get(imp.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), got.Failed)
+		require.Len(t, got.Errors, 1)
+		require.Equal(t, "7", got.Errors[0].WpID)
+
+		id, err := dbManager.WordPress().GetItem(export.Site, repo.WordPressItemPost, "11")
+		require.NoError(t, err)
+		if postID != 0 {
+			require.Equal(t, postID, id)
+		}
+		postID = id
+
+		post, err := dbManager.Post().Get(postID)
+		require.NoError(t, err)
+		require.Equal(t, "en", post.Language)
+
+		comments, err := dbManager.Comment().GetAll(&repo.GetCommentsParams{
+			Limit:    10,
+			Page:     1,
+			PostID:   postID,
+			Threaded: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, comments.Comments, 2)
+		require.Nil(t, comments.Comments[0].ParentID)
+		require.Equal(t, comments.Comments[0].ID, *comments.Comments[1].ParentID)
+	}
+
+	deletePost(t, postID)
+	deleteUser(t, user.ID)
+}
@@ -1,28 +1,46 @@
 package repo
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+var ErrCategoryExists = errors.New("category with this title already exists")
 
 type Category struct {
 	ID        int64
 	Title     string
 	CreatedAt time.Time
+	DeletedAt *time.Time
 }
 
 type CategoryStorageI interface {
 	Create(c *Category) (*Category, error)
 	Get(category_id int64) (*Category, error)
+	GetByTitle(title string) (*Category, error)
 	Update(u *Category) (*Category, error)
 	Delete(category_id int64) error
 	GetAll(params *GetAllCategoryParams) (*GetAllCategoryResult, error)
+	Restore(category_id int64) error
+	// PurgeDeleted permanently removes categories deleted before the given
+	// time. Categories still used by a post are kept.
+	PurgeDeleted(before time.Time) (int64, error)
 }
 
 type GetAllCategoryParams struct {
 	Limit int32
 	Page int32
 	Search string
+	Sort   []*SortField
+	After  *Cursor
+	Before *Cursor
+	// Deleted lists the trash instead of the live categories.
+	Deleted bool
 }
 
 type GetAllCategoryResult struct {
 	Categories []*Category
-	Count int32
+	Count      int32
+	NextCursor *Cursor
+	PrevCursor *Cursor
 }
\ No newline at end of file
@@ -2,14 +2,35 @@ package repo
 
 import "time"
 
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+	CommentStatusSpam     = "spam"
+)
+
 type Comment struct {
 	ID          int64
 	PostID      int64
 	UserID      int64
+	ParentID    *int64
 	Description string
 	CreatedAt   time.Time
 	UpdatedAt   *time.Time
+	DeletedAt   *time.Time
 	User        CommentUser
+	// Depth is 0 for top level comments. Path holds the ids from the root
+	// of the thread down to the comment itself.
+	Depth      int32
+	Path       []int64
+	ReplyCount int64
+	// Status is approved for the comments everybody can see.
+	Status      string
+	ModeratedBy *int64
+	ModeratedAt *time.Time
+	Mentions    []*Mention
+	// EditCount counts the edits that changed the description.
+	EditCount int32
 }
 
 type UpdateComment struct {
@@ -20,6 +41,23 @@ type UpdateComment struct {
 	CreatedAt   time.Time
 	UpdatedAt   *time.Time
 	User        CommentUser
+	// Status keeps the current one when empty.
+	Status string
+	// EditedBy is the user editing the comment, kept with the replaced
+	// description.
+	EditedBy  int64
+	EditCount int32
+}
+
+// CommentRevision is what a comment said before an edit. WrittenAt is when
+// that description was written, EditedAt when it was replaced.
+type CommentRevision struct {
+	ID          int64
+	CommentID   int64
+	Description string
+	WrittenAt   time.Time
+	EditedBy    *int64
+	EditedAt    time.Time
 }
 
 type CommentUser struct {
@@ -31,15 +69,36 @@ type CommentUser struct {
 }
 
 type CommentStorageI interface {
+	// Create places a reply below its parent.
 	Create(u *Comment) (*Comment, error)
+	Get(comment_id int64) (*Comment, error)
+	// Update keeps the replaced description as a revision when it
+	// changes.
 	Update(u *UpdateComment) (*UpdateComment, error)
 	Delete(comment_id int64) error
 	GetAll(params *GetCommentsParams) (*GetAllCommentsResult, error)
+	// Restore takes a comment out of the trash. A non-zero userID only
+	// restores the comment if it belongs to that user.
+	Restore(comment_id, userID int64) error
+	// Moderate sets the status of the comments, returning how many were
+	// changed. Comments hidden by a report are left as they are until the
+	// report is closed.
+	Moderate(m *ModerateComments) (int64, error)
+	// HasApproved reports whether the user has an approved comment.
+	HasApproved(userID int64) (bool, error)
+	// GetRevisions returns the earlier descriptions of a comment, oldest
+	// first.
+	GetRevisions(comment_id int64) ([]*CommentRevision, error)
+	// PurgeDeleted permanently removes comments deleted before the given
+	// time, keeping those with replies that are still around.
+	PurgeDeleted(before time.Time) (int64, error)
 }
 
 type GetAllCommentsResult struct {
-	Comments []*Comment
-	Count    int64
+	Comments   []*Comment
+	Count      int64
+	NextCursor *Cursor
+	PrevCursor *Cursor
 }
 
 type GetCommentsParams struct {
@@ -47,4 +106,36 @@ type GetCommentsParams struct {
 	Page   int64
 	UserID int64
 	PostID int64
+	Sort   []*SortField
+	After  *Cursor
+	Before *Cursor
+	// Deleted lists the trash instead of the live comments.
+	Deleted bool
+	// Threaded lists comments depth first, thread by thread, with deleted
+	// comments that still have replies kept as tombstones. ParentID then
+	// lists the whole thread below a comment instead of its replies.
+	Threaded bool
+	ParentID int64
+	// MaxDepth leaves out deeper comments when set.
+	MaxDepth *int32
+	// Status lists the comments in that status instead of the approved
+	// ones. ModeratorID restricts them to the posts the moderator
+	// contributes to.
+	Status      string
+	ModeratorID int64
+	// Comments are only listed on posts the viewer can see: public and
+	// unlisted ones, the password protected post UnlockedPostID, and the
+	// posts ViewerID contributes to. Admins see them all.
+	IsSuperadmin   bool
+	ViewerID       int64
+	UnlockedPostID int64
+}
+
+type ModerateComments struct {
+	IDs         []int64
+	Status      string
+	ModeratorID int64
+	// UserID restricts the comments to the posts that user contributes
+	// to, for moderators who aren't admins.
+	UserID int64
 }
@@ -0,0 +1,31 @@
+package repo
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidFeaturedPosts is returned when a featured post does not exist
+// or is listed twice.
+var ErrInvalidFeaturedPosts = errors.New("featured posts must exist and be listed once")
+
+// Pin puts a post above the others, globally or within its category when
+// CategoryID is set, until ExpiresAt if given.
+type Pin struct {
+	PostID     int64
+	CategoryID *int64
+	Position   int32
+	ExpiresAt  *time.Time
+	CreatedAt  time.Time
+}
+
+type CurationStorageI interface {
+	// Pin pins a post or updates an existing pin of the same scope.
+	Pin(p *Pin) (*Pin, error)
+	// Unpin removes the pin of a post, the global one for categoryID 0.
+	Unpin(post_id, categoryID int64) error
+	// GetPins returns the pins that haven't expired, in order.
+	GetPins() ([]*Pin, error)
+	// SetFeatured replaces the featured posts, in the given order.
+	SetFeatured(postIDs []int64) error
+}
@@ -0,0 +1,40 @@
+package repo
+
+import "time"
+
+// FilterOutcome records what the content filter decided about a comment
+// or a post, and why.
+type FilterOutcome struct {
+	ID         int64
+	TargetType string
+	// TargetID is nil for rejected content, which is never saved.
+	TargetID    *int64
+	UserID      int64
+	Action      string
+	Reasons     []string
+	ContentHash string
+	CreatedAt   time.Time
+}
+
+type FilterStorageI interface {
+	Record(o *FilterOutcome) (*FilterOutcome, error)
+	// CountDuplicates counts the content with the hash recorded since the
+	// given time, by the user and by other users. The target itself is
+	// left out, so edits don't duplicate the content they replace.
+	CountDuplicates(hash string, userID int64, targetType string, targetID int64, since time.Time) (own, others int64, err error)
+	GetAll(params *GetFilterOutcomesParams) (*GetAllFilterOutcomesResult, error)
+}
+
+type GetFilterOutcomesParams struct {
+	Limit      int64
+	Page       int64
+	TargetType string
+	TargetID   int64
+	UserID     int64
+	Action     string
+}
+
+type GetAllFilterOutcomesResult struct {
+	Outcomes []*FilterOutcome
+	Count    int64
+}
@@ -0,0 +1,32 @@
+package repo
+
+import "time"
+
+// The kinds of content users can be mentioned in.
+const (
+	MentionTargetPost    = "post"
+	MentionTargetComment = "comment"
+)
+
+// Mention is a user mentioned as @username in a post or a comment.
+type Mention struct {
+	TargetType string `json:"-"`
+	TargetID   int64  `json:"-"`
+	// PostID is the post itself, or the post of the comment.
+	PostID      int64     `json:"-"`
+	UserID      int64     `json:"user_id"`
+	Username    string    `json:"username"`
+	MentionedBy *int64    `json:"-"`
+	CreatedAt   time.Time `json:"-"`
+}
+
+type MentionStorageI interface {
+	// Set replaces the mentions of the content with the users having the
+	// usernames, leaving out those that don't exist. Users who stay
+	// mentioned keep their mention, so they are only notified once.
+	Set(targetType string, targetID, mentionedBy int64, usernames []string) ([]*Mention, error)
+	// ClaimNotifications marks the mentions in the content that can be
+	// seen and whose users weren't notified yet as notified, returning
+	// them. Users mentioning themselves are never notified.
+	ClaimNotifications(targetType string, targetIDs []int64) ([]*Mention, error)
+}
@@ -1,17 +1,119 @@
 package repo
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+const (
+	ContributorRoleAuthor   = "author"
+	ContributorRoleCoAuthor = "co-author"
+	ContributorRoleEditor   = "editor"
+)
+
+const (
+	PostVisibilityPublic   = "public"
+	PostVisibilityUnlisted = "unlisted"
+	PostVisibilityPrivate  = "private"
+	PostVisibilityPassword = "password"
+)
+
+const (
+	PostCommentsOpen      = "open"
+	PostCommentsClosed    = "closed"
+	PostCommentsModerated = "moderated"
+	// PostCommentsFirstTimeModerated holds the comments of users who have
+	// no approved comment yet.
+	PostCommentsFirstTimeModerated = "first_time_moderated"
+)
+
+// ErrSlugExists is returned when another translation in the same language
+// has the slug.
+var ErrSlugExists = errors.New("slug is already used in this language")
 
 type Post struct {
-	ID          int64
-	Title       string
-	Description string
-	ImageUrl    *string
-	UserID      int64
-	CategoryID  int64
-	CreatedAt   time.Time
-	UpdatedAt   *time.Time
-	ViewsCount  int32
+	ID              int64
+	Title           string
+	Description     string
+	DescriptionHtml string
+	TableOfContents []*PostHeading
+	ReadingTime     int32
+	ImageUrl        *string
+	Visibility      string
+	Password        *string
+	Tags            []string
+	Contributors    []*PostContributor
+	UserID          int64
+	CategoryID      int64
+	CreatedAt       time.Time
+	UpdatedAt       *time.Time
+	ViewsCount      int32
+	DeletedAt       *time.Time
+	SearchRank      float64
+	Headline        *string
+	// Language is the language of the content above, Translations the
+	// other languages the post is available in. Slug is only set when the
+	// content is a translation.
+	Language     string
+	Translations []string
+	Slug         *string
+	// CommentStatus tells who can comment on the post and whether the
+	// comments are held for moderation.
+	CommentStatus string
+	Mentions      []*Mention
+}
+
+// PostContributor is a user who can edit a post. The author is the owner
+// of the post and is always listed first.
+type PostContributor struct {
+	UserID          int64   `json:"user_id"`
+	Role            string  `json:"role"`
+	FirstName       string  `json:"first_name"`
+	LastName        string  `json:"last_name"`
+	Username        *string `json:"username"`
+	ProfileImageUrl *string `json:"profile_image_url"`
+}
+
+// PostSEO overrides the metadata derived from a post when sharing it.
+// Nil fields are derived from the post.
+type PostSEO struct {
+	PostID          int64
+	MetaTitle       *string
+	MetaDescription *string
+	CanonicalUrl    *string
+	ImageUrl        *string
+	UpdatedAt       *time.Time
+}
+
+// PostTranslation is the content of a post in another language.
+type PostTranslation struct {
+	PostID          int64
+	Language        string
+	Title           string
+	Slug            string
+	Description     string
+	DescriptionHtml string
+	TableOfContents []*PostHeading
+	ReadingTime     int32
+	CreatedAt       time.Time
+	UpdatedAt       *time.Time
+}
+
+type RelatedPost struct {
+	ID         int64
+	Title      string
+	ImageUrl   *string
+	UserID     int64
+	CategoryID int64
+	CreatedAt  time.Time
+	Tags       []string
+	Score      float64
+}
+
+type PostHeading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
 }
 
 type PostStorageI interface {
@@ -20,11 +122,45 @@ type PostStorageI interface {
 	Update(u *Post) (*Post, error)
 	Delete(post_id int64) error
 	GetAll(params *GetPostsParams) (*GetAllPostResult, error)
+	// GetRelated ranks other public posts by shared category and tags,
+	// title similarity and users who liked both.
+	GetRelated(post_id, limit int64) ([]*RelatedPost, error)
+	// GetPublicIDs returns the ids, of the given ones, whose posts are
+	// public and not deleted.
+	GetPublicIDs(postIDs []int64) ([]int64, error)
+	// SetContributor adds a contributor or changes their role. The author
+	// can't be changed.
+	SetContributor(post_id, userID int64, role string) error
+	RemoveContributor(post_id, userID int64) error
+	// GetSEO returns the metadata overrides of a post, with every field
+	// nil when there are none.
+	GetSEO(post_id int64) (*PostSEO, error)
+	SetSEO(s *PostSEO) (*PostSEO, error)
+	// SetTranslation adds a translation or replaces the one in its
+	// language.
+	SetTranslation(t *PostTranslation) (*PostTranslation, error)
+	DeleteTranslation(post_id int64, language string) error
+	// GetTranslations returns the translations of the posts in the given
+	// languages, or in every language when none are given.
+	GetTranslations(postIDs []int64, languages []string) ([]*PostTranslation, error)
+	// GetTranslationBySlug returns the translation with the slug in a
+	// language.
+	GetTranslationBySlug(language, slug string) (*PostTranslation, error)
+	// AddViews adds view counts, keyed by post id.
+	AddViews(views map[int64]int64) error
+	// Restore takes a post out of the trash. A non-zero userID only
+	// restores the post if it belongs to that user.
+	Restore(post_id, userID int64) error
+	// PurgeDeleted permanently removes posts deleted before the given time
+	// along with their comments and likes.
+	PurgeDeleted(before time.Time) (int64, error)
 }
 
 type GetAllPostResult struct {
-	Posts []*Post
-	Count int64
+	Posts      []*Post
+	Count      int64
+	NextCursor *Cursor
+	PrevCursor *Cursor
 }
 
 type GetPostsParams struct {
@@ -34,4 +170,21 @@ type GetPostsParams struct {
 	UserID     int64
 	CategoryID int64
 	SortByDate string
+	Sort       []*SortField
+	After      *Cursor
+	Before     *Cursor
+	// ViewerID and IsSuperadmin describe who is listing. Private posts
+	// are only listed for their contributors and superadmins.
+	ViewerID     int64
+	IsSuperadmin bool
+	// Deleted lists the trash instead of the live posts.
+	Deleted bool
+	// PinnedFirst puts the pinned posts first, the pins of CategoryID when
+	// it is set and the global ones otherwise.
+	PinnedFirst bool
+	// Featured only lists the featured posts, in their curated order.
+	Featured bool
+	// AllVisibilities lists every live post, unlisted ones and those of
+	// deleted categories included, for exports.
+	AllVisibilities bool
 }
@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"errors"
+	"time"
+)
+
+// The kinds of content that can be reported.
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+)
+
+var ErrUnknownReportTarget = errors.New("only posts, comments and users can be reported")
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// Report gathers the flags of users on a piece of content. Flags of the
+// same content are merged into its open report.
+type Report struct {
+	ID         int64
+	TargetType string
+	TargetID   int64
+	Status     string
+	FlagsCount int64
+	// Hidden is set while the report keeps its target hidden: posts are
+	// made private, comments go back to pending and profiles aren't shown.
+	Hidden     bool
+	Resolution *string
+	ResolvedBy *int64
+	ResolvedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  *time.Time
+	Flags      []*ReportFlag
+}
+
+type ReportFlag struct {
+	UserID    int64
+	Reason    string
+	Details   *string
+	CreatedAt time.Time
+}
+
+type ReportStorageI interface {
+	// Flag adds the flag of a user to the open report of the content,
+	// opening one when there is none. Flagging the same content again
+	// replaces the earlier flag. The content is hidden once hideAfter
+	// users flagged it.
+	Flag(targetType string, targetID int64, flag *ReportFlag, hideAfter int64) (*Report, error)
+	// Get returns a report with its flags.
+	Get(report_id int64) (*Report, error)
+	GetAll(params *GetReportsParams) (*GetAllReportsResult, error)
+	// Close resolves or dismisses an open report. Resolving hides the
+	// content, dismissing shows it again if the report hid it. The report
+	// is returned with its flags.
+	Close(report_id int64, status string, moderatorID int64, resolution *string) (*Report, error)
+	// IsHidden reports whether a report hides the content.
+	IsHidden(targetType string, targetID int64) (bool, error)
+}
+
+type GetReportsParams struct {
+	Limit      int64
+	Page       int64
+	Status     string
+	TargetType string
+	TargetID   int64
+}
+
+type GetAllReportsResult struct {
+	Reports []*Report
+	Count   int64
+}
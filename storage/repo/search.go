@@ -0,0 +1,57 @@
+package repo
+
+import "time"
+
+const (
+	SearchTypePosts      = "posts"
+	SearchTypeUsers      = "users"
+	SearchTypeCategories = "categories"
+)
+
+type SearchStorageI interface {
+	Search(params *SearchParams) (*SearchResult, error)
+}
+
+type SearchParams struct {
+	Query string
+	Types []string
+	Limit int64
+}
+
+type SearchResult struct {
+	Posts           []*SearchPost
+	PostsCount      int64
+	Users           []*SearchUser
+	UsersCount      int64
+	Categories      []*SearchCategory
+	CategoriesCount int64
+}
+
+type SearchPost struct {
+	ID                   int64
+	Title                string
+	TitleHighlight       string
+	DescriptionHighlight string
+	ImageUrl             *string
+	UserID               int64
+	CategoryID           int64
+	CreatedAt            time.Time
+	Rank                 float64
+}
+
+type SearchUser struct {
+	ID              int64
+	FirstName       string
+	LastName        string
+	UserName        *string
+	ProfileImageUrl *string
+	Highlight       string
+	Rank            float64
+}
+
+type SearchCategory struct {
+	ID        int64
+	Title     string
+	Highlight string
+	Rank      float64
+}
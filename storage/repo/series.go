@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidSeriesPosts is returned when a post added to a series does not
+// exist, belongs to someone else or is already part of another series.
+var ErrInvalidSeriesPosts = errors.New("posts must belong to the author of the series and to no other series")
+
+type Series struct {
+	ID          int64
+	Title       string
+	Description string
+	UserID      int64
+	CreatedAt   time.Time
+	UpdatedAt   *time.Time
+	Posts       []*SeriesPost
+}
+
+// SeriesPost is a post of a series, in the order of Position.
+type SeriesPost struct {
+	ID         int64
+	Title      string
+	Visibility string
+	Position   int32
+}
+
+type SeriesStorageI interface {
+	Create(s *Series) (*Series, error)
+	// Get returns a series with its live posts in order.
+	Get(series_id int64) (*Series, error)
+	// GetByPost returns the series a post belongs to.
+	GetByPost(post_id int64) (*Series, error)
+	Update(s *Series) (*Series, error)
+	Delete(series_id int64) error
+	// SetPosts replaces the posts of a series, in the given order.
+	SetPosts(series_id int64, postIDs []int64) error
+}
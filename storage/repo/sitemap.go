@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"errors"
+	"time"
+)
+
+// Sitemap kinds, one sitemap per kind and chunk.
+const (
+	SitemapPosts      = "posts"
+	SitemapCategories = "categories"
+	SitemapUsers      = "users"
+)
+
+var ErrUnknownSitemap = errors.New("unknown sitemap")
+
+// SitemapEntry is a page listed in a sitemap: a public post, a category or
+// the page of an author of a public post.
+type SitemapEntry struct {
+	ID      int64
+	LastMod time.Time
+}
+
+// SitemapChunk is a part of a sitemap. Chunk n holds the entries with ids
+// from (n-1)*size+1 to n*size, so a change to an entry only ever touches
+// the chunk of its id.
+type SitemapChunk struct {
+	Number  int64
+	LastMod time.Time
+}
+
+type SitemapStorageI interface {
+	// GetChunks returns the chunks of a kind that have entries, in order.
+	GetChunks(kind string, size int64) ([]*SitemapChunk, error)
+	// GetEntries returns the entries of a chunk ordered by id.
+	GetEntries(kind string, chunk, size int64) ([]*SitemapEntry, error)
+}
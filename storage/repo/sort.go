@@ -0,0 +1,18 @@
+package repo
+
+import "errors"
+
+var (
+	ErrInvalidSortField = errors.New("invalid sort field")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Cursor points at a row of a listing by the values of its sort keys.
+type Cursor struct {
+	Values []string
+}
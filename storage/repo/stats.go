@@ -0,0 +1,52 @@
+package repo
+
+import "time"
+
+type DailyStats struct {
+	Day           time.Time
+	Views         int64
+	UniqueViewers int64
+	Likes         int64
+	Dislikes      int64
+	Comments      int64
+}
+
+// PostDayViews holds the views of a post counted in Redis during one day.
+type PostDayViews struct {
+	PostID        int64
+	Views         int64
+	UniqueViewers int64
+}
+
+type TopPost struct {
+	ID       int64
+	Title    string
+	Views    int64
+	Likes    int64
+	Comments int64
+}
+
+type GetStatsParams struct {
+	PostID int64
+	UserID int64
+	From   time.Time
+	To     time.Time
+	Limit  int64
+}
+
+type UserStats struct {
+	Days     []*DailyStats
+	TopPosts []*TopPost
+}
+
+type StatsStorageI interface {
+	// Rollup stores the aggregates of one day. It can be run any number of
+	// times for the same day; views never go down.
+	Rollup(day time.Time, views []*PostDayViews) error
+	// RefreshRankings recomputes the trending and top scores of posts.
+	RefreshRankings() error
+	// GetPostStats returns one entry per day from From to To.
+	GetPostStats(params *GetStatsParams) ([]*DailyStats, error)
+	// GetUserStats sums the stats of all posts of a user.
+	GetUserStats(params *GetStatsParams) (*UserStats, error)
+}
@@ -40,9 +40,14 @@ type GetAllUserParams struct {
 	Limit  int32
 	Page   int32
 	Search string
+	Sort   []*SortField
+	After  *Cursor
+	Before *Cursor
 }
 
 type GetAllUsersResult struct {
-	Users []*User
-	Count int32
+	Users      []*User
+	Count      int32
+	NextCursor *Cursor
+	PrevCursor *Cursor
 }
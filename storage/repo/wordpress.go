@@ -0,0 +1,66 @@
+package repo
+
+import "time"
+
+const (
+	ImportStatusPending = "pending"
+	ImportStatusRunning = "running"
+	ImportStatusDone    = "done"
+	ImportStatusFailed  = "failed"
+)
+
+// The kinds of WordPress items an import maps to local rows.
+const (
+	WordPressItemUser     = "user"
+	WordPressItemCategory = "category"
+	WordPressItemPost     = "post"
+	WordPressItemComment  = "comment"
+)
+
+// WordPressImport is a background import of a WordPress export file.
+// Processed counts the items handled so far, Failed those of them that
+// couldn't be imported.
+type WordPressImport struct {
+	ID         int64
+	UserID     int64
+	FileName   string
+	Status     string
+	Total      int64
+	Processed  int64
+	Failed     int64
+	Error      *string
+	CreatedAt  time.Time
+	UpdatedAt  *time.Time
+	FinishedAt *time.Time
+	Errors     []*WordPressImportError
+}
+
+type WordPressImportError struct {
+	Kind      string
+	WpID      string
+	Error     string
+	CreatedAt time.Time
+}
+
+type WordPressStorageI interface {
+	Create(i *WordPressImport) (*WordPressImport, error)
+	// Get returns an import with its latest item errors.
+	Get(import_id int64) (*WordPressImport, error)
+	// Claim starts the oldest pending import, or a running one whose
+	// progress hasn't been reported since staleBefore, from scratch.
+	// It returns sql.ErrNoRows when there is nothing to run.
+	Claim(staleBefore time.Time) (*WordPressImport, error)
+	// Progress reports the counters of a running import.
+	Progress(import_id, total, processed, failed int64) error
+	AddError(import_id int64, kind, wpID, message string) error
+	// Finish marks an import done, or failed with the error when it isn't nil.
+	Finish(import_id int64, err error) error
+	// GetItem returns the local id a WordPress item of the site was
+	// imported as, or sql.ErrNoRows.
+	GetItem(site, kind, wpID string) (int64, error)
+	SetItem(site, kind, wpID string, localID int64) error
+	// FindPost and FindComment find rows created by an import that was
+	// interrupted before it could record them with SetItem.
+	FindPost(userID int64, title string, createdAt time.Time) (int64, error)
+	FindComment(postID, userID int64, createdAt time.Time) (int64, error)
+}
@@ -12,23 +12,50 @@ type StorageI interface {
 	Post() repo.PostStorageI
 	Comment() repo.CommentStorageI
 	Like() repo.LikeStorageI
+	Search() repo.SearchStorageI
+	Stats() repo.StatsStorageI
+	Series() repo.SeriesStorageI
+	Curation() repo.CurationStorageI
+	WordPress() repo.WordPressStorageI
+	Sitemap() repo.SitemapStorageI
+	Filter() repo.FilterStorageI
+	Report() repo.ReportStorageI
+	Mention() repo.MentionStorageI
 }
 
 type StoragePg struct {
-	userRepo     repo.UserStorageI
-	categoryRepo repo.CategoryStorageI
-	postRepo     repo.PostStorageI
-	commentRepo  repo.CommentStorageI
-	likeRepo     repo.LikeStorageI
+	userRepo      repo.UserStorageI
+	categoryRepo  repo.CategoryStorageI
+	postRepo      repo.PostStorageI
+	commentRepo   repo.CommentStorageI
+	likeRepo      repo.LikeStorageI
+	searchRepo    repo.SearchStorageI
+	statsRepo     repo.StatsStorageI
+	seriesRepo    repo.SeriesStorageI
+	curationRepo  repo.CurationStorageI
+	wordPressRepo repo.WordPressStorageI
+	sitemapRepo   repo.SitemapStorageI
+	filterRepo    repo.FilterStorageI
+	reportRepo    repo.ReportStorageI
+	mentionRepo   repo.MentionStorageI
 }
 
 func NewStoragePg(db *sqlx.DB) StorageI {
 	return &StoragePg{
-		userRepo:     postgres.NewUser(db),
-		categoryRepo: postgres.NewCategory(db),
-		postRepo:     postgres.NewPost(db),
-		commentRepo:  postgres.NewComment(db),
-		likeRepo:     postgres.NewLike(db),
+		userRepo:      postgres.NewUser(db),
+		categoryRepo:  postgres.NewCategory(db),
+		postRepo:      postgres.NewPost(db),
+		commentRepo:   postgres.NewComment(db),
+		likeRepo:      postgres.NewLike(db),
+		searchRepo:    postgres.NewSearch(db),
+		statsRepo:     postgres.NewStats(db),
+		seriesRepo:    postgres.NewSeries(db),
+		curationRepo:  postgres.NewCuration(db),
+		wordPressRepo: postgres.NewWordPress(db),
+		sitemapRepo:   postgres.NewSitemap(db),
+		filterRepo:    postgres.NewFilter(db),
+		reportRepo:    postgres.NewReport(db),
+		mentionRepo:   postgres.NewMention(db),
 	}
 }
 
@@ -51,3 +78,39 @@ func (s *StoragePg) Comment() repo.CommentStorageI {
 func (s *StoragePg) Like() repo.LikeStorageI {
 	return s.likeRepo
 }
+
+func (s *StoragePg) Search() repo.SearchStorageI {
+	return s.searchRepo
+}
+
+func (s *StoragePg) Stats() repo.StatsStorageI {
+	return s.statsRepo
+}
+
+func (s *StoragePg) Series() repo.SeriesStorageI {
+	return s.seriesRepo
+}
+
+func (s *StoragePg) Curation() repo.CurationStorageI {
+	return s.curationRepo
+}
+
+func (s *StoragePg) WordPress() repo.WordPressStorageI {
+	return s.wordPressRepo
+}
+
+func (s *StoragePg) Sitemap() repo.SitemapStorageI {
+	return s.sitemapRepo
+}
+
+func (s *StoragePg) Filter() repo.FilterStorageI {
+	return s.filterRepo
+}
+
+func (s *StoragePg) Report() repo.ReportStorageI {
+	return s.reportRepo
+}
+
+func (s *StoragePg) Mention() repo.MentionStorageI {
+	return s.mentionRepo
+}
@@ -0,0 +1,148 @@
+// Package wordpress imports WordPress eXtended RSS (WXR) export files.
+package wordpress
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNoSite is returned for exports that don't say which site they are from.
+var ErrNoSite = errors.New("export has no site URL")
+
+const dateLayout = "2006-01-02 15:04:05"
+
+// Export is the content of a WXR file.
+type Export struct {
+	// Site identifies the WordPress site, items are only imported once
+	// per site.
+	Site       string
+	Authors    []*Author
+	Categories []*Category
+	Items      []*Item
+}
+
+type Author struct {
+	ID          string `xml:"author_id"`
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+	FirstName   string `xml:"author_first_name"`
+	LastName    string `xml:"author_last_name"`
+}
+
+type Category struct {
+	Nicename string `xml:"category_nicename"`
+	Name     string `xml:"cat_name"`
+}
+
+// Item is a post, page or attachment.
+type Item struct {
+	Title         string     `xml:"title"`
+	Creator       string     `xml:"creator"`
+	Content       string     `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	ID            string     `xml:"post_id"`
+	Date          string     `xml:"post_date"`
+	DateGMT       string     `xml:"post_date_gmt"`
+	ModifiedGMT   string     `xml:"post_modified_gmt"`
+	Status        string     `xml:"status"`
+	Type          string     `xml:"post_type"`
+	Password      string     `xml:"post_password"`
+	AttachmentURL string     `xml:"attachment_url"`
+	Terms         []*Term    `xml:"category"`
+	Meta          []*Meta    `xml:"postmeta"`
+	Comments      []*Comment `xml:"comment"`
+}
+
+// Term is a category or tag of an item.
+type Term struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type Meta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+type Comment struct {
+	ID          string `xml:"comment_id"`
+	Author      string `xml:"comment_author"`
+	AuthorEmail string `xml:"comment_author_email"`
+	Date        string `xml:"comment_date"`
+	DateGMT     string `xml:"comment_date_gmt"`
+	Content     string `xml:"comment_content"`
+	Approved    string `xml:"comment_approved"`
+	Type        string `xml:"comment_type"`
+	Parent      string `xml:"comment_parent"`
+	UserID      string `xml:"comment_user_id"`
+}
+
+// Parse reads a WXR file.
+func Parse(r io.Reader) (*Export, error) {
+	var doc struct {
+		Channel struct {
+			Link        string      `xml:"link"`
+			BaseSiteURL string      `xml:"base_site_url"`
+			Authors     []*Author   `xml:"author"`
+			Categories  []*Category `xml:"category"`
+			Items       []*Item     `xml:"item"`
+		} `xml:"channel"`
+	}
+
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	site := doc.Channel.BaseSiteURL
+	if site == "" {
+		site = doc.Channel.Link
+	}
+	site = strings.TrimRight(strings.TrimSpace(site), "/")
+	if site == "" {
+		return nil, ErrNoSite
+	}
+
+	return &Export{
+		Site:       site,
+		Authors:    doc.Channel.Authors,
+		Categories: doc.Channel.Categories,
+		Items:      doc.Channel.Items,
+	}, nil
+}
+
+// MetaValue returns the value of a post meta field.
+func (i *Item) MetaValue(key string) string {
+	for _, m := range i.Meta {
+		if m.Key == key {
+			return m.Value
+		}
+	}
+	return ""
+}
+
+// Tags returns the names of the tags of an item.
+func (i *Item) Tags() []string {
+	var tags []string
+	for _, t := range i.Terms {
+		if t.Domain == "post_tag" {
+			tags = append(tags, t.Name)
+		}
+	}
+	return tags
+}
+
+// parseDate reads a WordPress date, preferring the UTC one. Unset dates are
+// written as zeros, which gives the zero time.
+func parseDate(gmt, local string) time.Time {
+	for _, value := range []string{gmt, local} {
+		t, err := time.Parse(dateLayout, strings.TrimSpace(value))
+		if err == nil && t.Year() > 1 {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,48 @@
+package wordpress
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("testdata/export.xml")
+	require.NoError(t, err)
+	defer f.Close()
+
+	export, err := Parse(f)
+	require.NoError(t, err)
+	require.Equal(t, "https://old.example.com", export.Site)
+
+	require.Len(t, export.Authors, 1)
+	require.Equal(t, "jane", export.Authors[0].Login)
+	require.Equal(t, "jane@example.com", export.Authors[0].Email)
+
+	require.Len(t, export.Categories, 1)
+	require.Equal(t, "news", export.Categories[0].Nicename)
+	require.Equal(t, "News", export.Categories[0].Name)
+
+	require.Len(t, export.Items, 2)
+	require.Equal(t, "https://old.example.com/wp-content/uploads/cover.png", export.Items[0].AttachmentURL)
+
+	post := export.Items[1]
+	require.Equal(t, "post", post.Type)
+	require.Equal(t, "jane", post.Creator)
+	require.Equal(t, "<p>Welcome!</p>", post.Content)
+	require.Equal(t, []string{"Intro"}, post.Tags())
+	require.Equal(t, "10", post.MetaValue("_thumbnail_id"))
+	require.Equal(t, time.Date(2015, 3, 1, 10, 0, 0, 0, time.UTC), parseDate(post.DateGMT, post.Date))
+
+	require.Len(t, post.Comments, 3)
+	require.Equal(t, "5", post.Comments[1].Parent)
+	comment := post.Comments[0]
+	require.Equal(t, "bob@example.com", comment.AuthorEmail)
+	require.Equal(t, time.Date(2015, 3, 2, 12, 0, 0, 0, time.UTC), parseDate(comment.DateGMT, comment.Date))
+
+	_, err = Parse(strings.NewReader("<rss><channel></channel></rss>"))
+	require.ErrorIs(t, err, ErrNoSite)
+}
@@ -0,0 +1,558 @@
+package wordpress
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/markdown"
+	"github.com/nurmuhammaddeveloper/blog_db/pkg/utils"
+	"github.com/nurmuhammaddeveloper/blog_db/storage"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+const (
+	// maxMediaSize is the largest attachment that is downloaded.
+	maxMediaSize = 20 << 20
+
+	defaultCategory = "Uncategorized"
+	defaultTitle    = "Untitled"
+
+	// the users table limits
+	maxNameLength  = 30
+	maxEmailLength = 50
+)
+
+var (
+	ErrUnknownAuthor = errors.New("post author is not in the export")
+	ErrNoEmail       = errors.New("no email to create a user with")
+	ErrMediaStatus   = errors.New("unexpected media response status")
+	ErrMediaTooLarge = fmt.Errorf("media is larger than %d bytes", maxMediaSize)
+
+	// errSkipped is returned for items that are left out on purpose
+	errSkipped = errors.New("skipped")
+
+	// sizeSuffix matches the size WordPress appends to resized images
+	sizeSuffix = regexp.MustCompile(`-\d+x\d+(\.\w+)$`)
+)
+
+type Options struct {
+	// MediaDir is where attachments are downloaded to, and MediaURL the
+	// URL prefix they are served from.
+	MediaDir string
+	MediaURL string
+	Client   *http.Client
+	// Language is the language the posts are written in.
+	Language string
+}
+
+// Import runs an import of a parsed export. Items imported before, by this
+// or an earlier import of the same site, are skipped, so an interrupted
+// import can simply be run again. Items that fail are recorded and don't
+// stop the import, which only fails when progress can't be saved or ctx is
+// done.
+func Import(ctx context.Context, strg storage.StorageI, imp *repo.WordPressImport, export *Export, opts *Options) error {
+	im := importer{
+		strg:       strg,
+		imp:        imp,
+		export:     export,
+		opts:       opts,
+		authors:    make(map[string]int64),
+		authorIDs:  make(map[string]int64),
+		emails:     make(map[string]int64),
+		categories: make(map[string]int64),
+		media:      make(map[string]string),
+		mediaByID:  make(map[string]string),
+	}
+
+	var posts []*Item
+	for _, item := range export.Items {
+		switch item.Type {
+		case "post":
+			posts = append(posts, item)
+			im.total += int64(1 + len(item.Comments))
+		case "attachment":
+			im.total++
+		}
+	}
+	im.total += int64(len(export.Authors) + len(export.Categories))
+
+	steps := make([]func() error, 0, im.total)
+	for _, a := range export.Authors {
+		a := a
+		steps = append(steps, func() error {
+			return im.step(repo.WordPressItemUser, a.Login, func() error { return im.author(a) })
+		})
+	}
+	for _, c := range export.Categories {
+		c := c
+		steps = append(steps, func() error {
+			return im.step(repo.WordPressItemCategory, c.Nicename, func() error {
+				_, err := im.category(c.Nicename, c.Name)
+				return err
+			})
+		})
+	}
+	for _, item := range export.Items {
+		if item.Type == "attachment" {
+			item := item
+			steps = append(steps, func() error {
+				return im.step("media", item.ID, func() error { return im.attachment(item) })
+			})
+		}
+	}
+	for _, item := range posts {
+		item := item
+		steps = append(steps, func() error { return im.post(item) })
+	}
+
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type importer struct {
+	strg   storage.StorageI
+	imp    *repo.WordPressImport
+	export *Export
+	opts   *Options
+
+	total, processed, failed int64
+
+	// authors by login and WordPress user id, users by email
+	authors   map[string]int64
+	authorIDs map[string]int64
+	emails    map[string]int64
+	// categories by nicename
+	categories map[string]int64
+	// local media URLs by original URL and attachment id
+	media     map[string]string
+	mediaByID map[string]string
+}
+
+// step runs the import of one item and reports the progress. Only failing
+// to report is returned, the error of the item itself is recorded.
+func (im *importer) step(kind, wpID string, f func() error) error {
+	im.processed++
+	if err := f(); err != nil && !errors.Is(err, errSkipped) {
+		im.failed++
+		if err := im.strg.WordPress().AddError(im.imp.ID, kind, wpID, err.Error()); err != nil {
+			return err
+		}
+	}
+	return im.strg.WordPress().Progress(im.imp.ID, im.total, im.processed, im.failed)
+}
+
+// mapped returns the local id of an item imported before.
+func (im *importer) mapped(kind, wpID string) (int64, bool, error) {
+	id, err := im.strg.WordPress().GetItem(im.export.Site, kind, wpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	return id, err == nil, err
+}
+
+func (im *importer) author(a *Author) error {
+	id, ok, err := im.mapped(repo.WordPressItemUser, a.Login)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		firstName, lastName := a.FirstName, a.LastName
+		if firstName == "" {
+			firstName = a.DisplayName
+		}
+		if firstName == "" {
+			firstName = a.Login
+		}
+
+		id, err = im.user(a.Email, firstName, lastName)
+		if err != nil {
+			return err
+		}
+
+		err = im.strg.WordPress().SetItem(im.export.Site, repo.WordPressItemUser, a.Login, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	im.authors[a.Login] = id
+	if a.ID != "" {
+		im.authorIDs[a.ID] = id
+	}
+	return nil
+}
+
+// user returns the user with the email, creating one when there is none.
+// Created users have a random password and sign in by resetting it.
+func (im *importer) user(email, firstName, lastName string) (int64, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || len(email) > maxEmailLength {
+		return 0, ErrNoEmail
+	}
+	if id, ok := im.emails[email]; ok {
+		return id, nil
+	}
+
+	user, err := im.strg.User().GetByEmail(email)
+	if errors.Is(err, sql.ErrNoRows) {
+		var password string
+		password, err = utils.GenerateRandomCode(32)
+		if err != nil {
+			return 0, err
+		}
+		password, err = utils.HashPassword(password)
+		if err != nil {
+			return 0, err
+		}
+
+		user, err = im.strg.User().Create(&repo.User{
+			FirstName: truncate(firstName, maxNameLength),
+			LastName:  truncate(lastName, maxNameLength),
+			Email:     email,
+			Type:      repo.UserTypeUser,
+			Password:  password,
+		})
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	im.emails[email] = user.ID
+	return user.ID, nil
+}
+
+func (im *importer) category(nicename, name string) (int64, error) {
+	if id, ok := im.categories[nicename]; ok {
+		return id, nil
+	}
+
+	id, ok, err := im.mapped(repo.WordPressItemCategory, nicename)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		title := truncate(strings.TrimSpace(name), 100)
+		if title == "" {
+			title = nicename
+		}
+
+		category, err := im.strg.Category().GetByTitle(title)
+		if errors.Is(err, sql.ErrNoRows) {
+			category, err = im.strg.Category().Create(&repo.Category{Title: title})
+		}
+		if err != nil {
+			return 0, err
+		}
+		id = category.ID
+
+		err = im.strg.WordPress().SetItem(im.export.Site, repo.WordPressItemCategory, nicename, id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	im.categories[nicename] = id
+	return id, nil
+}
+
+// attachment downloads a media file, unless it was downloaded before, and
+// records its local URL.
+func (im *importer) attachment(item *Item) error {
+	u, err := url.Parse(item.AttachmentURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported media URL %q", item.AttachmentURL)
+	}
+
+	// the name only depends on the URL, which makes downloads idempotent
+	sum := sha1.Sum([]byte(item.AttachmentURL))
+	name := hex.EncodeToString(sum[:10]) + strings.ToLower(path.Ext(u.Path))
+	file := filepath.Join(im.opts.MediaDir, name)
+
+	if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+		if err := im.download(item.AttachmentURL, file); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	local := im.opts.MediaURL + name
+	im.media[item.AttachmentURL] = local
+	im.mediaByID[item.ID] = local
+	return nil
+}
+
+func (im *importer) download(source, file string) error {
+	resp, err := im.opts.Client.Get(source)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s", ErrMediaStatus, resp.Status)
+	}
+
+	if err := os.MkdirAll(im.opts.MediaDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(im.opts.MediaDir, ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxMediaSize+1))
+	if err != nil {
+		return err
+	}
+	if n > maxMediaSize {
+		return ErrMediaTooLarge
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// rewriteURL points links to downloaded media, including resized images,
+// at their local copy.
+func (im *importer) rewriteURL(link string) string {
+	if local, ok := im.media[link]; ok {
+		return local
+	}
+	if original := sizeSuffix.ReplaceAllString(link, "$1"); original != link {
+		if local, ok := im.media[original]; ok {
+			return local
+		}
+	}
+	return link
+}
+
+// post imports a post and then its comments. The comments of a post that
+// wasn't imported are skipped, the error is recorded for the post.
+func (im *importer) post(item *Item) error {
+	var postID int64
+	err := im.step(repo.WordPressItemPost, item.ID, func() (err error) {
+		postID, err = im.importPost(item)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	comments := make([]*Comment, len(item.Comments))
+	copy(comments, item.Comments)
+	// parents have lower ids than their replies
+	sort.SliceStable(comments, func(i, j int) bool {
+		a, _ := strconv.ParseInt(comments[i].ID, 10, 64)
+		b, _ := strconv.ParseInt(comments[j].ID, 10, 64)
+		return a < b
+	})
+
+	for _, c := range comments {
+		c := c
+		err := im.step(repo.WordPressItemComment, c.ID, func() error {
+			if postID == 0 {
+				return errSkipped
+			}
+			return im.comment(postID, c)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (im *importer) importPost(item *Item) (int64, error) {
+	id, ok, err := im.mapped(repo.WordPressItemPost, item.ID)
+	if err != nil || ok {
+		return id, err
+	}
+
+	post := repo.Post{
+		Title:      strings.TrimSpace(item.Title),
+		CreatedAt:  parseDate(item.DateGMT, item.Date),
+		Tags:       utils.NormalizeTags(item.Tags()),
+		Visibility: repo.PostVisibilityPublic,
+		Language:   im.opts.Language,
+	}
+	if post.Title == "" {
+		post.Title = defaultTitle
+	}
+	if post.CreatedAt.IsZero() {
+		post.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	}
+	if modified := parseDate(item.ModifiedGMT, ""); modified.After(post.CreatedAt) {
+		post.UpdatedAt = &modified
+	}
+
+	// trashed posts and autosaves aren't imported, and scheduled ones stay
+	// private until they are published by hand
+	switch item.Status {
+	case "publish":
+	case "private", "draft", "pending", "future":
+		post.Visibility = repo.PostVisibilityPrivate
+	default:
+		return 0, errSkipped
+	}
+	if item.Password != "" {
+		password, err := utils.HashPassword(item.Password)
+		if err != nil {
+			return 0, err
+		}
+		post.Password = &password
+		if post.Visibility == repo.PostVisibilityPublic {
+			post.Visibility = repo.PostVisibilityPassword
+		}
+	}
+
+	var known bool
+	post.UserID, known = im.authors[item.Creator]
+	if !known {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownAuthor, item.Creator)
+	}
+
+	nicename, name := strings.ToLower(defaultCategory), defaultCategory
+	for _, t := range item.Terms {
+		if t.Domain == "category" {
+			nicename, name = t.Nicename, t.Name
+			break
+		}
+	}
+	post.CategoryID, err = im.category(nicename, name)
+	if err != nil {
+		return 0, err
+	}
+
+	if local, ok := im.mediaByID[item.MetaValue("_thumbnail_id")]; ok {
+		post.ImageUrl = &local
+	}
+
+	post.Description = ToMarkdown(item.Content, im.rewriteURL)
+	result, err := markdown.Render(post.Description)
+	if err != nil {
+		return 0, err
+	}
+	post.DescriptionHtml = result.HTML
+	post.ReadingTime = int32(result.ReadingTime)
+	post.TableOfContents = make([]*repo.PostHeading, 0, len(result.TableOfContents))
+	for _, h := range result.TableOfContents {
+		post.TableOfContents = append(post.TableOfContents, &repo.PostHeading{
+			Level: h.Level,
+			ID:    h.ID,
+			Text:  h.Text,
+		})
+	}
+
+	// a post created by an interrupted run of the import is reused
+	id, err = im.strg.WordPress().FindPost(post.UserID, post.Title, post.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		var created *repo.Post
+		created, err = im.strg.Post().Create(&post)
+		if created != nil {
+			id = created.ID
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, im.strg.WordPress().SetItem(im.export.Site, repo.WordPressItemPost, item.ID, id)
+}
+
+// comment imports an approved comment. Others are skipped without an error.
+func (im *importer) comment(postID int64, c *Comment) error {
+	if c.Approved != "1" || c.Type == "pingback" || c.Type == "trackback" {
+		return errSkipped
+	}
+
+	_, ok, err := im.mapped(repo.WordPressItemComment, c.ID)
+	if err != nil || ok {
+		return err
+	}
+
+	// comments of registered users belong to the imported author
+	userID, ok := im.authorIDs[c.UserID]
+	if !ok {
+		userID, err = im.user(c.AuthorEmail, c.Author, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	comment := repo.Comment{
+		PostID:      postID,
+		UserID:      userID,
+		Description: strings.TrimSpace(c.Content),
+		CreatedAt:   parseDate(c.DateGMT, c.Date),
+	}
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	}
+
+	if c.Parent != "" && c.Parent != "0" {
+		parentID, ok, err := im.mapped(repo.WordPressItemComment, c.Parent)
+		if err != nil {
+			return err
+		}
+		// replies to comments that weren't imported become top level
+		if ok {
+			comment.ParentID = &parentID
+		}
+	}
+
+	id, err := im.strg.WordPress().FindComment(comment.PostID, comment.UserID, comment.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		var created *repo.Comment
+		created, err = im.strg.Comment().Create(&comment)
+		if created != nil {
+			id = created.ID
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return im.strg.WordPress().SetItem(im.export.Site, repo.WordPressItemComment, c.ID, id)
+}
+
+func truncate(s string, length int) string {
+	if runes := []rune(s); len(runes) > length {
+		return string(runes[:length])
+	}
+	return s
+}
@@ -0,0 +1,229 @@
+package wordpress
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	shortcodes     = regexp.MustCompile(`\[/?(caption|embed|gallery|audio|video)[^\]]*\]`)
+	blankLines     = regexp.MustCompile(`\n[ \t]*\n(\s*\n)+`)
+	markdownEscape = strings.NewReplacer(
+		`\`, `\\`,
+		"`", "\\`",
+		`*`, `\*`,
+		`_`, `\_`,
+		`[`, `\[`,
+		`]`, `\]`,
+		`<`, `\<`,
+		`#`, `\#`,
+	)
+)
+
+// ToMarkdown converts the HTML of a WordPress post to Markdown. Every link
+// and image URL is passed through rewrite. Markup without a Markdown
+// counterpart is reduced to its text.
+func ToMarkdown(content string, rewrite func(string) string) string {
+	content = shortcodes.ReplaceAllString(content, "")
+
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return content
+	}
+
+	c := converter{rewrite: rewrite}
+	for _, n := range nodes {
+		c.node(n)
+	}
+
+	md := blankLines.ReplaceAllString(c.buf.String(), "\n\n")
+	return strings.TrimSpace(md) + "\n"
+}
+
+type converter struct {
+	buf     strings.Builder
+	rewrite func(string) string
+	// lists holds the item counter of every open list, 0 for unordered
+	lists []int
+	pre   bool
+}
+
+func (c *converter) block(f func()) {
+	c.buf.WriteString("\n\n")
+	f()
+	c.buf.WriteString("\n\n")
+}
+
+func (c *converter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child)
+	}
+}
+
+func (c *converter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if c.pre {
+			c.buf.WriteString(n.Data)
+		} else {
+			c.buf.WriteString(markdownEscape.Replace(n.Data))
+		}
+		return
+	case html.ElementNode:
+	default:
+		c.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript:
+	case atom.P, atom.Div, atom.Figure, atom.Figcaption, atom.Table, atom.Section, atom.Article:
+		c.block(func() { c.children(n) })
+	case atom.Tr:
+		c.children(n)
+		c.buf.WriteString("\n\n")
+	case atom.Td, atom.Th:
+		c.children(n)
+		c.buf.WriteString(" ")
+	case atom.Br:
+		c.buf.WriteString("  \n")
+	case atom.Hr:
+		c.block(func() { c.buf.WriteString("---") })
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		c.block(func() {
+			c.buf.WriteString(strings.Repeat("#", level) + " ")
+			c.inline(n)
+		})
+	case atom.Strong, atom.B:
+		c.wrap(n, "**")
+	case atom.Em, atom.I:
+		c.wrap(n, "*")
+	case atom.Del, atom.S, atom.Strike:
+		c.wrap(n, "~~")
+	case atom.Code:
+		if c.pre {
+			c.children(n)
+			return
+		}
+		c.buf.WriteString("`" + text(n) + "`")
+	case atom.Pre:
+		c.block(func() {
+			c.buf.WriteString("```\n")
+			c.pre = true
+			c.children(n)
+			c.pre = false
+			c.buf.WriteString("\n```")
+		})
+	case atom.A:
+		href := attr(n, "href")
+		if href == "" {
+			c.children(n)
+			return
+		}
+		c.buf.WriteString("[")
+		c.children(n)
+		c.buf.WriteString("](" + c.rewrite(href) + ")")
+	case atom.Img:
+		src := attr(n, "src")
+		if src != "" {
+			c.buf.WriteString("![" + markdownEscape.Replace(attr(n, "alt")) + "](" + c.rewrite(src) + ")")
+		}
+	case atom.Blockquote:
+		inner := converter{rewrite: c.rewrite}
+		inner.children(n)
+		quote := strings.TrimSpace(blankLines.ReplaceAllString(inner.buf.String(), "\n\n"))
+		c.block(func() {
+			c.buf.WriteString("> " + strings.ReplaceAll(quote, "\n", "\n> "))
+		})
+	case atom.Ul, atom.Ol:
+		counter := 0
+		if n.DataAtom == atom.Ol {
+			counter = 1
+		}
+		c.lists = append(c.lists, counter)
+		c.block(func() { c.children(n) })
+		c.lists = c.lists[:len(c.lists)-1]
+	case atom.Li:
+		c.item(n)
+	default:
+		c.children(n)
+	}
+}
+
+// wrap surrounds the text of inline markup with a Markdown delimiter.
+func (c *converter) wrap(n *html.Node, delimiter string) {
+	inner := converter{rewrite: c.rewrite}
+	inner.children(n)
+	content := inner.buf.String()
+	if strings.TrimSpace(content) == "" {
+		c.buf.WriteString(content)
+		return
+	}
+	c.buf.WriteString(delimiter + strings.TrimSpace(content) + delimiter)
+}
+
+func (c *converter) inline(n *html.Node) {
+	inner := converter{rewrite: c.rewrite}
+	inner.children(n)
+	c.buf.WriteString(strings.Join(strings.Fields(inner.buf.String()), " "))
+}
+
+func (c *converter) item(n *html.Node) {
+	marker := "- "
+	if len(c.lists) > 0 {
+		if last := &c.lists[len(c.lists)-1]; *last > 0 {
+			marker = strconv.Itoa(*last) + ". "
+			*last++
+		}
+	}
+
+	inner := converter{rewrite: c.rewrite, lists: c.lists}
+	inner.children(n)
+	content := strings.TrimSpace(blankLines.ReplaceAllString(inner.buf.String(), "\n\n"))
+
+	c.buf.WriteString("\n" + marker + indent(content, strings.Repeat(" ", len(marker))))
+}
+
+// indent prefixes every line but the first and the empty ones.
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+func text(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package wordpress
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToMarkdown(t *testing.T) {
+	rewrite := func(link string) string {
+		return strings.Replace(link, "https://old.example.com/wp-content/uploads/", "/medias/", 1)
+	}
+
+	cases := map[string]string{
+		"Plain text\n\nSecond paragraph":                                                         "Plain text\n\nSecond paragraph\n",
+		"<p>Some <strong>bold</strong> and <em>italic</em></p>":                                  "Some **bold** and *italic*\n",
+		"<h2>Title</h2><p>Text</p>":                                                              "## Title\n\nText\n",
+		`<a href="https://go.dev">Go</a>`:                                                        "[Go](https://go.dev)\n",
+		`<img src="https://old.example.com/wp-content/uploads/a.png" alt="A">`:                   "![A](/medias/a.png)\n",
+		"<ul><li>one</li><li>two<ol><li>nested</li></ol></li></ul>":                              "- one\n- two\n\n  1. nested\n",
+		"<blockquote><p>quoted</p></blockquote>":                                                 "> quoted\n",
+		"<pre><code>x := 1 * 2</code></pre>":                                                     "```\nx := 1 * 2\n```\n",
+		"Use <code>go test</code> *now*":                                                         "Use `go test` \\*now\\*\n",
+		`[caption id="1"]<img src="https://old.example.com/wp-content/uploads/b.jpg">[/caption]`: "![](/medias/b.jpg)\n",
+		"<script>alert(1)</script>Safe":                                                          "Safe\n",
+	}
+
+	for source, expected := range cases {
+		require.Equal(t, expected, ToMarkdown(source, rewrite), source)
+	}
+}
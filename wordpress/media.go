@@ -0,0 +1,63 @@
+package wordpress
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// maxMediaRedirects is how many redirects a media download follows.
+const maxMediaRedirects = 3
+
+var (
+	ErrMediaAddress   = errors.New("media is not on a public address")
+	ErrMediaRedirects = fmt.Errorf("media redirected more than %d times", maxMediaRedirects)
+)
+
+// MediaClient returns the client to download attachments with. Exports are
+// uploaded by users, so it only connects to public addresses, checked once
+// the host is resolved, redirects included, so that an export can't make
+// the server reach its own network.
+func MediaClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: publicOnly,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxMediaRedirects {
+				return ErrMediaRedirects
+			}
+			return nil
+		},
+	}
+}
+
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrMediaAddress, host)
+	}
+	return nil
+}
+
+// cgnat is the shared address space of carrier-grade NAT, RFC 6598.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !cgnat.Contains(ip)
+}
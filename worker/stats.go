@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage"
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+)
+
+// rollupStats stores the daily aggregates of yesterday and today. Yesterday
+// is redone so that whatever happened after its last run is not lost.
+func (w *Worker) rollupStats() error {
+	today := time.Now().UTC()
+
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		if err := w.rollupDay(day); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *Worker) rollupDay(day time.Time) error {
+	date := day.Format("2006-01-02")
+
+	buffer, err := w.inMemory.HGetAll(storage.PostDailyViewsKey + date)
+	if err != nil {
+		return err
+	}
+
+	views := make([]*repo.PostDayViews, 0, len(buffer))
+	for field, value := range buffer {
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		viewers, err := w.inMemory.PFCount(storage.PostDailyViewersKey + date + "_" + field)
+		if err != nil {
+			return err
+		}
+
+		views = append(views, &repo.PostDayViews{
+			PostID:        id,
+			Views:         count,
+			UniqueViewers: viewers,
+		})
+	}
+
+	return w.storage.Stats().Rollup(day, views)
+}
+
+func (w *Worker) refreshRankings() error {
+	return w.storage.Stats().RefreshRankings()
+}
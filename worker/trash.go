@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"log"
+	"time"
+)
+
+// purgeTrash permanently removes whatever has been in the trash for longer
+// than the retention period. Comments go first and categories last, so that
+// nothing is still referenced when it is removed.
+func (w *Worker) purgeTrash() error {
+	if w.cfg.Trash.RetentionDays <= 0 {
+		return nil
+	}
+	before := time.Now().AddDate(0, 0, -w.cfg.Trash.RetentionDays)
+
+	comments, err := w.storage.Comment().PurgeDeleted(before)
+	if err != nil {
+		return err
+	}
+
+	posts, err := w.storage.Post().PurgeDeleted(before)
+	if err != nil {
+		return err
+	}
+
+	categories, err := w.storage.Category().PurgeDeleted(before)
+	if err != nil {
+		return err
+	}
+
+	if comments+posts+categories > 0 {
+		log.Printf("worker: purged %d posts, %d comments and %d categories from the trash", posts, comments, categories)
+	}
+
+	return nil
+}
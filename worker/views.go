@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"strconv"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage"
+)
+
+// flushViews moves the view counts buffered in Redis to Postgres. When the
+// update fails the counts are put back so that they are retried next time.
+func (w *Worker) flushViews() error {
+	buffer, err := w.inMemory.HTake(storage.PostViewsKey)
+	if err != nil || len(buffer) == 0 {
+		return err
+	}
+
+	views := make(map[int64]int64, len(buffer))
+	for field, value := range buffer {
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		views[id] = count
+	}
+
+	err = w.storage.Post().AddViews(views)
+	if err != nil {
+		for id, count := range views {
+			_ = w.inMemory.HIncrBy(storage.PostViewsKey, strconv.FormatInt(id, 10), count)
+		}
+		return err
+	}
+
+	return nil
+}
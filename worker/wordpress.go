@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nurmuhammaddeveloper/blog_db/storage/repo"
+	"github.com/nurmuhammaddeveloper/blog_db/wordpress"
+)
+
+const (
+	// importStaleAfter is how long a running import may go without
+	// reporting progress before it is taken over. Every item reports, so
+	// this only happens when the process running it stopped.
+	importStaleAfter = 5 * time.Minute
+
+	mediaTimeout = 30 * time.Second
+)
+
+// runImports runs the pending WordPress imports one after another. An
+// import interrupted by ctx is left running, to be taken over and resumed
+// once it is stale.
+func (w *Worker) runImports(ctx context.Context) error {
+	for {
+		imp, err := w.storage.WordPress().Claim(time.Now().Add(-importStaleAfter))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = w.runImport(ctx, imp)
+		if ctx.Err() != nil {
+			log.Printf("worker: wordpress import %d interrupted: %v", imp.ID, err)
+			return nil
+		}
+		log.Printf("worker: wordpress import %d finished: %v", imp.ID, err)
+
+		if err := w.storage.WordPress().Finish(imp.ID, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *Worker) runImport(ctx context.Context, imp *repo.WordPressImport) error {
+	f, err := os.Open(filepath.Join(w.cfg.Import.Dir, imp.FileName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	export, err := wordpress.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	return wordpress.Import(ctx, w.storage, imp, export, &wordpress.Options{
+		MediaDir: filepath.Join(dir, "media"),
+		MediaURL: "/medias/",
+		Client:   wordpress.MediaClient(mediaTimeout),
+		Language: w.cfg.Languages.Default,
+	})
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/nurmuhammaddeveloper/blog_db/config"
+	"github.com/nurmuhammaddeveloper/blog_db/storage"
+)
+
+type Options struct {
+	Cfg      *config.Config
+	Storage  storage.StorageI
+	InMemory storage.InMemoryStorageI
+}
+
+// Worker runs the periodic background jobs of the service.
+type Worker struct {
+	cfg      *config.Config
+	storage  storage.StorageI
+	inMemory storage.InMemoryStorageI
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func() error
+}
+
+func New(opt *Options) *Worker {
+	return &Worker{
+		cfg:      opt.Cfg,
+		storage:  opt.Storage,
+		inMemory: opt.InMemory,
+	}
+}
+
+// jobs lists the jobs, the long running ones stopping when ctx is done.
+func (w *Worker) jobs(ctx context.Context) []job {
+	return []job{
+		{name: "purge trash", interval: time.Hour, run: w.purgeTrash},
+		{name: "flush views", interval: w.cfg.Views.FlushInterval, run: w.flushViews},
+		{name: "rollup stats", interval: w.cfg.Stats.RollupInterval, run: w.rollupStats},
+		{name: "refresh rankings", interval: w.cfg.Stats.RankingsInterval, run: w.refreshRankings},
+		{name: "wordpress imports", interval: w.cfg.Import.Interval, run: func() error { return w.runImports(ctx) }},
+	}
+}
+
+// Start runs every job in its own goroutine until ctx is done. Jobs
+// configured with a zero interval are disabled.
+func (w *Worker) Start(ctx context.Context) {
+	for _, j := range w.jobs(ctx) {
+		if j.interval <= 0 {
+			continue
+		}
+		go w.loop(ctx, j)
+	}
+}
+
+func (w *Worker) loop(ctx context.Context, j job) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.run(); err != nil {
+			log.Printf("worker: %s: %v", j.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}